@@ -0,0 +1,399 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"spoodblort/database"
+	"spoodblort/fight"
+	"spoodblort/scheduler"
+)
+
+// runCommand dispatches `spoodblort <command> [flags]` and returns the exit code
+func runCommand(args []string) int {
+	// Keep engine chatter off stdout so command output stays readable
+	log.SetOutput(os.Stderr)
+
+	switch args[0] {
+	case "verify":
+		return runVerify(args[1:])
+	case "simulate":
+		return runSimulate(args[1:])
+	case "bench":
+		return runBench(args[1:])
+	case "reconcile":
+		return runReconcile(args[1:])
+	case "settle-voids":
+		return runSettleVoids(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+		fmt.Fprintln(os.Stderr, "commands:")
+		fmt.Fprintln(os.Stderr, "  verify [-all] [fightID...]   re-run completed fights and report divergence from stored results")
+		fmt.Fprintln(os.Stderr, "  simulate [-fights N|-weeks N] play fights offline against a roster snapshot and report balance")
+		fmt.Fprintln(os.Stderr, "  bench [stat...]              time coin-flip against binomial stat contests")
+		fmt.Fprintln(os.Stderr, "  reconcile                    replay the credit ledger against every user's balance")
+		fmt.Fprintln(os.Stderr, "  settle-voids [-policy P]     settle stakes still pending on fights voided before void settlement existed")
+		return 2
+	}
+}
+
+// runVerify re-simulates completed fights and compares them to their stored results
+func runVerify(args []string) int {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	all := fs.Bool("all", false, "verify every completed fight")
+	quiet := fs.Bool("q", false, "only print fights that diverge")
+	_ = fs.Parse(args)
+
+	db := connectDatabase()
+	defer db.Close()
+	repo := database.NewRepository(db)
+	repo.SetClock(leagueClock())
+	engine := fight.NewEngine(repo)
+
+	var fightIDs []int
+	if *all {
+		fights, err := repo.GetAllFights()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to load fights: %v\n", err)
+			return 1
+		}
+		for _, f := range fights {
+			if f.Status == "completed" {
+				fightIDs = append(fightIDs, f.ID)
+			}
+		}
+	}
+	for _, arg := range fs.Args() {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid fight id %q\n", arg)
+			return 2
+		}
+		fightIDs = append(fightIDs, id)
+	}
+	if len(fightIDs) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: spoodblort verify [-all] [-q] [fightID...]")
+		return 2
+	}
+
+	diverged, unrecorded := 0, 0
+	for _, id := range fightIDs {
+		v, err := engine.VerifyFight(id)
+		if err != nil {
+			fmt.Printf("fight %d: error: %v\n", id, err)
+			diverged++
+			continue
+		}
+		if !v.RecordedInputs {
+			unrecorded++
+		}
+		if v.Match {
+			if !*quiet {
+				catchUp := ""
+				if v.CatchUpTicks > 0 {
+					catchUp = fmt.Sprintf(", %d catch-up ticks", v.CatchUpTicks)
+				}
+				fmt.Printf("fight %d: ok (%s %s, winner %d, %d-%d%s)\n", id, v.Ruleset, v.AlgoVersion, v.ReplayWinnerID, v.ReplayScore1, v.ReplayScore2, catchUp)
+			}
+			continue
+		}
+		diverged++
+		note := ""
+		if !v.RecordedInputs {
+			note = " [no recorded inputs]"
+		}
+		fmt.Printf("fight %d: DIVERGED (%s %s)%s\n", id, v.Ruleset, v.AlgoVersion, note)
+		for _, d := range v.Divergences {
+			fmt.Printf("    %s\n", d)
+		}
+	}
+
+	fmt.Printf("%d verified, %d diverged, %d without recorded inputs\n", len(fightIDs), diverged, unrecorded)
+	if diverged > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runSimulate plays fights between the fighters of a database snapshot and
+// prints a balance report. The snapshot is opened read-only and nothing the
+// simulated fights do is written back.
+func runSimulate(args []string) int {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	dbPath := fs.String("db", os.Getenv("DATABASE_URL"), "database snapshot to load the roster from (default $DATABASE_URL or ./spoodblort.db)")
+	fights := fs.Int("fights", 10000, "random pairings to fight")
+	weeks := fs.Int("weeks", 0, "simulate whole tournament weeks instead of random pairings")
+	seed := fs.Int64("seed", 1, "seed for pairings and fights")
+	version := fs.String("version", "", "standard ruleset algorithm version to start from (default current)")
+	bucket := fs.Int("bucket", 25, "stat points per row of the win-rate tables")
+	maxDamage := fs.Int("max-damage", 0, "override MaxDamage")
+	minDamage := fs.Int("min-damage", 0, "override MinDamage")
+	critChance := fs.Int("crit-chance", 0, "override CritChance (1 in N)")
+	deathChance := fs.Int("death-chance", 0, "override DeathChance (1 in N)")
+	frenzyChance := fs.Int("frenzy-chance", 0, "override FrenzyChance (1 in N)")
+	frenzyMin := fs.Int("frenzy-min", 0, "override FrenzyMinMult")
+	frenzyMax := fs.Int("frenzy-max", 0, "override FrenzyMaxMult")
+	_ = fs.Parse(args)
+
+	rules, err := fight.LookupRuleset(fight.DefaultRulesetName, *version)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 2
+	}
+	standard, ok := rules.(fight.StandardRuleset)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "algorithm version %s only replays old fights\n", rules.Version())
+		return 2
+	}
+	tuning := standard.Tuning()
+	tuned := false
+	for _, o := range []struct {
+		value int
+		field *int
+	}{
+		{*maxDamage, &tuning.MaxDamage},
+		{*minDamage, &tuning.MinDamage},
+		{*critChance, &tuning.CritChance},
+		{*deathChance, &tuning.DeathChance},
+		{*frenzyChance, &tuning.FrenzyChance},
+		{*frenzyMin, &tuning.FrenzyMinMult},
+		{*frenzyMax, &tuning.FrenzyMaxMult},
+	} {
+		if o.value > 0 {
+			*o.field = o.value
+			tuned = true
+		}
+	}
+	if tuning.MinDamage > tuning.MaxDamage || tuning.FrenzyMinMult > tuning.FrenzyMaxMult {
+		fmt.Fprintln(os.Stderr, "minimums must not exceed maximums")
+		return 2
+	}
+	if tuned {
+		// An unregistered version: it only ever exists inside this run
+		rules = fight.NewStandardRuleset(standard.Version()+"+tuned", tuning)
+	}
+
+	if *dbPath == "" {
+		*dbPath = "./spoodblort.db"
+	}
+	db, err := sqlx.Connect("sqlite3", "file:"+*dbPath+"?mode=ro")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open snapshot %s: %v\n", *dbPath, err)
+		return 1
+	}
+	defer db.Close()
+	repo := database.NewSnapshotRepository(db)
+	repo.SetClock(leagueClock())
+
+	roster, err := repo.GetEligibleFighters()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load roster: %v\n", err)
+		return 1
+	}
+
+	began := time.Now()
+	var report fight.BalanceReport
+	if *weeks > 0 {
+		report, err = fight.NewGenerator(repo).SimulateBalanceWeeks(rules, roster, *weeks, repo.Clock().Now().AddDate(0, 0, 7), *seed, *bucket)
+	} else {
+		report, err = fight.SimulateBalanceFights(rules, roster, *fights, *seed, *bucket)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "simulation failed: %v\n", err)
+		return 1
+	}
+
+	printBalanceReport(report, len(roster), tuning, time.Since(began))
+	return 0
+}
+
+// printBalanceReport writes a balance report as plain text
+func printBalanceReport(r fight.BalanceReport, rosterSize int, tuning fight.StandardTuning, took time.Duration) {
+	pct := func(n, of int) float64 {
+		if of == 0 {
+			return 0
+		}
+		return float64(n) * 100 / float64(of)
+	}
+
+	fmt.Printf("%s %s: %d fights between %d fighters", r.Ruleset, r.Version, r.Fights, rosterSize)
+	if r.Days > 0 {
+		fmt.Printf(" over %d fight days", r.Days)
+	}
+	fmt.Printf(" (%s)\n", took.Round(time.Millisecond))
+	fmt.Printf("tuning: damage %d-%d, crit 1/%d, death 1/%d, frenzy 1/%d x%d-%d, specials %v\n\n",
+		tuning.MinDamage, tuning.MaxDamage, tuning.CritChance, tuning.DeathChance,
+		tuning.FrenzyChance, tuning.FrenzyMinMult, tuning.FrenzyMaxMult, tuning.SpecialMoves)
+
+	fmt.Printf("deaths per 1000 fights  %.1f\n", r.DeathsPer1000())
+	fmt.Printf("average fight length    %.1f ticks, %.2f rounds\n", r.AverageTicks(), r.AverageRounds())
+	fmt.Printf("finishes                decision %.1f%%  KO %.1f%%  death %.1f%%  draw %.1f%%\n",
+		pct(r.Decisions, r.Fights), pct(r.KOs, r.Fights), pct(r.Deaths, r.Fights), pct(r.Draws, r.Fights))
+	if r.UndeadFights > 0 {
+		fmt.Printf("undead vs living        %.1f%% undead wins, %.1f%% draws over %d fights\n",
+			r.UndeadWinRate()*100, pct(r.UndeadDraws, r.UndeadFights), r.UndeadFights)
+	} else {
+		fmt.Println("undead vs living        no fights")
+	}
+
+	table := func(title string, buckets []fight.BalanceBucket) {
+		fmt.Printf("\n%s\n", title)
+		for _, b := range buckets {
+			fmt.Printf("  %5d-%-5d %7d fights  %5.1f%% won  %4.1f%% drawn\n",
+				b.Low, b.High-1, b.Fights, b.WinRate()*100, pct(b.Draws, b.Fights))
+		}
+	}
+	table("win rate by stat total", r.ByStatTotal)
+	table("favourite's win rate by stat edge", r.ByStatEdge)
+}
+
+// runBench times the coin-flip and binomial stat contests against each other
+// and checks both land on the exact win chance
+func runBench(args []string) int {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	contests := fs.Int("contests", 200000, "contests per stat level and method")
+	edge := fs.Int("edge", 5, "stat points fighter 2 has over fighter 1")
+	seed := fs.Int64("seed", 1, "seed for the contests")
+	_ = fs.Parse(args)
+
+	stats := []int{25, 100, 400, 1600}
+	if fs.NArg() > 0 {
+		stats = stats[:0]
+		for _, arg := range fs.Args() {
+			stat, err := strconv.Atoi(arg)
+			if err != nil || stat < 0 {
+				fmt.Fprintf(os.Stderr, "invalid stat %q\n", arg)
+				return 2
+			}
+			stats = append(stats, stat)
+		}
+	}
+
+	fmt.Printf("%d contests each, fighter 2 ahead by %d\n", *contests, *edge)
+	fmt.Printf("%6s  %12s  %12s  %8s  %8s  %8s  %8s\n", "stat", "coin flip", "binomial", "speedup", "exact", "flips", "binomial")
+	for _, stat := range stats {
+		b := fight.BenchStatAdvantage(stat, *edge, *contests, *seed)
+		speedup := 0.0
+		if b.Binomial > 0 {
+			speedup = float64(b.CoinFlip) / float64(b.Binomial)
+		}
+		fmt.Printf("%6d  %12s  %12s  %7.1fx  %7.2f%%  %7.2f%%  %7.2f%%\n",
+			stat, b.CoinFlip, b.Binomial, speedup, b.ExactChance*100, b.CoinFlipWins*100, b.BinomialWins*100)
+	}
+	return 0
+}
+
+// runReconcile replays the credit ledger and reports users whose balance
+// doesn't match it. Exits 1 when anything is off the books.
+func runReconcile(args []string) int {
+	fs := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	_ = fs.Parse(args)
+
+	db := connectDatabase()
+	defer db.Close()
+	repo := database.NewRepository(db)
+	repo.SetClock(leagueClock())
+
+	report, err := repo.ReconcileLedger()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "reconciliation failed: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("%d users, %d ledger entries\n", report.Users, report.Transactions)
+	accounts := make([]string, 0, len(report.Accounts))
+	for account := range report.Accounts {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+	for _, account := range accounts {
+		fmt.Printf("  %-12s %15d\n", account, report.Accounts[account])
+	}
+	if report.Balanced() {
+		fmt.Println("ledger balances")
+		return 0
+	}
+
+	fmt.Printf("%d users off the books:\n", len(report.Mismatches))
+	for _, m := range report.Mismatches {
+		fmt.Printf("  user %d (%s): credits %d, ledger %d, drift %+d", m.UserID, m.Username, m.Credits, m.LedgerBalance, m.Credits-m.LedgerBalance)
+		if m.FirstBadEntry != 0 {
+			fmt.Printf(", first bad entry %d", m.FirstBadEntry)
+		}
+		fmt.Println()
+	}
+	return 1
+}
+
+// runSettleVoids settles the stakes left pending on voided fights from before
+// voids were settled automatically. Quiet by default so old voids don't ping
+// everyone; pass -notify to announce them like a fresh void.
+func runSettleVoids(args []string) int {
+	fs := flag.NewFlagSet("settle-voids", flag.ExitOnError)
+	policy := fs.String("policy", database.VoidBetPolicy(), "refund, fee or forfeit (default $VOID_BET_POLICY or refund)")
+	dryRun := fs.Bool("dry-run", false, "list the fights that would be settled without touching them")
+	notify := fs.Bool("notify", false, "announce each settlement in Discord")
+	_ = fs.Parse(args)
+
+	if !database.ValidVoidPolicy(*policy) {
+		fmt.Fprintf(os.Stderr, "unknown policy %q (want refund, fee or forfeit)\n", *policy)
+		return 2
+	}
+
+	db := connectDatabase()
+	defer db.Close()
+	repo := database.NewRepository(db)
+	repo.SetClock(leagueClock())
+
+	fights, err := repo.GetVoidedFightsWithPendingBets()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load voided fights: %v\n", err)
+		return 1
+	}
+	fmt.Printf("%d voided fights with pending stakes\n", len(fights))
+
+	recovery := scheduler.NewRecovery(repo)
+	failed := 0
+	var stakes, staked, refunded, legs int
+	for _, f := range fights {
+		if *dryRun {
+			fmt.Printf("  fight %d %s vs %s (%s)\n", f.ID, f.Fighter1Name, f.Fighter2Name, f.ScheduledTime.Format("2006-01-02"))
+			continue
+		}
+
+		var settlement *database.VoidSettlement
+		if *notify {
+			settlement = recovery.SettleVoidedFight(f, *policy)
+		} else if settlement, err = repo.SettleVoidedFight(f.ID, *policy); err != nil {
+			log.Printf("Failed to settle bets on voided fight %d: %v", f.ID, err)
+		}
+		if settlement == nil {
+			failed++
+			continue
+		}
+
+		stakes += len(settlement.Stakes)
+		legs += settlement.SlipLegs
+		for _, s := range settlement.Stakes {
+			staked += s.Amount
+			refunded += s.Refund
+		}
+		fmt.Printf("  fight %d: %d stakes, %d parlay legs\n", f.ID, len(settlement.Stakes), settlement.SlipLegs)
+	}
+
+	if !*dryRun {
+		fmt.Printf("settled %d stakes (%d staked, %d refunded under %s) and %d parlay legs\n", stakes, staked, refunded, *policy, legs)
+	}
+	if failed > 0 {
+		fmt.Printf("%d fights failed to settle\n", failed)
+		return 1
+	}
+	return 0
+}
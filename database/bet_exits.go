@@ -0,0 +1,213 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Bet statuses for stakes that left before settlement
+const (
+	BetStatusCancelled = "cancelled"  // withdrawn before the bell, less the cancellation fee
+	BetStatusCashedOut = "cashed_out" // settled early at the live cash-out offer
+)
+
+// Cancellation defaults when BET_CANCEL_FEE_PERCENT / BET_CANCEL_CUTOFF_MINUTES aren't set
+const (
+	DefaultBetCancelFeePercent    = 10
+	DefaultBetCancelCutoffMinutes = 5
+)
+
+// ErrBetNotOpen is returned when a bet can't be cancelled or cashed out: it
+// isn't the user's, has already settled, or its fight is past the point allowed
+var ErrBetNotOpen = errors.New("bet can no longer be cancelled or cashed out")
+
+// BetCancelFeePercent returns the share of the stake kept when a bet is
+// cancelled, configured with BET_CANCEL_FEE_PERCENT (0-100)
+func BetCancelFeePercent() int {
+	fee := DefaultBetCancelFeePercent
+	if v := os.Getenv("BET_CANCEL_FEE_PERCENT"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			fee = n
+		}
+	}
+	if fee < 0 {
+		fee = 0
+	}
+	if fee > 100 {
+		fee = 100
+	}
+	return fee
+}
+
+// BetCancelCutoff returns how long before a fight's scheduled start
+// cancellations close, configured with BET_CANCEL_CUTOFF_MINUTES
+func BetCancelCutoff() time.Duration {
+	minutes := DefaultBetCancelCutoffMinutes
+	if v := os.Getenv("BET_CANCEL_CUTOFF_MINUTES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			minutes = n
+		}
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// BetCancelFee is what cancelling a stake of amount costs
+func BetCancelFee(amount int) int {
+	return amount * BetCancelFeePercent() / 100
+}
+
+// CanCancelBet reports whether a bet on fight may still be cancelled at now
+func CanCancelBet(bet *Bet, fight *Fight, now time.Time) bool {
+	return bet != nil && fight != nil && bet.Status == "pending" && fight.Status == "scheduled" &&
+		now.Before(fight.ScheduledTime.Add(-BetCancelCutoff()))
+}
+
+// ensureBetExitStatuses widens the bets status check to allow cancelled and
+// cashed out bets. SQLite can't alter a CHECK constraint, so the table is
+// rebuilt once with the same columns and indexes.
+func (r *Repository) ensureBetExitStatuses() error {
+	var schema string
+	if err := r.db.Get(&schema, `SELECT sql FROM sqlite_master WHERE type='table' AND name='bets'`); err != nil {
+		return err
+	}
+	if strings.Contains(schema, BetStatusCashedOut) {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+        CREATE TABLE bets_rebuild (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            user_id INTEGER NOT NULL,
+            fight_id INTEGER NOT NULL,
+            fighter_id INTEGER NOT NULL, -- which fighter they bet on
+            amount INTEGER NOT NULL,
+            status TEXT DEFAULT 'pending' CHECK (status IN ('pending', 'won', 'lost', 'voided', 'cancelled', 'cashed_out')),
+            payout INTEGER, -- amount won, refunded or cashed out
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            resolved_at DATETIME,
+            odds REAL NOT NULL DEFAULT 0,
+            placed_tick INTEGER NOT NULL DEFAULT 0,
+            FOREIGN KEY (user_id) REFERENCES users(id),
+            FOREIGN KEY (fight_id) REFERENCES fights(id),
+            FOREIGN KEY (fighter_id) REFERENCES fighters(id)
+        );
+        INSERT INTO bets_rebuild (id, user_id, fight_id, fighter_id, amount, status, payout, created_at, resolved_at, odds, placed_tick)
+            SELECT id, user_id, fight_id, fighter_id, amount, status, payout, created_at, resolved_at, odds, placed_tick FROM bets;
+        DROP TABLE bets;
+        ALTER TABLE bets_rebuild RENAME TO bets;
+        CREATE INDEX idx_bets_user_id ON bets(user_id);
+        CREATE INDEX idx_bets_fight_id ON bets(fight_id);
+    `)
+	if err != nil {
+		return fmt.Errorf("rebuild bets: %w", err)
+	}
+	return tx.Commit()
+}
+
+// GetBet returns a single fight bet
+func (r *Repository) GetBet(betID int) (*Bet, error) {
+	var bet Bet
+	err := r.db.Get(&bet, `SELECT * FROM bets WHERE id = ?`, betID)
+	return &bet, err
+}
+
+// CancelBet withdraws a user's pending bet on a scheduled fight before the
+// cancellation cutoff, refunding the stake less the cancellation fee
+func (r *Repository) CancelBet(userID, betID int, now time.Time) (*Bet, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var bet Bet
+	if err := tx.Get(&bet, `SELECT * FROM bets WHERE id = ? AND user_id = ?`, betID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBetNotOpen
+		}
+		return nil, err
+	}
+	var fight Fight
+	if err := tx.Get(&fight, `SELECT * FROM fights WHERE id = ?`, bet.FightID); err != nil {
+		return nil, err
+	}
+	if !CanCancelBet(&bet, &fight, now) {
+		return nil, ErrBetNotOpen
+	}
+
+	fee := BetCancelFee(bet.Amount)
+	refund := bet.Amount - fee
+	if _, err := tx.Exec(`
+        UPDATE bets SET status = ?, payout = ?, resolved_at = ?
+        WHERE id = ?`, BetStatusCancelled, refund, r.sqlNow(), bet.ID); err != nil {
+		return nil, err
+	}
+	if refund > 0 {
+		if _, err := r.postCredit(tx, CreditMove{
+			UserID: userID,
+			Amount: refund,
+			Reason: ReasonBetCancel,
+			Ref:    BetRef(bet.ID),
+			Memo:   fmt.Sprintf("Fight %d, %d fee kept", bet.FightID, fee),
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	bet.Status = BetStatusCancelled
+	bet.Payout = sql.NullInt64{Int64: int64(refund), Valid: true}
+	return &bet, nil
+}
+
+// CashOutBet settles a user's pending bet on an active fight early for
+// amount, the offer priced off the fight's live state at tick
+func (r *Repository) CashOutBet(userID, betID, amount, tick int) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+        UPDATE bets SET status = ?, payout = ?, resolved_at = ?
+        WHERE id = ? AND user_id = ? AND status = 'pending'
+          AND fight_id IN (SELECT id FROM fights WHERE status = 'active')`,
+		BetStatusCashedOut, amount, r.sqlNow(), betID, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrBetNotOpen
+	}
+
+	var fightID int
+	if err := tx.Get(&fightID, `SELECT fight_id FROM bets WHERE id = ?`, betID); err != nil {
+		return err
+	}
+	if amount > 0 {
+		if _, err := r.postCredit(tx, CreditMove{
+			UserID: userID,
+			Amount: amount,
+			Reason: ReasonBetCashOut,
+			Ref:    BetRef(betID),
+			Memo:   fmt.Sprintf("Fight %d, cashed out at tick %d", fightID, tick),
+		}); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,351 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Challenge statuses
+const (
+	ChallengeOpen      = "open"      // posted, waiting for someone to take the other side
+	ChallengeMatched   = "matched"   // both stakes in escrow
+	ChallengeSettled   = "settled"   // the pot went to the winner
+	ChallengeRefunded  = "refunded"  // stakes returned: a draw, a void, or nobody took it
+	ChallengeWithdrawn = "withdrawn" // the challenger took it back before anyone accepted
+)
+
+// ErrChallengeNotOpen is returned when a challenge can't be accepted or
+// withdrawn: it's already taken, gone, or its fight is no longer scheduled
+var ErrChallengeNotOpen = errors.New("challenge is no longer open")
+
+// Challenge is an even-money wager between two users on one fight. Each side
+// stakes the same amount into escrow and the winner takes both.
+type Challenge struct {
+	ID                  int           `db:"id" json:"id"`
+	FightID             int           `db:"fight_id" json:"fight_id"`
+	ChallengerID        int           `db:"challenger_id" json:"challenger_id"`
+	ChallengerFighterID int           `db:"challenger_fighter_id" json:"challenger_fighter_id"`
+	AcceptorID          sql.NullInt64 `db:"acceptor_id" json:"acceptor_id"`
+	AcceptorFighterID   int           `db:"acceptor_fighter_id" json:"acceptor_fighter_id"`
+	Stake               int           `db:"stake" json:"stake"` // per side
+	Status              string        `db:"status" json:"status"`
+	WinnerID            sql.NullInt64 `db:"winner_id" json:"winner_id"` // the user who took the pot
+	CreatedAt           time.Time     `db:"created_at" json:"created_at"`
+	AcceptedAt          sql.NullTime  `db:"accepted_at" json:"accepted_at"`
+	ResolvedAt          sql.NullTime  `db:"resolved_at" json:"resolved_at"`
+}
+
+// Pot is what the winner of a matched challenge collects
+func (c Challenge) Pot() int {
+	return c.Stake * 2
+}
+
+// ChallengeWithNames is a challenge with its users and fighters named for display
+type ChallengeWithNames struct {
+	Challenge
+	ChallengerName        string         `db:"challenger_name" json:"challenger_name"`
+	AcceptorName          sql.NullString `db:"acceptor_name" json:"acceptor_name"`
+	ChallengerFighterName string         `db:"challenger_fighter_name" json:"challenger_fighter_name"`
+	AcceptorFighterName   string         `db:"acceptor_fighter_name" json:"acceptor_fighter_name"`
+}
+
+// WinnerName names the user who took the pot, if anyone did
+func (c ChallengeWithNames) WinnerName() string {
+	switch {
+	case !c.WinnerID.Valid:
+		return ""
+	case int(c.WinnerID.Int64) == c.ChallengerID:
+		return c.ChallengerName
+	default:
+		return c.AcceptorName.String
+	}
+}
+
+func (r *Repository) ensureChallengesTable() error {
+	exists, err := r.tableExists("challenges")
+	if err != nil || exists {
+		return err
+	}
+	_, err = r.db.Exec(`
+        CREATE TABLE challenges (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            fight_id INTEGER NOT NULL,
+            challenger_id INTEGER NOT NULL,
+            challenger_fighter_id INTEGER NOT NULL,
+            acceptor_id INTEGER,
+            acceptor_fighter_id INTEGER NOT NULL,
+            stake INTEGER NOT NULL,
+            status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'matched', 'settled', 'refunded', 'withdrawn')),
+            winner_id INTEGER,
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            accepted_at DATETIME,
+            resolved_at DATETIME,
+            FOREIGN KEY (fight_id) REFERENCES fights(id),
+            FOREIGN KEY (challenger_id) REFERENCES users(id),
+            FOREIGN KEY (acceptor_id) REFERENCES users(id)
+        );
+        CREATE INDEX idx_challenges_fight ON challenges(fight_id, status);
+        CREATE INDEX idx_challenges_challenger ON challenges(challenger_id);
+        CREATE INDEX idx_challenges_acceptor ON challenges(acceptor_id);
+    `)
+	return err
+}
+
+// CreateChallenge posts a challenge backing fighterID on a scheduled fight,
+// moving the challenger's stake into escrow
+func (r *Repository) CreateChallenge(userID, fightID, fighterID, stake int) (*Challenge, error) {
+	if stake <= 0 {
+		return nil, fmt.Errorf("stake must be positive")
+	}
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var fight Fight
+	if err := tx.Get(&fight, `SELECT * FROM fights WHERE id = ?`, fightID); err != nil {
+		return nil, fmt.Errorf("fight %d: %w", fightID, err)
+	}
+	if fight.Status != "scheduled" {
+		return nil, fmt.Errorf("betting is closed for fight %d", fightID)
+	}
+	var other int
+	switch fighterID {
+	case fight.Fighter1ID:
+		other = fight.Fighter2ID
+	case fight.Fighter2ID:
+		other = fight.Fighter1ID
+	default:
+		return nil, fmt.Errorf("fighter %d is not in fight %d", fighterID, fightID)
+	}
+
+	res, err := tx.Exec(`
+        INSERT INTO challenges (fight_id, challenger_id, challenger_fighter_id, acceptor_fighter_id, stake, status, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		fightID, userID, fighterID, other, stake, ChallengeOpen, r.sqlNow())
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.postCredit(tx, CreditMove{
+		UserID: userID,
+		Amount: -stake,
+		Reason: ReasonChallengeEscrow,
+		Ref:    ChallengeRef(int(id)),
+		Memo:   fmt.Sprintf("Fight %d, open challenge", fightID),
+	}); err != nil {
+		return nil, err
+	}
+
+	var challenge Challenge
+	if err := tx.Get(&challenge, `SELECT * FROM challenges WHERE id = ?`, id); err != nil {
+		return nil, err
+	}
+	return &challenge, tx.Commit()
+}
+
+// AcceptChallenge takes the other side of an open challenge, matching its
+// stake into escrow. Users can't accept their own challenges.
+func (r *Repository) AcceptChallenge(userID, challengeID int) (*Challenge, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+        UPDATE challenges SET status = ?, acceptor_id = ?, accepted_at = ?
+        WHERE id = ? AND status = ? AND challenger_id != ?
+          AND fight_id IN (SELECT id FROM fights WHERE status = 'scheduled')`,
+		ChallengeMatched, userID, r.sqlNow(), challengeID, ChallengeOpen, userID)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, ErrChallengeNotOpen
+	}
+
+	var challenge Challenge
+	if err := tx.Get(&challenge, `SELECT * FROM challenges WHERE id = ?`, challengeID); err != nil {
+		return nil, err
+	}
+	if _, err := r.postCredit(tx, CreditMove{
+		UserID: userID,
+		Amount: -challenge.Stake,
+		Reason: ReasonChallengeEscrow,
+		Ref:    ChallengeRef(challenge.ID),
+		Memo:   fmt.Sprintf("Fight %d, accepted challenge", challenge.FightID),
+	}); err != nil {
+		return nil, err
+	}
+	return &challenge, tx.Commit()
+}
+
+// WithdrawChallenge returns an untaken challenge's stake to its challenger
+func (r *Repository) WithdrawChallenge(userID, challengeID int) (*Challenge, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+        UPDATE challenges SET status = ?, resolved_at = ?
+        WHERE id = ? AND challenger_id = ? AND status = ?`,
+		ChallengeWithdrawn, r.sqlNow(), challengeID, userID, ChallengeOpen)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, ErrChallengeNotOpen
+	}
+
+	var challenge Challenge
+	if err := tx.Get(&challenge, `SELECT * FROM challenges WHERE id = ?`, challengeID); err != nil {
+		return nil, err
+	}
+	if _, err := r.postCredit(tx, CreditMove{
+		UserID: userID,
+		Amount: challenge.Stake,
+		Reason: ReasonChallengeRefund,
+		Ref:    ChallengeRef(challenge.ID),
+		Memo:   "Withdrawn",
+	}); err != nil {
+		return nil, err
+	}
+	return &challenge, tx.Commit()
+}
+
+// ProcessChallengesForFight settles the fight's challenges out of escrow:
+// matched ones pay the pot to whoever backed the winner, and challenges
+// nobody took, or matched on a draw, are refunded. Returns the challenges it
+// settled for announcing.
+func (r *Repository) ProcessChallengesForFight(fightID int, winnerID *int) ([]Challenge, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	settled, err := r.settleChallenges(tx, fightID, winnerID, fmt.Sprintf("Fight %d", fightID))
+	if err != nil {
+		return nil, err
+	}
+	return settled, tx.Commit()
+}
+
+// settleChallenges settles every open or matched challenge on a fight inside
+// the caller's transaction. A nil winner refunds them all.
+func (r *Repository) settleChallenges(tx *sqlx.Tx, fightID int, winnerID *int, memo string) ([]Challenge, error) {
+	var challenges []Challenge
+	if err := tx.Select(&challenges, `
+        SELECT * FROM challenges WHERE fight_id = ? AND status IN (?, ?) ORDER BY id`,
+		fightID, ChallengeOpen, ChallengeMatched); err != nil {
+		return nil, err
+	}
+
+	for i := range challenges {
+		c := &challenges[i]
+		refund := func(userID int) error {
+			_, err := r.postCredit(tx, CreditMove{
+				UserID: userID,
+				Amount: c.Stake,
+				Reason: ReasonChallengeRefund,
+				Ref:    ChallengeRef(c.ID),
+				Memo:   memo,
+			})
+			return err
+		}
+
+		switch {
+		case c.Status == ChallengeOpen:
+			// Nobody took it before the bell
+			c.Status = ChallengeRefunded
+			if err := refund(c.ChallengerID); err != nil {
+				return nil, err
+			}
+		case winnerID == nil:
+			c.Status = ChallengeRefunded
+			if err := refund(c.ChallengerID); err != nil {
+				return nil, err
+			}
+			if err := refund(int(c.AcceptorID.Int64)); err != nil {
+				return nil, err
+			}
+		default:
+			winner := c.ChallengerID
+			if *winnerID == c.AcceptorFighterID {
+				winner = int(c.AcceptorID.Int64)
+			} else if *winnerID != c.ChallengerFighterID {
+				return nil, fmt.Errorf("challenge %d: winner %d is neither side", c.ID, *winnerID)
+			}
+			c.Status = ChallengeSettled
+			c.WinnerID = sql.NullInt64{Int64: int64(winner), Valid: true}
+			if _, err := r.postCredit(tx, CreditMove{
+				UserID: winner,
+				Amount: c.Pot(),
+				Reason: ReasonChallengePayout,
+				Ref:    ChallengeRef(c.ID),
+				Memo:   memo,
+			}); err != nil {
+				return nil, err
+			}
+		}
+
+		if _, err := tx.Exec(`
+            UPDATE challenges SET status = ?, winner_id = ?, resolved_at = ?
+            WHERE id = ?`, c.Status, c.WinnerID, r.sqlNow(), c.ID); err != nil {
+			return nil, err
+		}
+	}
+	return challenges, nil
+}
+
+// GetChallenge returns a single challenge
+func (r *Repository) GetChallenge(challengeID int) (*Challenge, error) {
+	var challenge Challenge
+	err := r.db.Get(&challenge, `SELECT * FROM challenges WHERE id = ?`, challengeID)
+	return &challenge, err
+}
+
+const challengeWithNamesQuery = `
+    SELECT c.*,
+        COALESCE(NULLIF(TRIM(cu.custom_username), ''), cu.username) AS challenger_name,
+        COALESCE(NULLIF(TRIM(au.custom_username), ''), au.username) AS acceptor_name,
+        cf.name AS challenger_fighter_name,
+        af.name AS acceptor_fighter_name
+    FROM challenges c
+    JOIN users cu ON c.challenger_id = cu.id
+    LEFT JOIN users au ON c.acceptor_id = au.id
+    JOIN fighters cf ON c.challenger_fighter_id = cf.id
+    JOIN fighters af ON c.acceptor_fighter_id = af.id`
+
+// GetChallengesForFight returns a fight's challenges other than withdrawn
+// ones, open ones first
+func (r *Repository) GetChallengesForFight(fightID int) ([]ChallengeWithNames, error) {
+	challenges := []ChallengeWithNames{}
+	err := r.db.Select(&challenges, challengeWithNamesQuery+`
+        WHERE c.fight_id = ? AND c.status != ?
+        ORDER BY c.status = 'open' DESC, c.stake DESC, c.id`, fightID, ChallengeWithdrawn)
+	return challenges, err
+}
+
+// GetUserChallenges returns challenges the user posted or accepted, newest first
+func (r *Repository) GetUserChallenges(userID, limit int) ([]ChallengeWithNames, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	challenges := []ChallengeWithNames{}
+	err := r.db.Select(&challenges, challengeWithNamesQuery+`
+        WHERE c.challenger_id = ? OR c.acceptor_id = ?
+        ORDER BY c.id DESC LIMIT ?`, userID, userID, limit)
+	return challenges, err
+}
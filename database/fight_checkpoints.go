@@ -0,0 +1,51 @@
+package database
+
+func (r *Repository) ensureFightCheckpointsTable() error {
+	exists, err := r.tableExists("fight_checkpoints")
+	if err != nil || exists {
+		return err
+	}
+
+	_, err = r.db.Exec(`
+        CREATE TABLE fight_checkpoints (
+            fight_id INTEGER PRIMARY KEY,
+            tick INTEGER NOT NULL,
+            round INTEGER NOT NULL DEFAULT 0,
+            ruleset TEXT NOT NULL DEFAULT '',
+            algo_version TEXT NOT NULL DEFAULT '',
+            state_json TEXT NOT NULL,
+            updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            FOREIGN KEY (fight_id) REFERENCES fights(id)
+        );
+    `)
+	return err
+}
+
+// SaveFightCheckpoint stores the latest state of a live fight, replacing the previous checkpoint
+func (r *Repository) SaveFightCheckpoint(cp FightCheckpoint) error {
+	_, err := r.db.Exec(`
+        INSERT INTO fight_checkpoints (fight_id, tick, round, ruleset, algo_version, state_json, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?)
+        ON CONFLICT(fight_id) DO UPDATE SET
+            tick = excluded.tick,
+            round = excluded.round,
+            ruleset = excluded.ruleset,
+            algo_version = excluded.algo_version,
+            state_json = excluded.state_json,
+            updated_at = excluded.updated_at`,
+		cp.FightID, cp.Tick, cp.Round, cp.Ruleset, cp.AlgoVersion, cp.StateJSON, r.sqlNow())
+	return err
+}
+
+// GetFightCheckpoint returns the last checkpoint saved for a fight
+func (r *Repository) GetFightCheckpoint(fightID int) (*FightCheckpoint, error) {
+	var cp FightCheckpoint
+	err := r.db.Get(&cp, `SELECT * FROM fight_checkpoints WHERE fight_id = ?`, fightID)
+	return &cp, err
+}
+
+// DeleteFightCheckpoint drops a fight's checkpoint once it no longer needs resuming
+func (r *Repository) DeleteFightCheckpoint(fightID int) error {
+	_, err := r.db.Exec(`DELETE FROM fight_checkpoints WHERE fight_id = ?`, fightID)
+	return err
+}
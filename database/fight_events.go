@@ -0,0 +1,98 @@
+package database
+
+import "strings"
+
+// Fight event types
+const (
+	FightEventDamage      = "damage"
+	FightEventCritical    = "critical"
+	FightEventFrenzy      = "frenzy"
+	FightEventRound       = "round"
+	FightEventClapSummary = "clap_summary"
+	FightEventDeath       = "death"
+	FightEventSpecial     = "special"
+)
+
+func (r *Repository) ensureFightEventsTable() error {
+	exists, err := r.tableExists("fight_events")
+	if err != nil || exists {
+		return err
+	}
+
+	_, err = r.db.Exec(`
+        CREATE TABLE fight_events (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            fight_id INTEGER NOT NULL,
+            tick INTEGER NOT NULL,
+            seq INTEGER NOT NULL,
+            round INTEGER NOT NULL DEFAULT 0,
+            event_type TEXT NOT NULL,
+            attacker TEXT NOT NULL DEFAULT '',
+            victim TEXT NOT NULL DEFAULT '',
+            damage INTEGER NOT NULL DEFAULT 0,
+            health1 INTEGER NOT NULL DEFAULT 0,
+            health2 INTEGER NOT NULL DEFAULT 0,
+            payload_json TEXT NOT NULL DEFAULT '',
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            UNIQUE(fight_id, tick, seq),
+            FOREIGN KEY (fight_id) REFERENCES fights(id)
+        );
+    `)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(`CREATE INDEX idx_fight_events_type ON fight_events(fight_id, event_type)`)
+	return err
+}
+
+// InsertFightEvents stores a batch of fight events in one transaction. Events
+// are keyed by (fight, tick, seq), so re-simulating a fight after a restart
+// leaves the rows that were already written untouched.
+func (r *Repository) InsertFightEvents(events []FightEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+        INSERT OR IGNORE INTO fight_events
+            (fight_id, tick, seq, round, event_type, attacker, victim, damage, health1, health2, payload_json)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, ev := range events {
+		if _, err := stmt.Exec(ev.FightID, ev.Tick, ev.Seq, ev.Round, ev.EventType, ev.Attacker, ev.Victim,
+			ev.Damage, ev.Health1, ev.Health2, ev.PayloadJSON); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetFightEvents returns up to limit events for a fight with an id greater than
+// afterID, in the order they happened. An empty eventTypes returns every type.
+func (r *Repository) GetFightEvents(fightID, afterID, limit int, eventTypes []string) ([]FightEvent, error) {
+	events := []FightEvent{}
+	query := `SELECT * FROM fight_events WHERE fight_id = ? AND id > ?`
+	args := []interface{}{fightID, afterID}
+	if len(eventTypes) > 0 {
+		query += ` AND event_type IN (?` + strings.Repeat(`, ?`, len(eventTypes)-1) + `)`
+		for _, t := range eventTypes {
+			args = append(args, t)
+		}
+	}
+	query += ` ORDER BY id LIMIT ?`
+	args = append(args, limit)
+
+	err := r.db.Select(&events, query, args...)
+	return events, err
+}
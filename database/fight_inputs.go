@@ -0,0 +1,78 @@
+package database
+
+// Fight input types
+const (
+	FightInputFighterSnapshot = "fighter_snapshot"
+	FightInputClapHeal        = "clap_heal"
+	FightInputTickSalt        = "tick_salt"
+)
+
+func (r *Repository) ensureFightInputsTable() error {
+	exists, err := r.tableExists("fight_inputs")
+	if err != nil || exists {
+		return err
+	}
+
+	_, err = r.db.Exec(`
+        CREATE TABLE fight_inputs (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            fight_id INTEGER NOT NULL,
+            tick INTEGER NOT NULL,
+            input_type TEXT NOT NULL,
+            lane INTEGER NOT NULL,
+            fighter_id INTEGER NOT NULL,
+            value INTEGER NOT NULL DEFAULT 0,
+            payload_json TEXT NOT NULL DEFAULT '',
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            UNIQUE(fight_id, tick, input_type, lane),
+            FOREIGN KEY (fight_id) REFERENCES fights(id)
+        );
+    `)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(`CREATE INDEX idx_fight_inputs_fight ON fight_inputs(fight_id, tick)`)
+	return err
+}
+
+// RecordFighterSnapshot stores the effect-modified fighter a lane started the
+// fight with. The first snapshot wins so restarts can't rewrite history.
+func (r *Repository) RecordFighterSnapshot(fightID, lane, fighterID, startingHealth int, payloadJSON string) error {
+	_, err := r.db.Exec(`
+        INSERT OR IGNORE INTO fight_inputs (fight_id, tick, input_type, lane, fighter_id, value, payload_json)
+        VALUES (?, 0, ?, ?, ?, ?, ?)`,
+		fightID, FightInputFighterSnapshot, lane, fighterID, startingHealth, payloadJSON)
+	return err
+}
+
+// RecordClapHeal stores the crowd healing a lane received on a tick
+func (r *Repository) RecordClapHeal(fightID, tick, lane, fighterID, amount int) error {
+	_, err := r.db.Exec(`
+        INSERT OR IGNORE INTO fight_inputs (fight_id, tick, input_type, lane, fighter_id, value)
+        VALUES (?, ?, ?, ?, ?, ?)`,
+		fightID, tick, FightInputClapHeal, lane, fighterID, amount)
+	return err
+}
+
+// RecordTickSalt stores the secret salt added to every tick seed of a fight and
+// returns the salt the fight actually runs with. The first salt wins, so a
+// restart keeps playing the fight it started.
+func (r *Repository) RecordTickSalt(fightID int, salt int64) (int64, error) {
+	_, err := r.db.Exec(`
+        INSERT OR IGNORE INTO fight_inputs (fight_id, tick, input_type, lane, fighter_id, value)
+        VALUES (?, 0, ?, 0, 0, ?)`,
+		fightID, FightInputTickSalt, salt)
+	if err != nil {
+		return 0, err
+	}
+	var stored int64
+	err = r.db.Get(&stored, `SELECT value FROM fight_inputs WHERE fight_id = ? AND input_type = ?`, fightID, FightInputTickSalt)
+	return stored, err
+}
+
+// GetFightInputs returns every recorded input for a fight in tick order
+func (r *Repository) GetFightInputs(fightID int) ([]FightInput, error) {
+	var inputs []FightInput
+	err := r.db.Select(&inputs, `SELECT * FROM fight_inputs WHERE fight_id = ? ORDER BY tick, lane, id`, fightID)
+	return inputs, err
+}
@@ -0,0 +1,62 @@
+package database
+
+import "time"
+
+func (r *Repository) ensureFightOddsTable() error {
+	exists, err := r.tableExists("fight_odds")
+	if err != nil || exists {
+		return err
+	}
+
+	_, err = r.db.Exec(`
+        CREATE TABLE fight_odds (
+            fight_id INTEGER PRIMARY KEY,
+            fighter1_win REAL NOT NULL DEFAULT 0,
+            fighter2_win REAL NOT NULL DEFAULT 0,
+            draw REAL NOT NULL DEFAULT 0,
+            death REAL NOT NULL DEFAULT 0,
+            simulations INTEGER NOT NULL DEFAULT 0,
+            ruleset TEXT NOT NULL DEFAULT '',
+            algo_version TEXT NOT NULL DEFAULT '',
+            updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            FOREIGN KEY (fight_id) REFERENCES fights(id)
+        );
+    `)
+	return err
+}
+
+// SaveFightOdds stores the latest price for a fight, replacing any earlier one
+func (r *Repository) SaveFightOdds(odds FightOdds) error {
+	_, err := r.db.Exec(`
+        INSERT INTO fight_odds (fight_id, fighter1_win, fighter2_win, draw, death, simulations, ruleset, algo_version, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+        ON CONFLICT(fight_id) DO UPDATE SET
+            fighter1_win = excluded.fighter1_win,
+            fighter2_win = excluded.fighter2_win,
+            draw = excluded.draw,
+            death = excluded.death,
+            simulations = excluded.simulations,
+            ruleset = excluded.ruleset,
+            algo_version = excluded.algo_version,
+            updated_at = excluded.updated_at`,
+		odds.FightID, odds.Fighter1Win, odds.Fighter2Win, odds.Draw, odds.Death, odds.Simulations, odds.Ruleset, odds.AlgoVersion, r.sqlNow())
+	return err
+}
+
+// GetFightOdds returns the latest price for a fight
+func (r *Repository) GetFightOdds(fightID int) (*FightOdds, error) {
+	var odds FightOdds
+	err := r.db.Get(&odds, `SELECT * FROM fight_odds WHERE fight_id = ?`, fightID)
+	return &odds, err
+}
+
+// GetUnpricedScheduledFights returns scheduled fights in [start, end) that have no odds yet
+func (r *Repository) GetUnpricedScheduledFights(start, end time.Time) ([]Fight, error) {
+	var fights []Fight
+	err := r.db.Select(&fights, `
+        SELECT f.* FROM fights f
+        LEFT JOIN fight_odds o ON o.fight_id = f.id
+        WHERE f.status = 'scheduled' AND f.scheduled_time >= ? AND f.scheduled_time < ? AND o.fight_id IS NULL
+        ORDER BY f.scheduled_time`, start, end)
+	return fights, err
+}
@@ -0,0 +1,74 @@
+package database
+
+import "time"
+
+// FighterHistory is a fighter's record going into a fight, as the commentary
+// booth sees it
+type FighterHistory struct {
+	FighterID  int
+	Wins       int
+	Losses     int
+	Draws      int
+	WinStreak  int // consecutive wins coming into the fight
+	LossStreak int // consecutive losses coming into the fight
+	Kills      int
+}
+
+// killedBefore restricts fighter_kills rows (aliased k) to kills from fights
+// and battle royales that finished before a cutoff
+const killedBefore = `(k.fight_id IN (SELECT id FROM fights WHERE status = 'completed' AND completed_at < ?)
+	OR k.battle_royale_id IN (SELECT id FROM battle_royales WHERE status = 'completed' AND completed_at < ?))`
+
+// GetFighterHistory returns a fighter's record and streak from the fights that
+// finished before a cutoff, normally the scheduled start of the fight being
+// called. A replay then hears the same history the fight went out with, and
+// never the result of the fight itself or anything after it.
+func (r *Repository) GetFighterHistory(fighterID int, before time.Time) (*FighterHistory, error) {
+	cutoff := sqlTime(before)
+	history := &FighterHistory{FighterID: fighterID}
+
+	err := r.db.Get(&history.Kills, `
+		SELECT COUNT(1) FROM fighter_kills k
+		WHERE k.killer_fighter_id = ? AND `+killedBefore,
+		fighterID, cutoff, cutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	var fights []Fight
+	err = r.db.Select(&fights, `
+		SELECT * FROM fights
+		WHERE status = 'completed' AND completed_at < ?
+		  AND (fighter1_id = ? OR fighter2_id = ? OR partner1_id = ? OR partner2_id = ?)
+		ORDER BY completed_at DESC`,
+		cutoff, fighterID, fighterID, fighterID, fighterID)
+	if err != nil {
+		return nil, err
+	}
+
+	streaking := true
+	for _, f := range fights {
+		won, lost := f.WonBy(fighterID), f.WinnerID.Valid && !f.WonBy(fighterID)
+		switch {
+		case won:
+			history.Wins++
+		case lost:
+			history.Losses++
+		default:
+			history.Draws++
+		}
+
+		// Most recent first; draws break a streak either way
+		if !streaking {
+			continue
+		}
+		if won && history.LossStreak == 0 {
+			history.WinStreak++
+		} else if lost && history.WinStreak == 0 {
+			history.LossStreak++
+		} else {
+			streaking = false
+		}
+	}
+	return history, nil
+}
@@ -0,0 +1,77 @@
+package database
+
+import "time"
+
+func (r *Repository) ensureFighterInjuriesTable() error {
+	exists, err := r.tableExists("fighter_injuries")
+	if err != nil || exists {
+		return err
+	}
+
+	_, err = r.db.Exec(`
+        CREATE TABLE fighter_injuries (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            fighter_id INTEGER NOT NULL,
+            fight_id INTEGER NOT NULL,
+            severity INTEGER NOT NULL DEFAULT 0,
+            fatigue INTEGER NOT NULL DEFAULT 0,
+            final_health INTEGER NOT NULL DEFAULT 0,
+            crits_taken INTEGER NOT NULL DEFAULT 0,
+            frenzies INTEGER NOT NULL DEFAULT 0,
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            UNIQUE(fighter_id, fight_id),
+            FOREIGN KEY (fighter_id) REFERENCES fighters(id),
+            FOREIGN KEY (fight_id) REFERENCES fights(id)
+        );
+    `)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(`CREATE INDEX idx_fighter_injuries_fighter ON fighter_injuries(fighter_id, created_at)`)
+	return err
+}
+
+// RecordFighterInjury stores the damage a fighter carried out of a fight. A
+// fight only ever injures a fighter once, so completing it again is a no-op.
+// Times are kept in UTC so the range queries compare like with like.
+func (r *Repository) RecordFighterInjury(injury FighterInjury) error {
+	_, err := r.db.Exec(`
+        INSERT OR IGNORE INTO fighter_injuries (fighter_id, fight_id, severity, fatigue, final_health, crits_taken, frenzies, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		injury.FighterID, injury.FightID, injury.Severity, injury.Fatigue, injury.FinalHealth,
+		injury.CritsTaken, injury.Frenzies, injury.CreatedAt.UTC())
+	return err
+}
+
+// GetFighterInjuries returns a fighter's injuries picked up in [since, before)
+func (r *Repository) GetFighterInjuries(fighterID int, since, before time.Time) ([]FighterInjury, error) {
+	var injuries []FighterInjury
+	err := r.db.Select(&injuries, `
+        SELECT * FROM fighter_injuries
+        WHERE fighter_id = ? AND created_at >= ? AND created_at < ?
+        ORDER BY created_at`, fighterID, since.UTC(), before.UTC())
+	return injuries, err
+}
+
+// GetInjuriesBetween returns every fighter's injuries picked up in [since, before)
+func (r *Repository) GetInjuriesBetween(since, before time.Time) ([]FighterInjury, error) {
+	var injuries []FighterInjury
+	err := r.db.Select(&injuries, `
+        SELECT * FROM fighter_injuries
+        WHERE created_at >= ? AND created_at < ?
+        ORDER BY created_at`, since.UTC(), before.UTC())
+	return injuries, err
+}
+
+// CountFightEventsFor counts a fight's events of one type naming fighterName
+// as attacker or victim
+func (r *Repository) CountFightEventsFor(fightID int, eventType, role, fighterName string) (int, error) {
+	column := "victim"
+	if role == "attacker" {
+		column = "attacker"
+	}
+	var count int
+	err := r.db.Get(&count, `SELECT COUNT(*) FROM fight_events WHERE fight_id = ? AND event_type = ? AND `+column+` = ?`,
+		fightID, eventType, fighterName)
+	return count, err
+}
@@ -0,0 +1,60 @@
+package database
+
+import "fmt"
+
+// ensureBetPricingColumns adds the locked-in price and acceptance tick to bets.
+// Zero odds means the bet was placed before the fight at the flat rate.
+func (r *Repository) ensureBetPricingColumns() error {
+	columns := []struct{ name, ddl string }{
+		{"odds", `ALTER TABLE bets ADD COLUMN odds REAL NOT NULL DEFAULT 0`},
+		{"placed_tick", `ALTER TABLE bets ADD COLUMN placed_tick INTEGER NOT NULL DEFAULT 0`},
+	}
+	for _, c := range columns {
+		exists, err := r.columnExists("bets", c.name)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if _, err := r.db.Exec(c.ddl); err != nil {
+			return fmt.Errorf("add column bets.%s: %w", c.name, err)
+		}
+	}
+	return nil
+}
+
+// CreateInPlayBet places a bet on a live fight at the quoted decimal odds,
+// locked to the tick it was accepted on. The stake is taken in the same
+// transaction so a user can't spend the same credits twice.
+func (r *Repository) CreateInPlayBet(userID, fightID, fighterID, amount int, odds float64, tick int) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+        INSERT INTO bets (user_id, fight_id, fighter_id, amount, status, odds, placed_tick, created_at)
+        VALUES (?, ?, ?, ?, 'pending', ?, ?, ?)`,
+		userID, fightID, fighterID, amount, odds, tick, r.sqlNow())
+	if err != nil {
+		return 0, err
+	}
+	betID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := r.postCredit(tx, CreditMove{
+		UserID: userID,
+		Amount: -amount,
+		Reason: ReasonBetStake,
+		Ref:    BetRef(int(betID)),
+		Memo:   fmt.Sprintf("In-play on fight %d at %.2f", fightID, odds),
+	}); err != nil {
+		return 0, err
+	}
+
+	return int(betID), tx.Commit()
+}
@@ -0,0 +1,370 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Every change to a user's credits is posted to credit_transactions in the
+// same transaction as the balance update. A row moves Amount into the user's
+// balance and out of one of the house accounts below, so the books always sum
+// to zero and users.credits can be rebuilt by replaying a user's rows.
+
+// CreditReason says why credits moved
+type CreditReason string
+
+const (
+	ReasonOpeningBalance   CreditReason = "opening_balance" // balance held before the ledger existed
+	ReasonSignupGrant      CreditReason = "signup_grant"
+	ReasonDailyTopUp       CreditReason = "daily_top_up"
+	ReasonMVPReward        CreditReason = "mvp_reward"
+	ReasonBetStake         CreditReason = "bet_stake"
+	ReasonBetPayout        CreditReason = "bet_payout"
+	ReasonBetRefund        CreditReason = "bet_refund"
+	ReasonBetCancel        CreditReason = "bet_cancel"   // stake back less the cancellation fee
+	ReasonBetCashOut       CreditReason = "bet_cash_out" // settled early at the live offer
+	ReasonCasinoStake      CreditReason = "casino_stake"
+	ReasonCasinoPayout     CreditReason = "casino_payout"
+	ReasonCasinoRefund     CreditReason = "casino_refund" // stake back on a push
+	ReasonExtortion        CreditReason = "extortion"
+	ReasonExtortionRefund  CreditReason = "extortion_refund"
+	ReasonHighRollerTax    CreditReason = "high_roller_tax"
+	ReasonShopPurchase     CreditReason = "shop_purchase"
+	ReasonSettingChange    CreditReason = "setting_change"
+	ReasonLoanDisbursement CreditReason = "loan_disbursement"
+	ReasonLoanRepayment    CreditReason = "loan_repayment"
+	ReasonLoanGarnishment  CreditReason = "loan_garnishment" // taken from winnings while in default
+	ReasonChallengeEscrow  CreditReason = "challenge_escrow"
+	ReasonChallengePayout  CreditReason = "challenge_payout"
+	ReasonChallengeRefund  CreditReason = "challenge_refund"
+)
+
+// House accounts on the other side of every credit movement
+const (
+	AccountMint       = "mint"       // credits created from nothing: grants, top-ups, rewards
+	AccountSportsbook = "sportsbook" // fight and royale bets
+	AccountCasino     = "casino"
+	AccountShop       = "shop"
+	AccountTreasury   = "treasury" // taxes and the goons' cut
+	AccountLoanOffice = "loan_office"
+	AccountEscrow     = "escrow" // stakes held on user-to-user challenges
+)
+
+// reasonAccounts picks the house account each reason posts against
+var reasonAccounts = map[CreditReason]string{
+	ReasonOpeningBalance:   AccountMint,
+	ReasonSignupGrant:      AccountMint,
+	ReasonDailyTopUp:       AccountMint,
+	ReasonMVPReward:        AccountMint,
+	ReasonBetStake:         AccountSportsbook,
+	ReasonBetPayout:        AccountSportsbook,
+	ReasonBetRefund:        AccountSportsbook,
+	ReasonBetCancel:        AccountSportsbook,
+	ReasonBetCashOut:       AccountSportsbook,
+	ReasonCasinoStake:      AccountCasino,
+	ReasonCasinoPayout:     AccountCasino,
+	ReasonCasinoRefund:     AccountCasino,
+	ReasonExtortion:        AccountTreasury,
+	ReasonExtortionRefund:  AccountTreasury,
+	ReasonHighRollerTax:    AccountTreasury,
+	ReasonShopPurchase:     AccountShop,
+	ReasonSettingChange:    AccountShop,
+	ReasonLoanDisbursement: AccountLoanOffice,
+	ReasonLoanRepayment:    AccountLoanOffice,
+	ReasonLoanGarnishment:  AccountLoanOffice,
+	ReasonChallengeEscrow:  AccountEscrow,
+	ReasonChallengePayout:  AccountEscrow,
+	ReasonChallengeRefund:  AccountEscrow,
+}
+
+// CreditRef points a ledger row at whatever caused it
+type CreditRef struct {
+	Type string
+	ID   string
+}
+
+// Reference types
+const (
+	RefFight      = "fight"
+	RefBet        = "bet"
+	RefRoyaleBet  = "royale_bet"
+	RefPropBet    = "prop_bet"
+	RefBetSlip    = "bet_slip"
+	RefCasinoGame = "casino_game"
+	RefTaxWeek    = "tax_week"
+	RefShopItem   = "shop_item"
+	RefSetting    = "setting"
+	RefLoan       = "loan"
+	RefChallenge  = "challenge"
+)
+
+// Refs for the common causes of a credit movement
+func FightRef(fightID int) CreditRef      { return CreditRef{RefFight, strconv.Itoa(fightID)} }
+func BetRef(betID int) CreditRef          { return CreditRef{RefBet, strconv.Itoa(betID)} }
+func PropBetRef(betID int) CreditRef      { return CreditRef{RefPropBet, strconv.Itoa(betID)} }
+func BetSlipRef(slipID int) CreditRef     { return CreditRef{RefBetSlip, strconv.Itoa(slipID)} }
+func RoyaleBetRef(betID int) CreditRef    { return CreditRef{RefRoyaleBet, strconv.Itoa(betID)} }
+func CasinoGameRef(game string) CreditRef { return CreditRef{RefCasinoGame, game} }
+func TaxWeekRef(weekKey string) CreditRef { return CreditRef{RefTaxWeek, weekKey} }
+func ShopItemRef(itemID int) CreditRef    { return CreditRef{RefShopItem, strconv.Itoa(itemID)} }
+func LoanRef(loanID int) CreditRef        { return CreditRef{RefLoan, strconv.Itoa(loanID)} }
+func ChallengeRef(id int) CreditRef       { return CreditRef{RefChallenge, strconv.Itoa(id)} }
+func SettingRef(settingType string) CreditRef {
+	return CreditRef{RefSetting, settingType}
+}
+
+// CreditMove is one change to a user's balance
+type CreditMove struct {
+	UserID int
+	Amount int // positive pays the user, negative charges them
+	Reason CreditReason
+	Ref    CreditRef
+	Memo   string
+}
+
+// CreditTransaction is a posted ledger row
+type CreditTransaction struct {
+	ID           int          `db:"id" json:"id"`
+	UserID       int          `db:"user_id" json:"user_id"`
+	Amount       int          `db:"amount" json:"amount"`
+	BalanceAfter int          `db:"balance_after" json:"balance_after"`
+	Account      string       `db:"account" json:"account"`
+	Reason       CreditReason `db:"reason" json:"reason"`
+	RefType      string       `db:"ref_type" json:"ref_type"`
+	RefID        string       `db:"ref_id" json:"ref_id"`
+	Memo         string       `db:"memo" json:"memo"`
+	CreatedAt    time.Time    `db:"created_at" json:"created_at"`
+}
+
+// ErrInsufficientCredits is returned when a charge would take a balance below zero
+var ErrInsufficientCredits = errors.New("insufficient credits")
+
+// ensureCreditTransactionsTable creates the ledger and opens it with every
+// user's current balance, so replaying it matches users.credits from day one
+func (r *Repository) ensureCreditTransactionsTable() error {
+	exists, err := r.tableExists("credit_transactions")
+	if err != nil || exists {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+        CREATE TABLE credit_transactions (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            user_id INTEGER NOT NULL,
+            amount INTEGER NOT NULL,
+            balance_after INTEGER NOT NULL,
+            account TEXT NOT NULL,
+            reason TEXT NOT NULL,
+            ref_type TEXT NOT NULL DEFAULT '',
+            ref_id TEXT NOT NULL DEFAULT '',
+            memo TEXT NOT NULL DEFAULT '',
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            FOREIGN KEY (user_id) REFERENCES users(id)
+        );
+        CREATE INDEX idx_credit_transactions_user ON credit_transactions(user_id, id);
+        CREATE INDEX idx_credit_transactions_ref ON credit_transactions(ref_type, ref_id);
+    `); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+        INSERT INTO credit_transactions (user_id, amount, balance_after, account, reason, created_at)
+        SELECT id, credits, credits, ?, ?, ? FROM users WHERE credits != 0 ORDER BY id`,
+		AccountMint, ReasonOpeningBalance, r.sqlNow()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ledgerExecutor is satisfied by the database and by transactions
+type ledgerExecutor interface {
+	sqlExecutor
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// postCredit applies a move to the user's balance and records it, returning
+// the new balance. Run it inside the caller's transaction so the balance and
+// its ledger row commit or roll back together.
+func (r *Repository) postCredit(exec ledgerExecutor, m CreditMove) (int, error) {
+	account, ok := reasonAccounts[m.Reason]
+	if !ok {
+		return 0, fmt.Errorf("unknown credit reason %q", m.Reason)
+	}
+
+	res, err := exec.Exec(`
+        UPDATE users SET credits = credits + ?, updated_at = ?
+        WHERE id = ? AND (? >= 0 OR credits + ? >= 0)`,
+		m.Amount, r.sqlNow(), m.UserID, m.Amount, m.Amount)
+	if err != nil {
+		return 0, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		var one int
+		if err := exec.QueryRow(`SELECT 1 FROM users WHERE id = ?`, m.UserID).Scan(&one); err != nil {
+			return 0, fmt.Errorf("user %d: %w", m.UserID, err)
+		}
+		return 0, ErrInsufficientCredits
+	}
+
+	var balance int
+	if err := exec.QueryRow(`SELECT credits FROM users WHERE id = ?`, m.UserID).Scan(&balance); err != nil {
+		return 0, err
+	}
+	if _, err := exec.Exec(`
+        INSERT INTO credit_transactions (user_id, amount, balance_after, account, reason, ref_type, ref_id, memo, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		m.UserID, m.Amount, balance, account, m.Reason, m.Ref.Type, m.Ref.ID, m.Memo, r.sqlNow()); err != nil {
+		return 0, err
+	}
+
+	// Borrowers in default have their winnings garnished as they're paid
+	if garnishable(m.Reason) && m.Amount > 0 {
+		if err := r.garnishPayout(exec, m); err != nil {
+			return 0, fmt.Errorf("garnish payout: %w", err)
+		}
+		if err := exec.QueryRow(`SELECT credits FROM users WHERE id = ?`, m.UserID).Scan(&balance); err != nil {
+			return 0, err
+		}
+	}
+	return balance, nil
+}
+
+// garnishable reports whether credits paid for reason count as winnings a
+// defaulted loan can garnish. Refunds and grants are left alone.
+func garnishable(reason CreditReason) bool {
+	switch reason {
+	case ReasonBetPayout, ReasonBetCashOut, ReasonChallengePayout, ReasonCasinoPayout:
+		return true
+	}
+	return false
+}
+
+// MoveCredits posts moves in order in one transaction and returns the last
+// user's resulting balance. A charge that would overdraw fails the lot with
+// ErrInsufficientCredits.
+func (r *Repository) MoveCredits(moves ...CreditMove) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	balance := 0
+	for _, m := range moves {
+		if m.Amount == 0 {
+			// Nothing to post, but still report the balance as it stands
+			if err := tx.QueryRow(`SELECT credits FROM users WHERE id = ?`, m.UserID).Scan(&balance); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		if balance, err = r.postCredit(tx, m); err != nil {
+			return 0, err
+		}
+	}
+	return balance, tx.Commit()
+}
+
+// GetCreditStatement returns a user's ledger rows, newest first. beforeID
+// pages backwards from an earlier statement; pass 0 for the latest rows.
+func (r *Repository) GetCreditStatement(userID, limit, beforeID int) ([]CreditTransaction, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	query := `SELECT * FROM credit_transactions WHERE user_id = ?`
+	args := []interface{}{userID}
+	if beforeID > 0 {
+		query += ` AND id < ?`
+		args = append(args, beforeID)
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows := []CreditTransaction{}
+	err := r.db.Select(&rows, query, args...)
+	return rows, err
+}
+
+// LedgerMismatch is a user whose ledger doesn't replay to their balance
+type LedgerMismatch struct {
+	UserID        int    `json:"user_id"`
+	Username      string `json:"username"`
+	Credits       int    `json:"credits"`        // users.credits
+	LedgerBalance int    `json:"ledger_balance"` // sum of the user's ledger rows
+	// FirstBadEntry is the first row whose balance_after disagrees with the
+	// running total, which is roughly when the balance was changed off the books.
+	// Zero when every row replays cleanly and only the final balance is off.
+	FirstBadEntry int `json:"first_bad_entry,omitempty"`
+}
+
+// LedgerReconciliation is the result of replaying the ledger
+type LedgerReconciliation struct {
+	Users        int              `json:"users"`
+	Transactions int              `json:"transactions"`
+	Mismatches   []LedgerMismatch `json:"mismatches"`
+	// Accounts holds each house account's balance. Together with user balances
+	// they sum to zero.
+	Accounts map[string]int `json:"accounts"`
+}
+
+// Balanced reports whether every user's ledger replayed to their balance
+func (l LedgerReconciliation) Balanced() bool {
+	return len(l.Mismatches) == 0
+}
+
+// ReconcileLedger replays every user's ledger rows in order and compares the
+// running total against each row's recorded balance and against users.credits
+func (r *Repository) ReconcileLedger() (*LedgerReconciliation, error) {
+	var users []User
+	if err := r.db.Select(&users, `SELECT * FROM users ORDER BY id`); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Queryx(`SELECT id, user_id, amount, balance_after, account FROM credit_transactions ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	report := &LedgerReconciliation{Users: len(users), Accounts: map[string]int{}}
+	running := make(map[int]int)
+	firstBad := make(map[int]int)
+	for rows.Next() {
+		var id, userID, amount, balanceAfter int
+		var account string
+		if err := rows.Scan(&id, &userID, &amount, &balanceAfter, &account); err != nil {
+			return nil, err
+		}
+		report.Transactions++
+		report.Accounts[account] -= amount
+		running[userID] += amount
+		if running[userID] != balanceAfter && firstBad[userID] == 0 {
+			firstBad[userID] = id
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, u := range users {
+		if running[u.ID] == u.Credits && firstBad[u.ID] == 0 {
+			continue
+		}
+		report.Mismatches = append(report.Mismatches, LedgerMismatch{
+			UserID:        u.ID,
+			Username:      u.Username,
+			Credits:       u.Credits,
+			LedgerBalance: running[u.ID],
+			FirstBadEntry: firstBad[u.ID],
+		})
+	}
+	return report, nil
+}
@@ -0,0 +1,488 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// Loan office terms. Interest compounds weekly on Mondays and each Monday
+// collects one installment; a borrower who misses enough of them defaults and
+// has their winnings garnished until the balance is cleared.
+const (
+	LoanWeeklyRateBps   = 500 // 5% a week on the outstanding balance
+	LoanTermWeeks       = 4   // installments in a new loan's schedule
+	LoanLimitPercent    = 10  // credit line as a share of lifetime settled stakes
+	LoanMinSettledBets  = 5   // settled bets needed before the office will lend
+	LoanMissesToDefault = 2   // missed installments before a loan defaults
+	LoanGarnishPercent  = 50  // share of each win taken from a defaulted borrower
+)
+
+// Loan statuses
+const (
+	LoanActive    = "active"
+	LoanRepaid    = "repaid"
+	LoanDefaulted = "defaulted"
+)
+
+// Loan entry kinds
+const (
+	LoanEntryDisbursement = "disbursement"
+	LoanEntryInterest     = "interest"
+	LoanEntryRepayment    = "repayment"
+	LoanEntryMissed       = "missed"
+	LoanEntryGarnishment  = "garnishment"
+)
+
+// ErrLoanDenied is returned when a user can't borrow the amount asked for
+var ErrLoanDenied = errors.New("the loan office declines")
+
+// Loan is a credit line drawn from the Department loan office
+type Loan struct {
+	ID             int          `db:"id" json:"id"`
+	UserID         int          `db:"user_id" json:"user_id"`
+	Principal      int          `db:"principal" json:"principal"`
+	Balance        int          `db:"balance" json:"balance"` // owed now, interest included
+	RateBps        int          `db:"rate_bps" json:"rate_bps"`
+	Installment    int          `db:"installment" json:"installment"` // collected each Monday
+	Status         string       `db:"status" json:"status"`
+	MissedPayments int          `db:"missed_payments" json:"missed_payments"`
+	LastWeek       string       `db:"last_week" json:"last_week"` // ISO week interest last accrued in
+	CreatedAt      time.Time    `db:"created_at" json:"created_at"`
+	ClosedAt       sql.NullTime `db:"closed_at" json:"closed_at"`
+}
+
+// LoanEntry is one change to a loan's balance
+type LoanEntry struct {
+	ID           int       `db:"id" json:"id"`
+	LoanID       int       `db:"loan_id" json:"loan_id"`
+	Kind         string    `db:"kind" json:"kind"`
+	Amount       int       `db:"amount" json:"amount"` // positive adds to the debt
+	BalanceAfter int       `db:"balance_after" json:"balance_after"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+}
+
+// LoanInstallment is one Monday on a loan's repayment schedule
+type LoanInstallment struct {
+	Due          time.Time `json:"due"`
+	Interest     int       `json:"interest"`
+	Amount       int       `json:"amount"`
+	BalanceAfter int       `json:"balance_after"`
+}
+
+// loanWeekKey is the ISO week a time falls in, the same key the high-roller
+// tithe uses
+func loanWeekKey(t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%04d-%02d", year, week)
+}
+
+// loanInterest is a week's interest on balance, rounded up so debts never stall
+func loanInterest(balance, rateBps int) int {
+	return int(math.Ceil(float64(balance) * float64(rateBps) / 10000))
+}
+
+// loanInstallment is the level weekly payment that clears principal over
+// weeks at rateBps compounding weekly
+func loanInstallment(principal, rateBps, weeks int) int {
+	r := float64(rateBps) / 10000
+	if r == 0 {
+		return int(math.Ceil(float64(principal) / float64(weeks)))
+	}
+	return int(math.Ceil(float64(principal) * r / (1 - math.Pow(1+r, -float64(weeks)))))
+}
+
+// Schedule projects the Mondays left on an active loan from now, assuming
+// every installment is paid on time
+func (l Loan) Schedule(now time.Time) []LoanInstallment {
+	if l.Status != LoanActive || l.Installment <= 0 {
+		return nil
+	}
+	due := now.AddDate(0, 0, (int(time.Monday)-int(now.Weekday())+7)%7)
+	due = time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, now.Location())
+	if loanWeekKey(due) == l.LastWeek {
+		due = due.AddDate(0, 0, 7) // this Monday has already been collected
+	}
+
+	var schedule []LoanInstallment
+	balance := l.Balance
+	for balance > 0 && len(schedule) < 52 {
+		interest := loanInterest(balance, l.RateBps)
+		balance += interest
+		amount := l.Installment
+		if amount > balance {
+			amount = balance
+		}
+		balance -= amount
+		schedule = append(schedule, LoanInstallment{Due: due, Interest: interest, Amount: amount, BalanceAfter: balance})
+		due = due.AddDate(0, 0, 7)
+	}
+	return schedule
+}
+
+func (r *Repository) ensureLoanTables() error {
+	exists, err := r.tableExists("loans")
+	if err != nil {
+		return err
+	}
+	if !exists {
+		_, err = r.db.Exec(`
+            CREATE TABLE loans (
+                id INTEGER PRIMARY KEY AUTOINCREMENT,
+                user_id INTEGER NOT NULL,
+                principal INTEGER NOT NULL,
+                balance INTEGER NOT NULL,
+                rate_bps INTEGER NOT NULL,
+                installment INTEGER NOT NULL,
+                status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'repaid', 'defaulted')),
+                missed_payments INTEGER NOT NULL DEFAULT 0,
+                last_week TEXT NOT NULL DEFAULT '',
+                created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                closed_at DATETIME,
+                FOREIGN KEY (user_id) REFERENCES users(id)
+            );
+            CREATE INDEX idx_loans_user_status ON loans(user_id, status);
+        `)
+		if err != nil {
+			return err
+		}
+	}
+	// A user owes on at most one loan at a time, however the requests race
+	if _, err := r.db.Exec(`
+        CREATE UNIQUE INDEX IF NOT EXISTS idx_loans_one_open ON loans(user_id) WHERE status != 'repaid'`); err != nil {
+		return err
+	}
+
+	exists, err = r.tableExists("loan_entries")
+	if err != nil {
+		return err
+	}
+	if !exists {
+		_, err = r.db.Exec(`
+            CREATE TABLE loan_entries (
+                id INTEGER PRIMARY KEY AUTOINCREMENT,
+                loan_id INTEGER NOT NULL,
+                kind TEXT NOT NULL,
+                amount INTEGER NOT NULL,
+                balance_after INTEGER NOT NULL,
+                created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                FOREIGN KEY (loan_id) REFERENCES loans(id)
+            );
+            CREATE INDEX idx_loan_entries_loan ON loan_entries(loan_id, id);
+        `)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetLoanLimit returns how much a user can borrow right now: a share of the
+// stakes they've seen through to a result, and nothing while they already owe
+func (r *Repository) GetLoanLimit(userID int) (int, error) {
+	return r.loanLimit(r.db, userID)
+}
+
+// loanLimit is GetLoanLimit read through exec, so a transaction can check the
+// limit it's about to lend against
+func (r *Repository) loanLimit(exec ledgerExecutor, userID int) (int, error) {
+	var owing int
+	if err := exec.QueryRow(`SELECT COUNT(*) FROM loans WHERE user_id = ? AND status != ?`, userID, LoanRepaid).Scan(&owing); err != nil {
+		return 0, err
+	}
+	if owing > 0 {
+		return 0, nil
+	}
+
+	var settled, staked int
+	if err := exec.QueryRow(`
+        SELECT COUNT(*), COALESCE(SUM(amount), 0)
+        FROM bets WHERE user_id = ? AND status IN ('won', 'lost')`, userID).Scan(&settled, &staked); err != nil {
+		return 0, err
+	}
+	if settled < LoanMinSettledBets {
+		return 0, nil
+	}
+	return staked / 100 * LoanLimitPercent, nil
+}
+
+// GetOpenLoan returns the user's active or defaulted loan, or nil
+func (r *Repository) GetOpenLoan(userID int) (*Loan, error) {
+	var loan Loan
+	err := r.db.Get(&loan, `
+        SELECT * FROM loans WHERE user_id = ? AND status != ?
+        ORDER BY id DESC LIMIT 1`, userID, LoanRepaid)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return &loan, err
+}
+
+// GetLoanEntries returns a loan's balance history, oldest first
+func (r *Repository) GetLoanEntries(loanID int) ([]LoanEntry, error) {
+	entries := []LoanEntry{}
+	err := r.db.Select(&entries, `SELECT * FROM loan_entries WHERE loan_id = ? ORDER BY id`, loanID)
+	return entries, err
+}
+
+// HasDefaultedLoan reports whether the user is in default
+func (r *Repository) HasDefaultedLoan(userID int) (bool, error) {
+	var n int
+	err := r.db.Get(&n, `SELECT COUNT(*) FROM loans WHERE user_id = ? AND status = ?`, userID, LoanDefaulted)
+	return n > 0, err
+}
+
+// TakeLoan lends amount to the user within their credit line and pays it out.
+// Interest starts accruing the Monday after.
+func (r *Repository) TakeLoan(userID, amount int, now time.Time) (*Loan, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	limit, err := r.loanLimit(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if amount <= 0 || amount > limit {
+		return nil, fmt.Errorf("%w: your credit line is %d", ErrLoanDenied, limit)
+	}
+
+	loan := Loan{
+		UserID:      userID,
+		Principal:   amount,
+		Balance:     amount,
+		RateBps:     LoanWeeklyRateBps,
+		Installment: loanInstallment(amount, LoanWeeklyRateBps, LoanTermWeeks),
+		Status:      LoanActive,
+		LastWeek:    loanWeekKey(now),
+	}
+	res, err := tx.Exec(`
+        INSERT INTO loans (user_id, principal, balance, rate_bps, installment, status, last_week, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		loan.UserID, loan.Principal, loan.Balance, loan.RateBps, loan.Installment, loan.Status, loan.LastWeek, r.sqlNow())
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code == sqlite3.ErrConstraint {
+		// Another request opened a loan between our check and the insert
+		return nil, fmt.Errorf("%w: you already have an open loan", ErrLoanDenied)
+	}
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	loan.ID = int(id)
+
+	if err := r.addLoanEntry(tx, loan.ID, LoanEntryDisbursement, amount, loan.Balance); err != nil {
+		return nil, err
+	}
+	if _, err := r.postCredit(tx, CreditMove{
+		UserID: userID,
+		Amount: amount,
+		Reason: ReasonLoanDisbursement,
+		Ref:    LoanRef(loan.ID),
+		Memo:   fmt.Sprintf("%d weekly installments of %d", LoanTermWeeks, loan.Installment),
+	}); err != nil {
+		return nil, err
+	}
+	return &loan, tx.Commit()
+}
+
+// RepayLoan pays up to amount off the user's open loan from their credits,
+// returning what was actually paid. Clearing a defaulted loan lifts the default.
+func (r *Repository) RepayLoan(userID, amount int) (int, *Loan, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback()
+
+	var loan Loan
+	if err := tx.Get(&loan, `SELECT * FROM loans WHERE user_id = ? AND status != ? ORDER BY id DESC LIMIT 1`, userID, LoanRepaid); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil, fmt.Errorf("no open loan")
+		}
+		return 0, nil, err
+	}
+	if amount > loan.Balance {
+		amount = loan.Balance
+	}
+	if amount <= 0 {
+		return 0, &loan, fmt.Errorf("nothing to repay")
+	}
+
+	if _, err := r.postCredit(tx, CreditMove{
+		UserID: userID,
+		Amount: -amount,
+		Reason: ReasonLoanRepayment,
+		Ref:    LoanRef(loan.ID),
+	}); err != nil {
+		return 0, nil, err
+	}
+	if err := r.reduceLoan(tx, &loan, LoanEntryRepayment, amount); err != nil {
+		return 0, nil, err
+	}
+	return amount, &loan, tx.Commit()
+}
+
+// addLoanEntry records a change to a loan's balance
+func (r *Repository) addLoanEntry(exec sqlExecutor, loanID int, kind string, amount, balanceAfter int) error {
+	_, err := exec.Exec(`
+        INSERT INTO loan_entries (loan_id, kind, amount, balance_after, created_at)
+        VALUES (?, ?, ?, ?, ?)`, loanID, kind, amount, balanceAfter, r.sqlNow())
+	return err
+}
+
+// reduceLoan takes a payment off a loan's balance, closing it when paid off
+func (r *Repository) reduceLoan(exec sqlExecutor, loan *Loan, kind string, amount int) error {
+	loan.Balance -= amount
+	if loan.Balance <= 0 {
+		loan.Balance = 0
+		loan.Status = LoanRepaid
+	}
+	var closedAt interface{}
+	if loan.Status == LoanRepaid {
+		closedAt = r.sqlNow()
+	}
+	if _, err := exec.Exec(`
+        UPDATE loans SET balance = ?, status = ?, closed_at = COALESCE(closed_at, ?)
+        WHERE id = ?`, loan.Balance, loan.Status, closedAt, loan.ID); err != nil {
+		return err
+	}
+	return r.addLoanEntry(exec, loan.ID, kind, -amount, loan.Balance)
+}
+
+// garnishPayout takes the garnished share of winnings the user was just
+// paid when they're in default. Runs inside postCredit's executor so the
+// payout and the garnishment land together.
+func (r *Repository) garnishPayout(exec ledgerExecutor, m CreditMove) error {
+	var loan Loan
+	err := exec.QueryRow(`
+        SELECT id, balance, status FROM loans WHERE user_id = ? AND status = ?
+        ORDER BY id LIMIT 1`, m.UserID, LoanDefaulted).Scan(&loan.ID, &loan.Balance, &loan.Status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	take := m.Amount * LoanGarnishPercent / 100
+	if take > loan.Balance {
+		take = loan.Balance
+	}
+	if take <= 0 {
+		return nil
+	}
+	if _, err := r.postCredit(exec, CreditMove{
+		UserID: m.UserID,
+		Amount: -take,
+		Reason: ReasonLoanGarnishment,
+		Ref:    LoanRef(loan.ID),
+		Memo:   fmt.Sprintf("%d%% of %s %s", LoanGarnishPercent, m.Ref.Type, m.Ref.ID),
+	}); err != nil {
+		return err
+	}
+	return r.reduceLoan(exec, &loan, LoanEntryGarnishment, take)
+}
+
+// ProcessLoansIfNeeded accrues a week's interest on every open loan on
+// Mondays and collects the installment due from active ones. Idempotent per
+// loan per ISO week, like the high-roller tithe. Returns the users whose loan
+// defaulted or was paid off this run, so their roles can be refreshed.
+func (r *Repository) ProcessLoansIfNeeded(now time.Time) ([]int, error) {
+	if now.Weekday() != time.Monday {
+		return nil, nil
+	}
+	weekKey := loanWeekKey(now)
+
+	var loans []Loan
+	if err := r.db.Select(&loans, `SELECT * FROM loans WHERE status != ? AND last_week != ?`, LoanRepaid, weekKey); err != nil {
+		return nil, err
+	}
+
+	var changed []int
+	for _, loan := range loans {
+		before := loan.Status
+		if err := r.processLoanWeek(loan, weekKey); err != nil {
+			log.Printf("Failed to process loan %d for user %d: %v", loan.ID, loan.UserID, err)
+			continue
+		}
+		if after, err := r.loanStatus(loan.ID); err == nil && after != before {
+			changed = append(changed, loan.UserID)
+		}
+	}
+	return changed, nil
+}
+
+func (r *Repository) loanStatus(loanID int) (string, error) {
+	var status string
+	err := r.db.Get(&status, `SELECT status FROM loans WHERE id = ?`, loanID)
+	return status, err
+}
+
+// processLoanWeek runs one Monday on one loan in a transaction: interest,
+// then the installment, which is taken in full or counted as missed
+func (r *Repository) processLoanWeek(loan Loan, weekKey string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	interest := loanInterest(loan.Balance, loan.RateBps)
+	loan.Balance += interest
+	if _, err := tx.Exec(`UPDATE loans SET balance = ?, last_week = ? WHERE id = ?`, loan.Balance, weekKey, loan.ID); err != nil {
+		return err
+	}
+	if err := r.addLoanEntry(tx, loan.ID, LoanEntryInterest, interest, loan.Balance); err != nil {
+		return err
+	}
+
+	// Defaulted loans only grow; they're paid down by garnishment or by hand
+	if loan.Status == LoanActive {
+		due := loan.Installment
+		if due > loan.Balance {
+			due = loan.Balance
+		}
+		_, err := r.postCredit(tx, CreditMove{
+			UserID: loan.UserID,
+			Amount: -due,
+			Reason: ReasonLoanRepayment,
+			Ref:    LoanRef(loan.ID),
+			Memo:   "Weekly installment " + weekKey,
+		})
+		switch {
+		case err == nil:
+			if err := r.reduceLoan(tx, &loan, LoanEntryRepayment, due); err != nil {
+				return err
+			}
+		case errors.Is(err, ErrInsufficientCredits):
+			loan.MissedPayments++
+			if loan.MissedPayments >= LoanMissesToDefault {
+				loan.Status = LoanDefaulted
+			}
+			if _, err := tx.Exec(`UPDATE loans SET missed_payments = ?, status = ? WHERE id = ?`,
+				loan.MissedPayments, loan.Status, loan.ID); err != nil {
+				return err
+			}
+			if err := r.addLoanEntry(tx, loan.ID, LoanEntryMissed, 0, loan.Balance); err != nil {
+				return err
+			}
+			if loan.Status == LoanDefaulted {
+				log.Printf("Loan %d for user %d defaulted owing %d", loan.ID, loan.UserID, loan.Balance)
+			}
+		default:
+			return err
+		}
+	}
+	return tx.Commit()
+}
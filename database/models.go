@@ -2,6 +2,8 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"time"
 )
 
@@ -55,6 +57,40 @@ type Fighter struct {
 	HybridRogueLabInventoryID int        `db:"hybrid_rogue_lab_inventory_id"`
 }
 
+// FighterMutation is one permanent change in a fighter's mutation history
+type FighterMutation struct {
+	ID              int       `db:"id"`
+	FighterID       int       `db:"fighter_id"`
+	FightID         int       `db:"fight_id"`
+	MutationType    string    `db:"mutation_type"`
+	MutationName    string    `db:"mutation_name"`
+	Description     string    `db:"description"`
+	StatChangesJSON string    `db:"stat_changes_json"`
+	MutationTrigger string    `db:"mutation_trigger"`
+	CreatedAt       time.Time `db:"created_at"`
+}
+
+// StatChanges decodes what the mutation did to the fighter
+func (m FighterMutation) StatChanges() MutationStatChanges {
+	var changes MutationStatChanges
+	_ = json.Unmarshal([]byte(m.StatChangesJSON), &changes)
+	return changes
+}
+
+// FighterInjury is the damage and fatigue a fighter carried out of one fight.
+// Both start at 0-100 and wear off over the following days.
+type FighterInjury struct {
+	ID          int       `db:"id"`
+	FighterID   int       `db:"fighter_id"`
+	FightID     int       `db:"fight_id"`
+	Severity    int       `db:"severity"`
+	Fatigue     int       `db:"fatigue"`
+	FinalHealth int       `db:"final_health"`
+	CritsTaken  int       `db:"crits_taken"`
+	Frenzies    int       `db:"frenzies"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
 type FighterKill struct {
 	ID              int       `db:"id"`
 	KillerFighterID int       `db:"killer_fighter_id"`
@@ -116,29 +152,152 @@ type ChampionTitleCount struct {
 }
 
 type Tournament struct {
-	ID         int       `db:"id"`
-	WeekNumber int       `db:"week_number"`
-	Name       string    `db:"name"`
-	Sponsor    string    `db:"sponsor"`
-	StartDate  time.Time `db:"start_date"`
-	CreatedAt  time.Time `db:"created_at"`
+	ID             int       `db:"id"`
+	WeekNumber     int       `db:"week_number"`
+	Name           string    `db:"name"`
+	Sponsor        string    `db:"sponsor"`
+	StartDate      time.Time `db:"start_date"`
+	CreatedAt      time.Time `db:"created_at"`
+	Ruleset        string    `db:"ruleset"`         // combat ruleset for the week's fights ("" = default)
+	SettlementMode string    `db:"settlement_mode"` // bet settlement for the week's fights ("" = fixed odds)
 }
 
 type Fight struct {
-	ID            int            `db:"id"`
-	TournamentID  int            `db:"tournament_id"`
-	Fighter1ID    int            `db:"fighter1_id"`
-	Fighter2ID    int            `db:"fighter2_id"`
-	Fighter1Name  string         `db:"fighter1_name"`
-	Fighter2Name  string         `db:"fighter2_name"`
-	ScheduledTime time.Time      `db:"scheduled_time"`
-	Status        string         `db:"status"`
-	WinnerID      sql.NullInt64  `db:"winner_id"`
-	FinalScore1   sql.NullInt64  `db:"final_score1"`
-	FinalScore2   sql.NullInt64  `db:"final_score2"`
-	CompletedAt   sql.NullTime   `db:"completed_at"`
-	VoidedReason  sql.NullString `db:"voided_reason"`
-	CreatedAt     time.Time      `db:"created_at"`
+	ID             int            `db:"id"`
+	TournamentID   int            `db:"tournament_id"`
+	Fighter1ID     int            `db:"fighter1_id"`
+	Fighter2ID     int            `db:"fighter2_id"`
+	Fighter1Name   string         `db:"fighter1_name"`
+	Fighter2Name   string         `db:"fighter2_name"`
+	ScheduledTime  time.Time      `db:"scheduled_time"`
+	Status         string         `db:"status"`
+	WinnerID       sql.NullInt64  `db:"winner_id"`
+	FinalScore1    sql.NullInt64  `db:"final_score1"`
+	FinalScore2    sql.NullInt64  `db:"final_score2"`
+	CompletedAt    sql.NullTime   `db:"completed_at"`
+	VoidedReason   sql.NullString `db:"voided_reason"`
+	CreatedAt      time.Time      `db:"created_at"`
+	Ruleset        string         `db:"ruleset"`         // overrides the tournament ruleset when set
+	AlgoVersion    string         `db:"algo_version"`    // engine algorithm version the fight ran under ("" until it starts)
+	SettlementMode string         `db:"settlement_mode"` // overrides the tournament settlement mode when set
+	Format         string         `db:"format"`          // "singles" or "tag_team"
+	Partner1ID     int            `db:"partner1_id"`     // tag-team partner of fighter 1 (0 in singles)
+	Partner2ID     int            `db:"partner2_id"`
+	Partner1Name   string         `db:"partner1_name"`
+	Partner2Name   string         `db:"partner2_name"`
+	TagHealth      string         `db:"tag_health"` // "individual" or "shared" for tag-team fights
+	Grudge         bool           `db:"grudge"`     // booked between hot rivals
+}
+
+// BattleRoyale is a free-for-all event between 8 to 16 fighters. The last one
+// standing wins; everyone else is placed in the order they went out.
+type BattleRoyale struct {
+	ID            int           `db:"id" json:"id"`
+	Name          string        `db:"name" json:"name"`
+	ScheduledTime time.Time     `db:"scheduled_time" json:"scheduled_time"`
+	Status        string        `db:"status" json:"status"`
+	WinnerID      sql.NullInt64 `db:"winner_id" json:"-"`
+	Ruleset       string        `db:"ruleset" json:"ruleset"`
+	AlgoVersion   string        `db:"algo_version" json:"algo_version"`
+	TickSalt      int64         `db:"tick_salt" json:"-"` // secret added to every tick seed; published once the royale is over
+	CreatedAt     time.Time     `db:"created_at" json:"created_at"`
+	CompletedAt   sql.NullTime  `db:"completed_at" json:"-"`
+}
+
+// RoyaleEntrant is one fighter's place in a battle royale. Placement is 0
+// while they are still in it.
+type RoyaleEntrant struct {
+	ID              int    `db:"id" json:"-"`
+	RoyaleID        int    `db:"royale_id" json:"royale_id"`
+	FighterID       int    `db:"fighter_id" json:"fighter_id"`
+	FighterName     string `db:"fighter_name" json:"fighter_name"`
+	Slot            int    `db:"slot" json:"slot"`
+	Placement       int    `db:"placement" json:"placement"`
+	EliminatedBy    int    `db:"eliminated_by" json:"eliminated_by,omitempty"`
+	EliminatedRound int    `db:"eliminated_round" json:"eliminated_round,omitempty"`
+	EliminatedTick  int    `db:"eliminated_tick" json:"eliminated_tick,omitempty"`
+	FinalHealth     int    `db:"final_health" json:"final_health"`
+}
+
+// RoyaleBet backs a fighter to win a battle royale or to place in its top 3,
+// at decimal odds fixed when the bet was placed
+type RoyaleBet struct {
+	ID         int          `db:"id"`
+	UserID     int          `db:"user_id"`
+	RoyaleID   int          `db:"royale_id"`
+	FighterID  int          `db:"fighter_id"`
+	BetType    string       `db:"bet_type"`
+	Amount     int          `db:"amount"`
+	Odds       float64      `db:"odds"`
+	Status     string       `db:"status"`
+	Payout     int          `db:"payout"`
+	CreatedAt  time.Time    `db:"created_at"`
+	ResolvedAt sql.NullTime `db:"resolved_at"`
+}
+
+// FightInput is one external, non-seeded input to a fight simulation: the
+// effect-modified fighter snapshot taken at the opening bell (tick 0), or the
+// crowd's clap healing delivered to a lane on a given tick.
+type FightInput struct {
+	ID          int       `db:"id" json:"id"`
+	FightID     int       `db:"fight_id" json:"fight_id"`
+	Tick        int       `db:"tick" json:"tick"`
+	InputType   string    `db:"input_type" json:"input_type"` // "fighter_snapshot", "clap_heal" or "tick_salt"
+	Lane        int       `db:"lane" json:"lane"`             // 1 or 2 (0 for the tick salt)
+	FighterID   int       `db:"fighter_id" json:"fighter_id"`
+	Value       int       `db:"value" json:"value"` // starting health for snapshots, heal amount for claps, the salt itself
+	PayloadJSON string    `db:"payload_json" json:"payload_json"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+}
+
+// FightEvent is one structured entry in a fight's tick-by-tick history.
+// PayloadJSON holds the full LiveAction so replays can re-render it verbatim;
+// frenzy rows hold the multiplier and the stat that was zeroed instead.
+type FightEvent struct {
+	ID          int       `db:"id" json:"id"`
+	FightID     int       `db:"fight_id" json:"fight_id"`
+	Tick        int       `db:"tick" json:"tick"`
+	Seq         int       `db:"seq" json:"seq"` // order within the tick
+	Round       int       `db:"round" json:"round"`
+	EventType   string    `db:"event_type" json:"event_type"` // "damage", "critical", "frenzy", "round", "clap_summary", "death"
+	Attacker    string    `db:"attacker" json:"attacker"`
+	Victim      string    `db:"victim" json:"victim"`
+	Damage      int       `db:"damage" json:"damage"`
+	Health1     int       `db:"health1" json:"health1"`
+	Health2     int       `db:"health2" json:"health2"`
+	PayloadJSON string    `db:"payload_json" json:"-"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+}
+
+// FightOdds is the Monte Carlo price for a fight: the share of seeded
+// simulations each outcome came up in. Death overlaps the win columns.
+type FightOdds struct {
+	FightID     int       `db:"fight_id" json:"fight_id"`
+	Fighter1Win float64   `db:"fighter1_win" json:"fighter1_win"`
+	Fighter2Win float64   `db:"fighter2_win" json:"fighter2_win"`
+	Draw        float64   `db:"draw" json:"draw"`
+	Death       float64   `db:"death" json:"death"`
+	Simulations int       `db:"simulations" json:"simulations"`
+	Ruleset     string    `db:"ruleset" json:"ruleset"`
+	AlgoVersion string    `db:"algo_version" json:"algo_version"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Percent formats a probability for display, e.g. 0.4231 -> "42.3%"
+func (o FightOdds) Percent(p float64) string {
+	return fmt.Sprintf("%.1f%%", p*100)
+}
+
+// FightCheckpoint is the last saved state of a live fight, so a restart can
+// pick the fight up where it left off instead of replaying it from tick 1
+type FightCheckpoint struct {
+	FightID     int       `db:"fight_id"`
+	Tick        int       `db:"tick"`
+	Round       int       `db:"round"`
+	Ruleset     string    `db:"ruleset"`
+	AlgoVersion string    `db:"algo_version"`
+	StateJSON   string    `db:"state_json"`
+	UpdatedAt   time.Time `db:"updated_at"`
 }
 
 type Bet struct {
@@ -151,6 +310,9 @@ type Bet struct {
 	Payout     sql.NullInt64 `db:"payout"`
 	CreatedAt  time.Time     `db:"created_at"`
 	ResolvedAt sql.NullTime  `db:"resolved_at"`
+	Odds       float64       `db:"odds"`        // decimal odds locked in for in-play bets (0 = flat pre-fight rate)
+	PlacedTick int           `db:"placed_tick"` // fight tick an in-play bet was accepted on
+	VoidReason string        `db:"void_reason"` // why the fight was voided, for bets settled by a void
 }
 
 type BetWithUser struct {
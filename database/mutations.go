@@ -0,0 +1,156 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Mutation triggers
+const (
+	MutationTriggerPostFight = "post_fight"
+)
+
+// MutationStatChanges is what a mutation does to a fighter. Numbers are added
+// to the fighter's current values; strings replace them when set.
+type MutationStatChanges struct {
+	Strength         int     `json:"strength,omitempty"`
+	Speed            int     `json:"speed,omitempty"`
+	Endurance        int     `json:"endurance,omitempty"`
+	Technique        int     `json:"technique,omitempty"`
+	ExistentialDread int     `json:"existential_dread,omitempty"`
+	MolecularDensity float64 `json:"molecular_density,omitempty"`
+	Fingers          int     `json:"fingers,omitempty"`
+	Toes             int     `json:"toes,omitempty"`
+	Ancestors        int     `json:"ancestors,omitempty"`
+	BloodType        string  `json:"blood_type,omitempty"`
+	Horoscope        string  `json:"horoscope,omitempty"`
+}
+
+// Apply permanently applies the changes to f. Digits can't go below zero;
+// everything else is allowed to run wherever the mutation takes it.
+func (c MutationStatChanges) Apply(f *Fighter) {
+	f.Strength += c.Strength
+	f.Speed += c.Speed
+	f.Endurance += c.Endurance
+	f.Technique += c.Technique
+	f.ExistentialDread += c.ExistentialDread
+	f.MolecularDensity += c.MolecularDensity
+	f.Fingers = max(0, f.Fingers+c.Fingers)
+	f.Toes = max(0, f.Toes+c.Toes)
+	f.Ancestors = max(0, f.Ancestors+c.Ancestors)
+	if c.BloodType != "" {
+		f.BloodType = c.BloodType
+	}
+	if c.Horoscope != "" {
+		f.Horoscope = c.Horoscope
+	}
+}
+
+// Summary describes the changes for display, e.g. "+15 STR, -5 SPD, blood: Pure Mathematics"
+func (c MutationStatChanges) Summary() string {
+	var parts []string
+	signed := func(n int, label string) {
+		if n != 0 {
+			parts = append(parts, fmt.Sprintf("%+d %s", n, label))
+		}
+	}
+	signed(c.Strength, "STR")
+	signed(c.Speed, "SPD")
+	signed(c.Endurance, "END")
+	signed(c.Technique, "TEC")
+	signed(c.ExistentialDread, "dread")
+	if c.MolecularDensity != 0 {
+		parts = append(parts, fmt.Sprintf("%+.2f density", c.MolecularDensity))
+	}
+	signed(c.Fingers, "fingers")
+	signed(c.Toes, "toes")
+	signed(c.Ancestors, "ancestors")
+	if c.BloodType != "" {
+		parts = append(parts, "blood: "+c.BloodType)
+	}
+	if c.Horoscope != "" {
+		parts = append(parts, "sign: "+c.Horoscope)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (r *Repository) ensureFighterMutationsTable() error {
+	exists, err := r.tableExists("fighter_mutations")
+	if err != nil || exists {
+		return err
+	}
+
+	_, err = r.db.Exec(`
+        CREATE TABLE fighter_mutations (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            fighter_id INTEGER NOT NULL,
+            fight_id INTEGER NOT NULL DEFAULT 0,
+            mutation_type TEXT NOT NULL,
+            mutation_name TEXT NOT NULL,
+            description TEXT NOT NULL,
+            stat_changes_json TEXT NOT NULL DEFAULT '{}',
+            mutation_trigger TEXT NOT NULL,
+            created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            FOREIGN KEY (fighter_id) REFERENCES fighters(id)
+        );
+    `)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(`CREATE INDEX idx_fighter_mutations_fighter ON fighter_mutations(fighter_id)`)
+	return err
+}
+
+// MutateFighter applies a mutation to a fighter and records it in their
+// history, in one transaction. The fighter's genome is recomputed from the
+// mutated stats. Returns the mutated fighter.
+func (r *Repository) MutateFighter(fighterID, fightID int, mutationType, name, description, trigger string, changes MutationStatChanges) (*Fighter, error) {
+	payload, err := json.Marshal(changes)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var fighter Fighter
+	if err := tx.Get(&fighter, `SELECT * FROM fighters WHERE id = ?`, fighterID); err != nil {
+		return nil, fmt.Errorf("failed to get fighter %d: %w", fighterID, err)
+	}
+	ensureFighterDefaults(&fighter)
+	changes.Apply(&fighter)
+	fighter.Genome = fighter.DeriveGenome()
+
+	_, err = tx.Exec(`
+        UPDATE fighters SET strength = ?, speed = ?, endurance = ?, technique = ?,
+            existential_dread = ?, molecular_density = ?, fingers = ?, toes = ?, ancestors = ?,
+            blood_type = ?, horoscope = ?, genome = ?
+        WHERE id = ?`,
+		fighter.Strength, fighter.Speed, fighter.Endurance, fighter.Technique,
+		fighter.ExistentialDread, fighter.MolecularDensity, fighter.Fingers, fighter.Toes, fighter.Ancestors,
+		fighter.BloodType, fighter.Horoscope, fighter.Genome, fighter.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+        INSERT INTO fighter_mutations (fighter_id, fight_id, mutation_type, mutation_name, description, stat_changes_json, mutation_trigger)
+        VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		fighterID, fightID, mutationType, name, description, string(payload), trigger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fighter, tx.Commit()
+}
+
+// GetFighterMutations returns a fighter's mutation history, newest first
+func (r *Repository) GetFighterMutations(fighterID int) ([]FighterMutation, error) {
+	var mutations []FighterMutation
+	err := r.db.Select(&mutations, `SELECT * FROM fighter_mutations WHERE fighter_id = ? ORDER BY created_at DESC, id DESC`, fighterID)
+	return mutations, err
+}
@@ -0,0 +1,364 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Prop markets. Each is settled from how the fight ended rather than who won.
+const (
+	PropMarketMethod = "method" // how the fight ended
+	PropMarketRound  = "round"  // which bracket of rounds the finish came in
+	PropMarketDamage = "damage" // total damage dealt by both sides, over or under the line
+	PropMarketDeath  = "death"  // somebody dies
+	PropMarketFrenzy = "frenzy" // an undead fighter frenzies at least once
+)
+
+// Prop selections
+const (
+	PropMethodKO       = "ko"
+	PropMethodDeath    = "death"
+	PropMethodDecision = "decision"
+	PropMethodDraw     = "draw"
+
+	PropRoundDistance = "distance" // went to the judges, no finish
+
+	PropOver  = "over"
+	PropUnder = "under"
+	PropYes   = "yes"
+	PropNo    = "no"
+)
+
+// PropRoundBracket is a span of rounds a finish can land in. Last is zero for
+// the open-ended final bracket.
+type PropRoundBracket struct {
+	Label string
+	First int
+	Last  int
+}
+
+// PropRoundBrackets split the finishes so each bracket sees a fair share of
+// fights; most fights end in their early twenties
+var PropRoundBrackets = []PropRoundBracket{
+	{"1-15", 1, 15},
+	{"16-20", 16, 20},
+	{"21-25", 21, 25},
+	{"26-30", 26, 30},
+	{"31+", 31, 0},
+}
+
+// PropOutcome is everything the prop markets settle on
+type PropOutcome struct {
+	Method      string `json:"method"`
+	Round       int    `json:"round"` // round of the finish, zero if the fight went the distance
+	TotalDamage int    `json:"total_damage"`
+	Death       bool   `json:"death"`
+	Frenzy      bool   `json:"frenzy"`
+}
+
+// RoundBracket names the round bracket the outcome falls in
+func (o PropOutcome) RoundBracket() string {
+	if o.Round <= 0 {
+		return PropRoundDistance
+	}
+	for _, b := range PropRoundBrackets {
+		if o.Round >= b.First && (b.Last == 0 || o.Round <= b.Last) {
+			return b.Label
+		}
+	}
+	return PropRoundDistance
+}
+
+// Wins reports whether a selection in market pays on this outcome. line only
+// matters to the damage market.
+func (o PropOutcome) Wins(market, selection string, line int) bool {
+	switch market {
+	case PropMarketMethod:
+		return o.Method == selection
+	case PropMarketRound:
+		return o.RoundBracket() == selection
+	case PropMarketDamage:
+		if selection == PropOver {
+			return o.TotalDamage > line
+		}
+		return selection == PropUnder && o.TotalDamage < line
+	case PropMarketDeath:
+		return (selection == PropYes) == o.Death
+	case PropMarketFrenzy:
+		return (selection == PropYes) == o.Frenzy
+	}
+	return false
+}
+
+// Pushes reports whether the outcome lands exactly on a market's line, in
+// which case neither side wins and every stake on the market comes back
+func (o PropOutcome) Pushes(market string, line int) bool {
+	return market == PropMarketDamage && o.TotalDamage == line
+}
+
+// PropPushReason is recorded on prop bets refunded because the line was hit exactly
+const PropPushReason = "push: landed on the line"
+
+// PropSelections lists every selection offered in a market
+func PropSelections(market string) []string {
+	switch market {
+	case PropMarketMethod:
+		return []string{PropMethodKO, PropMethodDeath, PropMethodDecision, PropMethodDraw}
+	case PropMarketRound:
+		labels := make([]string, 0, len(PropRoundBrackets)+1)
+		for _, b := range PropRoundBrackets {
+			labels = append(labels, b.Label)
+		}
+		return append(labels, PropRoundDistance)
+	case PropMarketDamage:
+		return []string{PropOver, PropUnder}
+	case PropMarketDeath, PropMarketFrenzy:
+		return []string{PropYes, PropNo}
+	}
+	return nil
+}
+
+// PropMarkets lists the markets in display order
+var PropMarkets = []string{PropMarketMethod, PropMarketRound, PropMarketDamage, PropMarketDeath, PropMarketFrenzy}
+
+// FightPropOdds is the price of one prop selection on a fight
+type FightPropOdds struct {
+	FightID     int       `db:"fight_id" json:"fight_id"`
+	Market      string    `db:"market" json:"market"`
+	Selection   string    `db:"selection" json:"selection"`
+	Line        int       `db:"line" json:"line,omitempty"` // damage market only
+	Probability float64   `db:"probability" json:"probability"`
+	Odds        float64   `db:"odds" json:"odds"` // decimal odds offered, zero if not offered
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// PropBet is a stake on a prop selection at decimal odds fixed when it was placed
+type PropBet struct {
+	ID         int          `db:"id" json:"id"`
+	UserID     int          `db:"user_id" json:"user_id"`
+	FightID    int          `db:"fight_id" json:"fight_id"`
+	Market     string       `db:"market" json:"market"`
+	Selection  string       `db:"selection" json:"selection"`
+	Line       int          `db:"line" json:"line,omitempty"`
+	Amount     int          `db:"amount" json:"amount"`
+	Odds       float64      `db:"odds" json:"odds"`
+	Status     string       `db:"status" json:"status"`
+	Payout     int          `db:"payout" json:"payout"`
+	VoidReason string       `db:"void_reason" json:"void_reason,omitempty"`
+	CreatedAt  time.Time    `db:"created_at" json:"created_at"`
+	ResolvedAt sql.NullTime `db:"resolved_at" json:"resolved_at"`
+}
+
+func (r *Repository) ensurePropTables() error {
+	exists, err := r.tableExists("fight_prop_odds")
+	if err != nil {
+		return err
+	}
+	if !exists {
+		_, err = r.db.Exec(`
+            CREATE TABLE fight_prop_odds (
+                fight_id INTEGER NOT NULL,
+                market TEXT NOT NULL,
+                selection TEXT NOT NULL,
+                line INTEGER NOT NULL DEFAULT 0,
+                probability REAL NOT NULL DEFAULT 0,
+                odds REAL NOT NULL DEFAULT 0,
+                updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                PRIMARY KEY (fight_id, market, selection),
+                FOREIGN KEY (fight_id) REFERENCES fights(id)
+            );
+        `)
+		if err != nil {
+			return err
+		}
+	}
+
+	exists, err = r.tableExists("prop_bets")
+	if err != nil {
+		return err
+	}
+	if !exists {
+		_, err = r.db.Exec(`
+            CREATE TABLE prop_bets (
+                id INTEGER PRIMARY KEY AUTOINCREMENT,
+                user_id INTEGER NOT NULL,
+                fight_id INTEGER NOT NULL,
+                market TEXT NOT NULL,
+                selection TEXT NOT NULL,
+                line INTEGER NOT NULL DEFAULT 0,
+                amount INTEGER NOT NULL,
+                odds REAL NOT NULL,
+                status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'won', 'lost', 'voided')),
+                payout INTEGER NOT NULL DEFAULT 0,
+                created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                resolved_at DATETIME,
+                FOREIGN KEY (user_id) REFERENCES users(id),
+                FOREIGN KEY (fight_id) REFERENCES fights(id)
+            );
+            CREATE INDEX idx_prop_bets_fight ON prop_bets(fight_id, status);
+        `)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveFightPropOdds replaces a fight's prop prices
+func (r *Repository) SaveFightPropOdds(fightID int, odds []FightPropOdds) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM fight_prop_odds WHERE fight_id = ?`, fightID); err != nil {
+		return err
+	}
+	for _, o := range odds {
+		if _, err := tx.Exec(`
+            INSERT INTO fight_prop_odds (fight_id, market, selection, line, probability, odds, updated_at)
+            VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			fightID, o.Market, o.Selection, o.Line, o.Probability, o.Odds, r.sqlNow()); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetFightPropOdds returns a fight's prop prices, grouped by market in display order
+func (r *Repository) GetFightPropOdds(fightID int) ([]FightPropOdds, error) {
+	odds := []FightPropOdds{}
+	err := r.db.Select(&odds, `SELECT * FROM fight_prop_odds WHERE fight_id = ? ORDER BY rowid`, fightID)
+	return odds, err
+}
+
+// CreatePropBet places a prop bet on a scheduled fight at the quoted decimal
+// odds and line, taking the stake in the same transaction
+func (r *Repository) CreatePropBet(userID, fightID int, market, selection string, line, amount int, odds float64) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var status string
+	if err := tx.QueryRow(`SELECT status FROM fights WHERE id = ?`, fightID).Scan(&status); err != nil {
+		return 0, err
+	}
+	if status != "scheduled" {
+		return 0, fmt.Errorf("betting is closed for this fight")
+	}
+
+	res, err := tx.Exec(`
+        INSERT INTO prop_bets (user_id, fight_id, market, selection, line, amount, odds, status, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, 'pending', ?)`,
+		userID, fightID, market, selection, line, amount, odds, r.sqlNow())
+	if err != nil {
+		return 0, err
+	}
+	betID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := r.postCredit(tx, CreditMove{
+		UserID: userID,
+		Amount: -amount,
+		Reason: ReasonBetStake,
+		Ref:    PropBetRef(int(betID)),
+		Memo:   fmt.Sprintf("Fight %d, %s %s", fightID, market, selection),
+	}); err != nil {
+		return 0, err
+	}
+	return int(betID), tx.Commit()
+}
+
+// GetUserPropBets returns a user's prop bets on a fight, newest first
+func (r *Repository) GetUserPropBets(userID, fightID int) ([]PropBet, error) {
+	bets := []PropBet{}
+	err := r.db.Select(&bets, `
+        SELECT * FROM prop_bets WHERE user_id = ? AND fight_id = ?
+        ORDER BY created_at DESC`, userID, fightID)
+	return bets, err
+}
+
+// ProcessPropBetsForFight settles every pending prop bet on a fight against
+// how it ended
+func (r *Repository) ProcessPropBetsForFight(fightID int, outcome PropOutcome) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	bets := []PropBet{}
+	if err := tx.Select(&bets, `SELECT * FROM prop_bets WHERE fight_id = ? AND status = 'pending'`, fightID); err != nil {
+		return err
+	}
+	for _, bet := range bets {
+		status, payout, reason, voidReason := "lost", 0, ReasonBetPayout, ""
+		switch {
+		case outcome.Pushes(bet.Market, bet.Line):
+			status, payout, reason, voidReason = "voided", bet.Amount, ReasonBetRefund, PropPushReason
+		case outcome.Wins(bet.Market, bet.Selection, bet.Line):
+			status, payout = "won", int(float64(bet.Amount)*bet.Odds)
+		}
+		if _, err := tx.Exec(`
+            UPDATE prop_bets SET status = ?, payout = ?, void_reason = ?, resolved_at = ?
+            WHERE id = ?`, status, payout, voidReason, r.sqlNow(), bet.ID); err != nil {
+			return err
+		}
+		if payout > 0 {
+			if _, err := r.postCredit(tx, CreditMove{
+				UserID: bet.UserID,
+				Amount: payout,
+				Reason: reason,
+				Ref:    PropBetRef(bet.ID),
+				Memo:   fmt.Sprintf("Fight %d, %s %s", fightID, bet.Market, bet.Selection),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// PropSelectionLabel names a prop selection for display
+func PropSelectionLabel(market, selection string, line int) string {
+	switch market {
+	case PropMarketMethod:
+		switch selection {
+		case PropMethodKO:
+			return "Ends by KO"
+		case PropMethodDeath:
+			return "Ends in death"
+		case PropMethodDecision:
+			return "Goes to the judges"
+		case PropMethodDraw:
+			return "Draw"
+		}
+	case PropMarketRound:
+		if selection == PropRoundDistance {
+			return "No finish"
+		}
+		return "Finish in rounds " + selection
+	case PropMarketDamage:
+		if selection == PropOver {
+			return "Over " + strconv.Itoa(line) + " damage"
+		}
+		return "Under " + strconv.Itoa(line) + " damage"
+	case PropMarketDeath:
+		if selection == PropYes {
+			return "A death occurs"
+		}
+		return "Everyone survives"
+	case PropMarketFrenzy:
+		if selection == PropYes {
+			return "Undead frenzy triggers"
+		}
+		return "No undead frenzy"
+	}
+	return market + " " + selection
+}
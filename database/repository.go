@@ -13,20 +13,108 @@ import (
 )
 
 type Repository struct {
-	db *sqlx.DB
+	db    *sqlx.DB
+	clock utils.Clock
 }
 
 func NewRepository(db *sqlx.DB) *Repository {
-	repo := &Repository{db: db}
+	repo := &Repository{db: db, clock: utils.DefaultClock()}
 	if err := repo.runLineageMigrations(); err != nil {
 		log.Printf("lineage migration warning: %v", err)
 	}
 	if err := repo.ensureHybridShopItems(); err != nil {
 		log.Printf("hybrid shop ensure warning: %v", err)
 	}
+	if err := repo.ensureRulesetColumns(); err != nil {
+		log.Printf("ruleset migration warning: %v", err)
+	}
+	if err := repo.ensureFightInputsTable(); err != nil {
+		log.Printf("fight inputs migration warning: %v", err)
+	}
+	if err := repo.restampLegacyFights(); err != nil {
+		log.Printf("legacy fights migration warning: %v", err)
+	}
+	if err := repo.ensureFightEventsTable(); err != nil {
+		log.Printf("fight events migration warning: %v", err)
+	}
+	if err := repo.ensureFightOddsTable(); err != nil {
+		log.Printf("fight odds migration warning: %v", err)
+	}
+	if err := repo.ensureSettlementModeColumns(); err != nil {
+		log.Printf("settlement mode migration warning: %v", err)
+	}
+	if err := repo.ensureBetPricingColumns(); err != nil {
+		log.Printf("bet pricing migration warning: %v", err)
+	}
+	if err := repo.ensureFightCheckpointsTable(); err != nil {
+		log.Printf("fight checkpoints migration warning: %v", err)
+	}
+	if err := repo.ensureFighterMutationsTable(); err != nil {
+		log.Printf("fighter mutations migration warning: %v", err)
+	}
+	if err := repo.ensureFighterInjuriesTable(); err != nil {
+		log.Printf("fighter injuries migration warning: %v", err)
+	}
+	if err := repo.ensureTagTeamColumns(); err != nil {
+		log.Printf("tag team migration warning: %v", err)
+	}
+	if err := repo.ensureBattleRoyaleTables(); err != nil {
+		log.Printf("battle royale migration warning: %v", err)
+	}
+	if err := repo.ensureGrudgeColumn(); err != nil {
+		log.Printf("grudge match migration warning: %v", err)
+	}
+	if err := repo.ensureCreditTransactionsTable(); err != nil {
+		log.Printf("credit ledger migration warning: %v", err)
+	}
+	if err := repo.ensurePropTables(); err != nil {
+		log.Printf("prop bets migration warning: %v", err)
+	}
+	if err := repo.ensureBetSlipTables(); err != nil {
+		log.Printf("bet slips migration warning: %v", err)
+	}
+	if err := repo.ensureBetExitStatuses(); err != nil {
+		log.Printf("bet exit statuses migration warning: %v", err)
+	}
+	if err := repo.ensureVoidReasonColumns(); err != nil {
+		log.Printf("void reason migration warning: %v", err)
+	}
+	if err := repo.ensureLoanTables(); err != nil {
+		log.Printf("loans migration warning: %v", err)
+	}
+	if err := repo.ensureChallengesTable(); err != nil {
+		log.Printf("challenges migration warning: %v", err)
+	}
 	return repo
 }
 
+// NewSnapshotRepository wraps a database without running any migrations, for
+// tools that only read from a copy of the league
+func NewSnapshotRepository(db *sqlx.DB) *Repository {
+	return &Repository{db: db, clock: utils.DefaultClock()}
+}
+
+// SetClock sets the league clock. Everything built on this repository reads
+// the time from it, so set it before the scheduler and web server start.
+func (r *Repository) SetClock(clock utils.Clock) {
+	r.clock = clock
+}
+
+// Clock returns the league clock
+func (r *Repository) Clock() utils.Clock {
+	return r.clock
+}
+
+// sqlNow is the current league time in the UTC format datetime('now') writes
+func (r *Repository) sqlNow() string {
+	return sqlTime(r.clock.Now())
+}
+
+// sqlTime formats t the way sqlNow does, for comparing against stored timestamps
+func sqlTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
+
 // ensureFighterDefaults applies default values to fighter fields if not set
 func ensureFighterDefaults(f *Fighter) {
 	if f.AvatarURL == "" {
@@ -444,10 +532,18 @@ func (r *Repository) FightExistsAt(tournamentID int, at time.Time) (bool, error)
 }
 
 func (r *Repository) InsertFight(fight Fight) error {
+	if fight.Format == "" {
+		fight.Format = FightFormatSingles
+	}
 	_, err := r.db.NamedExec(`
-		INSERT INTO fights (tournament_id, fighter1_id, fighter2_id, fighter1_name, fighter2_name, scheduled_time, status, created_at)
-		VALUES (:tournament_id, :fighter1_id, :fighter2_id, :fighter1_name, :fighter2_name, :scheduled_time, :status, datetime('now'))
-	`, fight)
+		INSERT INTO fights (tournament_id, fighter1_id, fighter2_id, fighter1_name, fighter2_name, scheduled_time, status, ruleset, settlement_mode,
+			format, partner1_id, partner2_id, partner1_name, partner2_name, tag_health, grudge, created_at)
+		VALUES (:tournament_id, :fighter1_id, :fighter2_id, :fighter1_name, :fighter2_name, :scheduled_time, :status, :ruleset, :settlement_mode,
+			:format, :partner1_id, :partner2_id, :partner1_name, :partner2_name, :tag_health, :grudge, :created_at)
+	`, struct {
+		Fight
+		CreatedAt string `db:"created_at"`
+	}{fight, r.sqlNow()})
 	return err
 }
 
@@ -474,8 +570,8 @@ func (r *Repository) GetExpiredScheduledFights(tournamentID int, now time.Time)
 func (r *Repository) VoidFight(fightID int, reason string) error {
 	_, err := r.db.Exec(`
 		UPDATE fights 
-		SET status = 'voided', voided_reason = ?, completed_at = datetime('now')
-		WHERE id = ?`, reason, fightID)
+		SET status = 'voided', voided_reason = ?, completed_at = ?
+		WHERE id = ?`, reason, r.sqlNow(), fightID)
 	return err
 }
 
@@ -608,10 +704,10 @@ func (r *Repository) GetPastFightsForFighter(fighterID int, limit int) ([]Fight,
 	err := r.db.Select(&fights, `
         SELECT * FROM fights
         WHERE status = 'completed'
-          AND (fighter1_id = ? OR fighter2_id = ?)
+          AND (fighter1_id = ? OR fighter2_id = ? OR partner1_id = ? OR partner2_id = ?)
         ORDER BY completed_at DESC
         LIMIT ?
-    `, fighterID, fighterID, limit)
+    `, fighterID, fighterID, fighterID, fighterID, limit)
 	return fights, err
 }
 
@@ -637,9 +733,9 @@ func (r *Repository) UpdateFightResult(fightID int, winnerID interface{}, score1
 			winner_id = ?, 
 			final_score1 = ?, 
 			final_score2 = ?, 
-			completed_at = datetime('now')
+			completed_at = ?
 		WHERE id = ?`,
-		winnerID, score1, score2, fightID)
+		winnerID, score1, score2, r.sqlNow(), fightID)
 	return err
 }
 
@@ -674,9 +770,15 @@ func (r *Repository) CleanExpiredSessions() error {
 
 // User management methods
 func (r *Repository) CreateUser(discordID, username, avatarURL string) (*User, error) {
-	result, err := r.db.Exec(`
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
 		INSERT INTO users (discord_id, username, avatar_url, custom_username, credits) 
-		VALUES (?, ?, ?, ?, 1000000)`,
+		VALUES (?, ?, ?, ?, 0)`,
 		discordID, username, avatarURL, username)
 	if err != nil {
 		return nil, err
@@ -687,6 +789,13 @@ func (r *Repository) CreateUser(discordID, username, avatarURL string) (*User, e
 		return nil, err
 	}
 
+	if _, err := r.postCredit(tx, CreditMove{UserID: int(userID), Amount: 1000000, Reason: ReasonSignupGrant}); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
 	var user User
 	err = r.db.Get(&user, "SELECT * FROM users WHERE id = ?", userID)
 	return &user, err
@@ -711,8 +820,8 @@ func (r *Repository) GetUserByUsername(username string) (*User, error) {
 }
 
 func (r *Repository) UpdateUserCustomUsername(userID int, customUsername string) error {
-	_, err := r.db.Exec("UPDATE users SET custom_username = ?, updated_at = datetime('now') WHERE id = ?",
-		customUsername, userID)
+	_, err := r.db.Exec("UPDATE users SET custom_username = ?, updated_at = ? WHERE id = ?",
+		customUsername, r.sqlNow(), userID)
 	return err
 }
 
@@ -732,17 +841,43 @@ func (r *Repository) GetAllFightersByRecord() ([]Fighter, error) {
 }
 
 // Betting methods
+// CreateBet places a pre-fight bet, taking the stake in the same transaction
 func (r *Repository) CreateBet(userID, fightID, fighterID, amount int) error {
-	_, err := r.db.Exec(`
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
 		INSERT INTO bets (user_id, fight_id, fighter_id, amount, status, created_at) 
-		VALUES (?, ?, ?, ?, 'pending', datetime('now'))`,
-		userID, fightID, fighterID, amount)
-	return err
+		VALUES (?, ?, ?, ?, 'pending', ?)`,
+		userID, fightID, fighterID, amount, r.sqlNow())
+	if err != nil {
+		return err
+	}
+	betID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.postCredit(tx, CreditMove{
+		UserID: userID,
+		Amount: -amount,
+		Reason: ReasonBetStake,
+		Ref:    BetRef(int(betID)),
+		Memo:   fmt.Sprintf("Fight %d", fightID),
+	}); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
+// GetUserBetOnFight returns the user's bet on a fight. Cancelled bets don't
+// count, so the user is free to bet again.
 func (r *Repository) GetUserBetOnFight(userID, fightID int) (*Bet, error) {
 	var bet Bet
-	err := r.db.Get(&bet, "SELECT * FROM bets WHERE user_id = ? AND fight_id = ?", userID, fightID)
+	err := r.db.Get(&bet, "SELECT * FROM bets WHERE user_id = ? AND fight_id = ? AND status != 'cancelled'", userID, fightID)
 	return &bet, err
 }
 
@@ -753,18 +888,12 @@ func (r *Repository) GetAllBetsOnFight(fightID int) ([]BetWithUser, error) {
 		FROM bets b 
 		JOIN users u ON b.user_id = u.id 
 		JOIN fighters f ON b.fighter_id = f.id
-		WHERE b.fight_id = ? 
+		WHERE b.fight_id = ? AND b.status != 'cancelled'
 		ORDER BY b.created_at ASC`,
 		fightID)
 	return bets, err
 }
 
-func (r *Repository) UpdateUserCredits(userID, credits int) error {
-	_, err := r.db.Exec("UPDATE users SET credits = ?, updated_at = datetime('now') WHERE id = ?",
-		credits, userID)
-	return err
-}
-
 func (r *Repository) ProcessBetsForFight(fightID int, winnerID *int) error {
 	// Get all bets for this fight
 	var bets []Bet
@@ -773,6 +902,17 @@ func (r *Repository) ProcessBetsForFight(fightID int, winnerID *int) error {
 		return err
 	}
 
+	mode, err := r.SettlementModeForFight(fightID)
+	if err != nil {
+		return err
+	}
+
+	// Parimutuel pools are settled as a whole; fixed-odds bets one by one below
+	var pooled map[int]betOutcome
+	if mode == SettlementParimutuel {
+		pooled = parimutuelOutcomes(bets, winnerID, ParimutuelRakePercent())
+	}
+
 	tx, err := r.db.Begin()
 	if err != nil {
 		return err
@@ -783,10 +923,16 @@ func (r *Repository) ProcessBetsForFight(fightID int, winnerID *int) error {
 		var newStatus string
 		var payout int
 
-		if winnerID == nil {
+		if pooled != nil {
+			newStatus, payout = pooled[bet.ID].status, pooled[bet.ID].payout
+		} else if winnerID == nil {
 			// Draw - return original bet
 			newStatus = "voided"
 			payout = bet.Amount
+		} else if bet.FighterID == *winnerID && bet.Odds > 0 {
+			// Won an in-play bet - pays the odds locked in when it was placed
+			newStatus = "won"
+			payout = int(float64(bet.Amount) * bet.Odds)
 		} else if bet.FighterID == *winnerID {
 			// Won - default 2x payout; 10x if this is user's MVP fighter and they own MVP item
 			newStatus = "won"
@@ -820,20 +966,26 @@ func (r *Repository) ProcessBetsForFight(fightID int, winnerID *int) error {
 		// Update bet status
 		_, err = tx.Exec(`
 			UPDATE bets 
-			SET status = ?, payout = ?, resolved_at = datetime('now') 
+			SET status = ?, payout = ?, resolved_at = ? 
 			WHERE id = ?`,
-			newStatus, payout, bet.ID)
+			newStatus, payout, r.sqlNow(), bet.ID)
 		if err != nil {
 			return err
 		}
 
 		// Update user credits if there's a payout
 		if payout > 0 {
-			_, err = tx.Exec(`
-				UPDATE users 
-				SET credits = credits + ?, updated_at = datetime('now') 
-				WHERE id = ?`,
-				payout, bet.UserID)
+			reason := ReasonBetPayout
+			if newStatus == "voided" {
+				reason = ReasonBetRefund
+			}
+			_, err = r.postCredit(tx, CreditMove{
+				UserID: bet.UserID,
+				Amount: payout,
+				Reason: reason,
+				Ref:    BetRef(bet.ID),
+				Memo:   fmt.Sprintf("Fight %d", fightID),
+			})
 			if err != nil {
 				return err
 			}
@@ -907,9 +1059,9 @@ func (r *Repository) ConsolidateUserInventory() error {
 func (r *Repository) UpdateUser(user *User) error {
 	_, err := r.db.Exec(`
 		UPDATE users 
-		SET username = ?, avatar_url = ?, updated_at = datetime('now') 
+		SET username = ?, avatar_url = ?, updated_at = ? 
 		WHERE id = ?`,
-		user.Username, user.AvatarURL, user.ID)
+		user.Username, user.AvatarURL, r.sqlNow(), user.ID)
 	return err
 }
 
@@ -917,7 +1069,7 @@ func (r *Repository) GetUserBets(userID int) ([]BetWithFight, error) {
 	var bets []BetWithFight
 	err := r.db.Select(&bets, `
 		SELECT b.id, b.user_id, b.fight_id, b.fighter_id, b.amount, b.status, b.payout, 
-		       b.created_at, b.resolved_at,
+		       b.created_at, b.resolved_at, b.odds, b.placed_tick, b.void_reason,
 		       f.fighter1_name, f.fighter2_name, f.scheduled_time, f.status as fight_status,
 		       fighter.name as fighter_name
 		FROM bets b 
@@ -941,8 +1093,12 @@ func (r *Repository) GetUserBettingStats(userID int) (*BettingStats, error) {
 			COUNT(CASE WHEN status = 'lost' THEN 1 END) as bets_lost,
 			COUNT(CASE WHEN status = 'voided' THEN 1 END) as bets_voided,
 			COUNT(CASE WHEN status = 'pending' THEN 1 END) as active_bets,
-			COALESCE(SUM(CASE WHEN status = 'won' THEN payout - amount ELSE 0 END), 0) as total_winnings,
-			COALESCE(SUM(CASE WHEN status = 'lost' THEN amount ELSE 0 END), 0) as total_losses,
+			COALESCE(SUM(CASE WHEN status = 'won' THEN payout - amount
+			                  WHEN status = 'cashed_out' AND payout > amount THEN payout - amount
+			                  ELSE 0 END), 0) as total_winnings,
+			COALESCE(SUM(CASE WHEN status = 'lost' THEN amount
+			                  WHEN status IN ('cancelled', 'cashed_out') AND payout < amount THEN amount - payout
+			                  ELSE 0 END), 0) as total_losses,
 			COALESCE(AVG(amount), 0) as avg_bet_size,
 			COALESCE(MAX(CASE WHEN status = 'won' THEN payout - amount END), 0) as biggest_win,
 			COALESCE(MAX(CASE WHEN status = 'lost' THEN amount END), 0) as biggest_loss
@@ -997,11 +1153,13 @@ func (r *Repository) PurchaseItem(userID, itemID, quantity int, totalCost int) e
 	defer tx.Rollback()
 
 	// Deduct credits from user
-	_, err = tx.Exec(`
-		UPDATE users 
-		SET credits = credits - ?, updated_at = datetime('now') 
-		WHERE id = ? AND credits >= ?`,
-		totalCost, userID, totalCost)
+	_, err = r.postCredit(tx, CreditMove{
+		UserID: userID,
+		Amount: -totalCost,
+		Reason: ReasonShopPurchase,
+		Ref:    ShopItemRef(itemID),
+		Memo:   fmt.Sprintf("x%d", quantity),
+	})
 	if err != nil {
 		return err
 	}
@@ -1046,8 +1204,8 @@ func (r *Repository) PurchaseItem(userID, itemID, quantity int, totalCost int) e
 		// Insert new inventory item
 		_, err = tx.Exec(`
             INSERT INTO user_inventory (user_id, shop_item_id, quantity, created_at) 
-            VALUES (?, ?, ?, datetime('now'))`,
-			userID, itemID, quantity)
+            VALUES (?, ?, ?, ?)`,
+			userID, itemID, quantity, r.sqlNow())
 		if err != nil {
 			return err
 		}
@@ -1079,13 +1237,11 @@ func (r *Repository) GetDeadEligibleFighters() ([]Fighter, error) {
 	return fighters, err
 }
 
-// HasUsedSerumToday checks whether the user has already used a serum today according to Central Time.
-// We store timestamps in UTC, so we compute Central day bounds and compare against UTC range.
+// HasUsedSerumToday checks whether the user has already used a serum today in league time.
+// We store timestamps in UTC, so we compute league day bounds and compare against UTC range.
 func (r *Repository) HasUsedSerumToday(userID int) (bool, error) {
-	// Determine Central Time day window and convert to UTC for comparison
-	central, _ := time.LoadLocation("America/Chicago")
-	nowCentral := time.Now().In(central)
-	startCentral, endCentral := utils.GetDayBounds(nowCentral)
+	// Determine the league day window and convert to UTC for comparison
+	startCentral, endCentral := utils.GetDayBounds(r.clock.Now())
 	startUTC := startCentral.UTC().Format("2006-01-02 15:04:05")
 	endUTC := endCentral.UTC().Format("2006-01-02 15:04:05")
 
@@ -1130,10 +1286,8 @@ func (r *Repository) ApplySerum(userID, shopItemID, fighterID int) (bool, error)
 		return false, invErr
 	}
 
-	// Enforce one serum per user per day based on Central Time day window
-	central, _ := time.LoadLocation("America/Chicago")
-	nowCentral := time.Now().In(central)
-	startCentral, endCentral := utils.GetDayBounds(nowCentral)
+	// Enforce one serum per user per day based on the league day window
+	startCentral, endCentral := utils.GetDayBounds(r.clock.Now())
 	startUTC := startCentral.UTC().Format("2006-01-02 15:04:05")
 	endUTC := endCentral.UTC().Format("2006-01-02 15:04:05")
 
@@ -1167,7 +1321,7 @@ func (r *Repository) ApplySerum(userID, shopItemID, fighterID int) (bool, error)
 	}
 
 	// Log usage in applied_effects
-	nowStr := time.Now().UTC().Format("2006-01-02 15:04:05")
+	nowStr := r.sqlNow()
 	val := 0
 	if worked {
 		val = 1
@@ -1200,7 +1354,7 @@ func (r *Repository) UseInventoryItem(userID, itemID int, quantity int) error {
 
 func (r *Repository) ApplyEffect(userID int, targetType string, targetID int, effectType string, effectValue int) error {
 	// Store current time as UTC for consistent storage
-	now := time.Now().UTC()
+	now := r.clock.Now().UTC()
 	timestampStr := now.Format("2006-01-02 15:04:05")
 
 	// For fighter effects, randomly select which stat to modify
@@ -1316,12 +1470,12 @@ func (r *Repository) SetUserSetting(userID int, settingType, settingValue string
 
 	_, err := r.db.Exec(`
 		INSERT INTO user_settings (user_id, setting_type, setting_value, can_change_at, updated_at) 
-		VALUES (?, ?, ?, ?, datetime('now'))
+		VALUES (?, ?, ?, ?, ?)
 		ON CONFLICT(user_id, setting_type) DO UPDATE SET
 			setting_value = excluded.setting_value,
 			can_change_at = excluded.can_change_at,
-			updated_at = datetime('now')`,
-		userID, settingType, settingValue, canChangeAtValue)
+			updated_at = excluded.updated_at`,
+		userID, settingType, settingValue, canChangeAtValue, r.sqlNow())
 	return err
 }
 
@@ -1342,7 +1496,7 @@ func (r *Repository) CanChangeUserSetting(userID int, settingType string) (bool,
 	}
 
 	// Check if enough time has passed
-	return time.Now().After(setting.CanChangeAt.Time), nil
+	return r.clock.Now().After(setting.CanChangeAt.Time), nil
 }
 
 func (r *Repository) PayToChangeUserSetting(userID int, settingType, newValue string, cost int) error {
@@ -1353,11 +1507,12 @@ func (r *Repository) PayToChangeUserSetting(userID int, settingType, newValue st
 	defer tx.Rollback()
 
 	// Deduct credits
-	_, err = tx.Exec(`
-		UPDATE users 
-		SET credits = credits - ?, updated_at = datetime('now') 
-		WHERE id = ? AND credits >= ?`,
-		cost, userID, cost)
+	_, err = r.postCredit(tx, CreditMove{
+		UserID: userID,
+		Amount: -cost,
+		Reason: ReasonSettingChange,
+		Ref:    SettingRef(settingType),
+	})
 	if err != nil {
 		return err
 	}
@@ -1365,12 +1520,12 @@ func (r *Repository) PayToChangeUserSetting(userID int, settingType, newValue st
 	// Update setting with no restriction
 	_, err = tx.Exec(`
 		INSERT INTO user_settings (user_id, setting_type, setting_value, can_change_at, updated_at) 
-		VALUES (?, ?, ?, NULL, datetime('now'))
+		VALUES (?, ?, ?, NULL, ?)
 		ON CONFLICT(user_id, setting_type) DO UPDATE SET
 			setting_value = excluded.setting_value,
 			can_change_at = NULL,
-			updated_at = datetime('now')`,
-		userID, settingType, newValue)
+			updated_at = excluded.updated_at`,
+		userID, settingType, newValue, r.sqlNow())
 	if err != nil {
 		return err
 	}
@@ -1411,11 +1566,13 @@ func (r *Repository) ProcessMVPRewards(fightID int, winnerID int) error {
 		}
 
 		// Award 10,000 credits
-		_, err = r.db.Exec(`
-			UPDATE users 
-			SET credits = credits + 10000, updated_at = datetime('now') 
-			WHERE id = ?`,
-			userID)
+		_, err = r.MoveCredits(CreditMove{
+			UserID: userID,
+			Amount: 10000,
+			Reason: ReasonMVPReward,
+			Ref:    FightRef(fightID),
+			Memo:   fmt.Sprintf("Fighter %d won", winnerID),
+		})
 		if err != nil {
 			log.Printf("Failed to award MVP credits to user %d: %v", userID, err)
 		} else {
@@ -1450,7 +1607,7 @@ func (r *Repository) IncrementFighterCombatStat(fighterID int, stat string, delt
 
 func (r *Repository) CreateChampionLegacyRecord(rec ChampionLegacyRecord) error {
 	if rec.CreatedAt.IsZero() {
-		rec.CreatedAt = time.Now().UTC()
+		rec.CreatedAt = r.clock.Now().UTC()
 	}
 	rec.StatAwarded = strings.ToLower(rec.StatAwarded)
 	_, err := r.db.NamedExec(`
@@ -1664,7 +1821,7 @@ func (r *Repository) UpsertWeeklyWeather(w *WeatherWeekly) error {
             tournament_id, tournament_week, week_start, seed_hash, algo_version,
             biome, pizza_selection, casino_officials, weekly_traits_json, transition_matrix_json,
             created_at, updated_at
-        ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'), datetime('now'))
+        ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
         ON CONFLICT(tournament_id, tournament_week) DO UPDATE SET
             seed_hash = excluded.seed_hash,
             algo_version = excluded.algo_version,
@@ -1673,9 +1830,9 @@ func (r *Repository) UpsertWeeklyWeather(w *WeatherWeekly) error {
             casino_officials = excluded.casino_officials,
             weekly_traits_json = excluded.weekly_traits_json,
             transition_matrix_json = excluded.transition_matrix_json,
-            updated_at = datetime('now')
+            updated_at = excluded.updated_at
     `, w.TournamentID, w.TournamentWeek, w.WeekStart, w.SeedHash, w.AlgoVersion,
-		w.Biome, w.PizzaSelection, w.CasinoOfficials, w.WeeklyTraitsJSON, w.TransitionMatrixJSON)
+		w.Biome, w.PizzaSelection, w.CasinoOfficials, w.WeeklyTraitsJSON, w.TransitionMatrixJSON, r.sqlNow(), r.sqlNow())
 	return err
 }
 
@@ -1687,7 +1844,7 @@ func (r *Repository) UpsertDailyWeather(d *WeatherDaily) error {
             wind_speed_mph, wind_dir_deg, precipitation_mm, drizzle_minutes,
             indices_json, counts_json, events_json, meta_json, is_final,
             created_at, updated_at
-        ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'), datetime('now'))
+        ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
         ON CONFLICT(date) DO UPDATE SET
             tournament_id = excluded.tournament_id,
             tournament_week = excluded.tournament_week,
@@ -1708,11 +1865,11 @@ func (r *Repository) UpsertDailyWeather(d *WeatherDaily) error {
             events_json = excluded.events_json,
             meta_json = excluded.meta_json,
             is_final = excluded.is_final,
-            updated_at = datetime('now')
+            updated_at = excluded.updated_at
     `, d.Date.UTC().Format("2006-01-02"), d.TournamentID, d.TournamentWeek, d.SeedHash, d.AlgoVersion, d.Regime,
 		d.Viscosity, d.TemperatureF, d.Temporality, d.CheeseSmell, d.TimeMode,
 		d.WindSpeedMPH, d.WindDirDeg, d.PrecipitationMM, d.DrizzleMinutes,
-		d.IndicesJSON, d.CountsJSON, d.EventsJSON, d.MetaJSON, d.IsFinal)
+		d.IndicesJSON, d.CountsJSON, d.EventsJSON, d.MetaJSON, d.IsFinal, r.sqlNow(), r.sqlNow())
 	return err
 }
 
@@ -1752,7 +1909,7 @@ func (r *Repository) GetFighterByName(name string) (*Fighter, error) {
 // CreateCustomFighter creates a new custom fighter and returns the fighter ID
 func (r *Repository) CreateCustomFighter(fighter Fighter) (int, error) {
 	ensureFighterDefaults(&fighter)
-	now := time.Now()
+	now := r.clock.Now()
 	fighter.CreatedAt = now
 	fighter.Genome = fighter.DeriveGenome()
 	return insertFighterRecord(r.db, fighter, now)
@@ -1794,7 +1951,7 @@ func (r *Repository) CreateHybridFighter(userID int, fighter Fighter) (int, erro
 	}
 
 	ensureFighterDefaults(&fighter)
-	now := time.Now()
+	now := r.clock.Now()
 	fighter.CreatedAt = now
 	fighter.Genome = fighter.DeriveGenome()
 	if fighter.CreatedByUserID == nil {
@@ -1826,7 +1983,18 @@ func (r *Repository) UpdateFighterAvatarURL(fighterID int, avatarURL string) err
 // GetUserIDsWithBetsOnFight returns user IDs of users who have bets on the given fight
 func (r *Repository) GetUserIDsWithBetsOnFight(fightID int) ([]int, error) {
 	var userIDs []int
-	err := r.db.Select(&userIDs, "SELECT DISTINCT user_id FROM bets WHERE fight_id = ? AND status = 'pending'", fightID)
+	err := r.db.Select(&userIDs, `
+        SELECT user_id FROM bets WHERE fight_id = ? AND status = 'pending'
+        UNION
+        SELECT user_id FROM prop_bets WHERE fight_id = ? AND status = 'pending'
+        UNION
+        SELECT s.user_id FROM bet_slip_legs l JOIN bet_slips s ON l.slip_id = s.id
+        WHERE l.fight_id = ? AND l.status = 'pending' AND s.status = 'pending'
+        UNION
+        SELECT challenger_id FROM challenges WHERE fight_id = ? AND status IN ('open', 'matched')
+        UNION
+        SELECT acceptor_id FROM challenges WHERE fight_id = ? AND status = 'matched'`,
+		fightID, fightID, fightID, fightID, fightID)
 	return userIDs, err
 }
 
@@ -1883,12 +2051,13 @@ func (r *Repository) TaxHighRollersIfNeeded(now time.Time) error {
 
 		// Deduct 7.5%
 		tax := (user.Credits * 75) / 1000
-		newCredits := user.Credits - tax
-		if newCredits < 0 {
-			newCredits = 0
-		}
 
-		err = r.UpdateUserCredits(uid, newCredits)
+		_, err = r.MoveCredits(CreditMove{
+			UserID: uid,
+			Amount: -tax,
+			Reason: ReasonHighRollerTax,
+			Ref:    TaxWeekRef(weekKey),
+		})
 		if err != nil {
 			log.Printf("Failed to apply high-roller tax to user %d: %v", uid, err)
 			continue
@@ -1992,7 +2161,7 @@ func (r *Repository) DecaySacrificesIfNeeded(now time.Time) error {
 		if newTotal > 0 {
 			if _, err = tx.Exec(`
                 INSERT INTO user_inventory (user_id, shop_item_id, quantity, created_at)
-                VALUES (?, ?, ?, datetime('now'))`, rec.UserID, sacrificeItemID, newTotal); err != nil {
+                VALUES (?, ?, ?, ?)`, rec.UserID, sacrificeItemID, newTotal, r.sqlNow()); err != nil {
 				tx.Rollback()
 				return err
 			}
@@ -2001,11 +2170,11 @@ func (r *Repository) DecaySacrificesIfNeeded(now time.Time) error {
 		// Mark as decayed this week
 		if _, err = tx.Exec(`
             INSERT INTO user_settings (user_id, setting_type, setting_value, updated_at)
-            VALUES (?, 'sacrifice_decay_week', ?, datetime('now'))
+            VALUES (?, 'sacrifice_decay_week', ?, ?)
             ON CONFLICT(user_id, setting_type) DO UPDATE SET
                 setting_value = excluded.setting_value,
-                updated_at = datetime('now')
-        `, rec.UserID, weekKey); err != nil {
+                updated_at = excluded.updated_at
+        `, rec.UserID, weekKey, r.sqlNow()); err != nil {
 			tx.Rollback()
 			return err
 		}
@@ -2023,14 +2192,27 @@ func (r *Repository) DecaySacrificesIfNeeded(now time.Time) error {
 // Idempotent by operation: running multiple times in a day will not increase balances beyond the minimum.
 // Intended to be called once per day by the scheduler.
 func (r *Repository) TopUpUsersToMinimum(minimumCredits int) error {
-	// Use a single UPDATE to efficiently raise all users below the threshold
-	_, err := r.db.Exec(`
-        UPDATE users
-        SET credits = ?, updated_at = datetime('now')
-        WHERE credits < ?
-    `, minimumCredits, minimumCredits)
+	tx, err := r.db.Beginx()
 	if err != nil {
 		return err
 	}
-	return nil
+	defer tx.Rollback()
+
+	var short []struct {
+		ID      int `db:"id"`
+		Credits int `db:"credits"`
+	}
+	if err := tx.Select(&short, `SELECT id, credits FROM users WHERE credits < ?`, minimumCredits); err != nil {
+		return err
+	}
+	for _, u := range short {
+		if _, err := r.postCredit(tx, CreditMove{
+			UserID: u.ID,
+			Amount: minimumCredits - u.Credits,
+			Reason: ReasonDailyTopUp,
+		}); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
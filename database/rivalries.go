@@ -0,0 +1,269 @@
+package database
+
+import (
+	"sort"
+	"time"
+)
+
+// Rivalry scoring. Every meeting after the first, every close decision and
+// every kill between a pair heats the rivalry up.
+const (
+	RivalryRematchPoints = 10
+	RivalryClosePoints   = 15
+	RivalryKillPoints    = 40
+	CloseDecisionMargin  = 5000 // health gap at the final bell that counts as close
+)
+
+// headToHeadFightFilter picks the fights that count towards head-to-head records
+const headToHeadFightFilter = `status = 'completed' AND format = 'singles'`
+
+// ensureGrudgeColumn adds the flag marking fights booked as grudge matches
+func (r *Repository) ensureGrudgeColumn() error {
+	exists, err := r.columnExists("fights", "grudge")
+	if err != nil || exists {
+		return err
+	}
+	_, err = r.db.Exec(`ALTER TABLE fights ADD COLUMN grudge BOOLEAN NOT NULL DEFAULT FALSE`)
+	return err
+}
+
+// HeadToHead is the record between two fighters. FighterA is always the lower ID.
+type HeadToHead struct {
+	FighterA       int       `json:"fighter_a"`
+	FighterB       int       `json:"fighter_b"`
+	NameA          string    `json:"name_a"`
+	NameB          string    `json:"name_b"`
+	Meetings       int       `json:"meetings"`
+	WinsA          int       `json:"wins_a"`
+	WinsB          int       `json:"wins_b"`
+	Draws          int       `json:"draws"`
+	CloseDecisions int       `json:"close_decisions"` // went the distance within CloseDecisionMargin, draws included
+	KillsA         int       `json:"kills_a"`         // times A killed B
+	KillsB         int       `json:"kills_b"`
+	LastMet        time.Time `json:"last_met"`
+	Score          int       `json:"score"`
+}
+
+// Rival is a head-to-head record seen from one fighter's side
+type Rival struct {
+	OpponentID     int
+	OpponentName   string
+	Meetings       int
+	Wins           int
+	Losses         int
+	Draws          int
+	CloseDecisions int
+	Kills          int // times this fighter killed the opponent
+	KilledBy       int // times the opponent killed this fighter
+	LastMet        time.Time
+	Score          int
+}
+
+// pairKey orders two fighter IDs the way HeadToHead stores them
+func pairKey(a, b int) [2]int {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]int{a, b}
+}
+
+// From returns the record from fighterID's side
+func (h HeadToHead) From(fighterID int) Rival {
+	if fighterID == h.FighterB {
+		return Rival{h.FighterA, h.NameA, h.Meetings, h.WinsB, h.WinsA, h.Draws, h.CloseDecisions, h.KillsB, h.KillsA, h.LastMet, h.Score}
+	}
+	return Rival{h.FighterB, h.NameB, h.Meetings, h.WinsA, h.WinsB, h.Draws, h.CloseDecisions, h.KillsA, h.KillsB, h.LastMet, h.Score}
+}
+
+// score recomputes the rivalry score from the record
+func (h *HeadToHead) score() {
+	h.Score = RivalryRematchPoints*max(0, h.Meetings-1) +
+		RivalryClosePoints*h.CloseDecisions +
+		RivalryKillPoints*(h.KillsA+h.KillsB)
+}
+
+// GetHeadToHeadRecords computes the record for every pair of fighters that
+// has met in a completed singles fight, hottest rivalries first
+func (r *Repository) GetHeadToHeadRecords() ([]HeadToHead, error) {
+	var fights []Fight
+	if err := r.db.Select(&fights, `SELECT * FROM fights WHERE `+headToHeadFightFilter+` ORDER BY completed_at ASC`); err != nil {
+		return nil, err
+	}
+	var kills []FighterKill
+	if err := r.db.Select(&kills, `SELECT id, killer_fighter_id, victim_fighter_id, fight_id, round, tick, created_at FROM fighter_kills`); err != nil {
+		return nil, err
+	}
+	records := buildHeadToHead(fights, kills)
+
+	// Pairs that only ever met in a battle royale have no fight to take names from
+	var names []struct {
+		ID   int    `db:"id"`
+		Name string `db:"name"`
+	}
+	if err := r.db.Select(&names, `SELECT id, name FROM fighters`); err != nil {
+		return nil, err
+	}
+	nameOf := make(map[int]string, len(names))
+	for _, n := range names {
+		nameOf[n.ID] = n.Name
+	}
+	for i := range records {
+		if records[i].NameA == "" {
+			records[i].NameA = nameOf[records[i].FighterA]
+		}
+		if records[i].NameB == "" {
+			records[i].NameB = nameOf[records[i].FighterB]
+		}
+	}
+	return records, nil
+}
+
+// GetHeadToHead returns the record between two fighters from the meetings
+// that finished before a cutoff
+func (r *Repository) GetHeadToHead(fighterA, fighterB int, before time.Time) (*HeadToHead, error) {
+	cutoff := sqlTime(before)
+	var fights []Fight
+	err := r.db.Select(&fights, `
+		SELECT * FROM fights
+		WHERE `+headToHeadFightFilter+` AND completed_at < ?
+		  AND ((fighter1_id = ? AND fighter2_id = ?) OR (fighter1_id = ? AND fighter2_id = ?))
+		ORDER BY completed_at ASC`,
+		cutoff, fighterA, fighterB, fighterB, fighterA)
+	if err != nil {
+		return nil, err
+	}
+	var kills []FighterKill
+	err = r.db.Select(&kills, `
+		SELECT k.id, k.killer_fighter_id, k.victim_fighter_id, k.fight_id, k.round, k.tick, k.created_at
+		FROM fighter_kills k
+		WHERE ((k.killer_fighter_id = ? AND k.victim_fighter_id = ?) OR (k.killer_fighter_id = ? AND k.victim_fighter_id = ?))
+		  AND `+killedBefore,
+		fighterA, fighterB, fighterB, fighterA, cutoff, cutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	key := pairKey(fighterA, fighterB)
+	for _, h := range buildHeadToHead(fights, kills) {
+		if h.FighterA == key[0] && h.FighterB == key[1] {
+			return &h, nil
+		}
+	}
+	return &HeadToHead{FighterA: key[0], FighterB: key[1]}, nil
+}
+
+// GetRivalsForFighter returns everyone a fighter has a history with, hottest
+// rivalry first
+func (r *Repository) GetRivalsForFighter(fighterID, limit int) ([]Rival, error) {
+	records, err := r.GetHeadToHeadRecords()
+	if err != nil {
+		return nil, err
+	}
+	var rivals []Rival
+	for _, h := range records {
+		if h.FighterA != fighterID && h.FighterB != fighterID {
+			continue
+		}
+		rivals = append(rivals, h.From(fighterID))
+		if limit > 0 && len(rivals) == limit {
+			break
+		}
+	}
+	return rivals, nil
+}
+
+// buildHeadToHead folds fights (oldest first) and kills into per-pair records,
+// sorted by score, then meetings, then most recent meeting
+func buildHeadToHead(fights []Fight, kills []FighterKill) []HeadToHead {
+	pairs := make(map[[2]int]*HeadToHead)
+	get := func(a, b int) *HeadToHead {
+		key := pairKey(a, b)
+		h, ok := pairs[key]
+		if !ok {
+			h = &HeadToHead{FighterA: key[0], FighterB: key[1]}
+			pairs[key] = h
+		}
+		return h
+	}
+
+	// A fight that ended in a death wasn't decided by the judges, however close
+	killFights := make(map[int]bool)
+	for _, k := range kills {
+		if k.FightID != 0 {
+			killFights[k.FightID] = true
+		}
+	}
+
+	for _, f := range fights {
+		if f.Fighter1ID == 0 || f.Fighter2ID == 0 || f.Fighter1ID == f.Fighter2ID {
+			continue
+		}
+		h := get(f.Fighter1ID, f.Fighter2ID)
+		h.Meetings++
+		if f.Fighter1ID == h.FighterA {
+			h.NameA, h.NameB = f.Fighter1Name, f.Fighter2Name
+		} else {
+			h.NameA, h.NameB = f.Fighter2Name, f.Fighter1Name
+		}
+		switch {
+		case !f.WinnerID.Valid:
+			h.Draws++
+		case int(f.WinnerID.Int64) == h.FighterA:
+			h.WinsA++
+		default:
+			h.WinsB++
+		}
+		if !killFights[f.ID] && f.isCloseDecision() {
+			h.CloseDecisions++
+		}
+		met := f.ScheduledTime
+		if f.CompletedAt.Valid {
+			met = f.CompletedAt.Time
+		}
+		if met.After(h.LastMet) {
+			h.LastMet = met
+		}
+	}
+
+	for _, k := range kills {
+		if k.KillerFighterID == 0 || k.VictimFighterID == 0 || k.KillerFighterID == k.VictimFighterID {
+			continue
+		}
+		h := get(k.KillerFighterID, k.VictimFighterID)
+		if k.KillerFighterID == h.FighterA {
+			h.KillsA++
+		} else {
+			h.KillsB++
+		}
+	}
+
+	records := make([]HeadToHead, 0, len(pairs))
+	for _, h := range pairs {
+		h.score()
+		records = append(records, *h)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Meetings != b.Meetings {
+			return a.Meetings > b.Meetings
+		}
+		if !a.LastMet.Equal(b.LastMet) {
+			return a.LastMet.After(b.LastMet)
+		}
+		return a.FighterA < b.FighterA || (a.FighterA == b.FighterA && a.FighterB < b.FighterB)
+	})
+	return records
+}
+
+// isCloseDecision reports whether the fight went the distance and finished
+// within CloseDecisionMargin. A draw is as close as it gets.
+func (f Fight) isCloseDecision() bool {
+	if !f.FinalScore1.Valid || !f.FinalScore2.Valid || f.FinalScore1.Int64 <= 0 || f.FinalScore2.Int64 <= 0 {
+		return false
+	}
+	gap := f.FinalScore1.Int64 - f.FinalScore2.Int64
+	return gap <= CloseDecisionMargin && gap >= -CloseDecisionMargin
+}
@@ -0,0 +1,448 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"spoodblort/utils"
+)
+
+// Battle royale limits and bet types
+const (
+	RoyaleMinEntrants = 8
+	RoyaleMaxEntrants = 16
+
+	RoyaleBetWinner = "winner" // pays if the fighter is the last one standing
+	RoyaleBetTop3   = "top3"   // pays if the fighter places first, second or third
+)
+
+func (r *Repository) ensureBattleRoyaleTables() error {
+	exists, err := r.tableExists("battle_royales")
+	if err != nil {
+		return err
+	}
+	if !exists {
+		_, err = r.db.Exec(`
+            CREATE TABLE battle_royales (
+                id INTEGER PRIMARY KEY AUTOINCREMENT,
+                name TEXT NOT NULL,
+                scheduled_time DATETIME NOT NULL,
+                status TEXT NOT NULL DEFAULT 'scheduled' CHECK (status IN ('scheduled', 'active', 'completed', 'voided')),
+                winner_id INTEGER,
+                ruleset TEXT NOT NULL DEFAULT '',
+                algo_version TEXT NOT NULL DEFAULT '',
+                created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                completed_at DATETIME,
+                FOREIGN KEY (winner_id) REFERENCES fighters(id)
+            );
+        `)
+		if err != nil {
+			return err
+		}
+	}
+
+	exists, err = r.tableExists("battle_royale_entrants")
+	if err != nil {
+		return err
+	}
+	if !exists {
+		_, err = r.db.Exec(`
+            CREATE TABLE battle_royale_entrants (
+                id INTEGER PRIMARY KEY AUTOINCREMENT,
+                royale_id INTEGER NOT NULL,
+                fighter_id INTEGER NOT NULL,
+                fighter_name TEXT NOT NULL,
+                slot INTEGER NOT NULL,
+                placement INTEGER NOT NULL DEFAULT 0,
+                eliminated_by INTEGER NOT NULL DEFAULT 0,
+                eliminated_round INTEGER NOT NULL DEFAULT 0,
+                eliminated_tick INTEGER NOT NULL DEFAULT 0,
+                final_health INTEGER NOT NULL DEFAULT 0,
+                UNIQUE(royale_id, fighter_id),
+                FOREIGN KEY (royale_id) REFERENCES battle_royales(id),
+                FOREIGN KEY (fighter_id) REFERENCES fighters(id)
+            );
+        `)
+		if err != nil {
+			return err
+		}
+	}
+
+	exists, err = r.tableExists("battle_royale_bets")
+	if err != nil {
+		return err
+	}
+	if !exists {
+		_, err = r.db.Exec(`
+            CREATE TABLE battle_royale_bets (
+                id INTEGER PRIMARY KEY AUTOINCREMENT,
+                user_id INTEGER NOT NULL,
+                royale_id INTEGER NOT NULL,
+                fighter_id INTEGER NOT NULL,
+                bet_type TEXT NOT NULL CHECK (bet_type IN ('winner', 'top3')),
+                amount INTEGER NOT NULL,
+                odds REAL NOT NULL,
+                status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'won', 'lost', 'voided')),
+                payout INTEGER NOT NULL DEFAULT 0,
+                created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                resolved_at DATETIME,
+                FOREIGN KEY (user_id) REFERENCES users(id),
+                FOREIGN KEY (royale_id) REFERENCES battle_royales(id),
+                FOREIGN KEY (fighter_id) REFERENCES fighters(id)
+            );
+        `)
+		if err != nil {
+			return err
+		}
+		if _, err = r.db.Exec(`CREATE INDEX idx_battle_royale_bets_royale ON battle_royale_bets(royale_id, status)`); err != nil {
+			return err
+		}
+	}
+
+	if err := r.ensureRoyaleKillsColumn(); err != nil {
+		return err
+	}
+	return r.ensureRoyaleTickSaltColumn()
+}
+
+// ensureRoyaleTickSaltColumn adds the secret salt a royale's tick seeds are
+// shifted by. Royales that ran before it keep 0 and replay unsalted.
+func (r *Repository) ensureRoyaleTickSaltColumn() error {
+	exists, err := r.columnExists("battle_royales", "tick_salt")
+	if err != nil || exists {
+		return err
+	}
+	_, err = r.db.Exec(`ALTER TABLE battle_royales ADD COLUMN tick_salt INTEGER NOT NULL DEFAULT 0`)
+	return err
+}
+
+// ensureRoyaleKillsColumn lets fighter_kills rows point at a battle royale.
+// Royale eliminations are stored with fight_id 0 so they never attach to a fight.
+func (r *Repository) ensureRoyaleKillsColumn() error {
+	exists, err := r.tableExists("fighter_kills")
+	if err != nil {
+		return err
+	}
+	if !exists {
+		_, err = r.db.Exec(`
+            CREATE TABLE fighter_kills (
+                id INTEGER PRIMARY KEY AUTOINCREMENT,
+                killer_fighter_id INTEGER NOT NULL,
+                victim_fighter_id INTEGER NOT NULL,
+                fight_id INTEGER NOT NULL DEFAULT 0,
+                round INTEGER NOT NULL DEFAULT 0,
+                tick INTEGER NOT NULL DEFAULT 0,
+                created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                battle_royale_id INTEGER NOT NULL DEFAULT 0
+            );
+        `)
+		return err
+	}
+
+	exists, err = r.columnExists("fighter_kills", "battle_royale_id")
+	if err != nil || exists {
+		return err
+	}
+	_, err = r.db.Exec(`ALTER TABLE fighter_kills ADD COLUMN battle_royale_id INTEGER NOT NULL DEFAULT 0`)
+	return err
+}
+
+// CreateBattleRoyale schedules a royale between fighters, entered in slot order
+func (r *Repository) CreateBattleRoyale(name string, scheduledTime time.Time, ruleset, algoVersion string, fighters []Fighter) (int, error) {
+	if len(fighters) < RoyaleMinEntrants || len(fighters) > RoyaleMaxEntrants {
+		return 0, fmt.Errorf("a battle royale needs %d to %d fighters, got %d", RoyaleMinEntrants, RoyaleMaxEntrants, len(fighters))
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+        INSERT INTO battle_royales (name, scheduled_time, status, ruleset, algo_version)
+        VALUES (?, ?, 'scheduled', ?, ?)`, name, scheduledTime, ruleset, algoVersion)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	for slot, f := range fighters {
+		if _, err := tx.Exec(`
+            INSERT INTO battle_royale_entrants (royale_id, fighter_id, fighter_name, slot)
+            VALUES (?, ?, ?, ?)`, id, f.ID, f.Name, slot+1); err != nil {
+			return 0, fmt.Errorf("enter fighter %d: %w", f.ID, err)
+		}
+	}
+
+	return int(id), tx.Commit()
+}
+
+func (r *Repository) GetBattleRoyale(id int) (*BattleRoyale, error) {
+	var royale BattleRoyale
+	err := r.db.Get(&royale, `SELECT * FROM battle_royales WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &royale, nil
+}
+
+// GetBattleRoyaleEntrants returns a royale's fighters in slot order
+func (r *Repository) GetBattleRoyaleEntrants(royaleID int) ([]RoyaleEntrant, error) {
+	entrants := []RoyaleEntrant{}
+	err := r.db.Select(&entrants, `SELECT * FROM battle_royale_entrants WHERE royale_id = ? ORDER BY slot`, royaleID)
+	return entrants, err
+}
+
+// GetUpcomingBattleRoyales returns royales that haven't finished, soonest first
+func (r *Repository) GetUpcomingBattleRoyales() ([]BattleRoyale, error) {
+	royales := []BattleRoyale{}
+	err := r.db.Select(&royales, `
+        SELECT * FROM battle_royales
+        WHERE status IN ('scheduled', 'active')
+        ORDER BY scheduled_time`)
+	return royales, err
+}
+
+// ActivateDueBattleRoyales starts every scheduled royale whose time has come.
+// Each one draws its secret tick salt as betting closes, so nobody can play
+// the royale out ahead of time from its ID and the entrants' public stats.
+func (r *Repository) ActivateDueBattleRoyales(now time.Time) error {
+	var due []int
+	if err := r.db.Select(&due, `
+        SELECT id FROM battle_royales
+        WHERE status = 'scheduled' AND scheduled_time <= ?`, now); err != nil {
+		return err
+	}
+	for _, id := range due {
+		salt, err := utils.NewTickSalt()
+		if err != nil {
+			return fmt.Errorf("draw tick salt for battle royale %d: %w", id, err)
+		}
+		if _, err := r.db.Exec(`
+            UPDATE battle_royales SET status = 'active', tick_salt = ?
+            WHERE id = ? AND status = 'scheduled'`, salt, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecordRoyaleElimination places an eliminated fighter and logs the kill
+// against whoever put them out. Eliminating the same fighter twice, as a
+// catch-up after a restart will, changes nothing.
+func (r *Repository) RecordRoyaleElimination(royaleID, fighterID, killerID, placement, round, tick, finalHealth int, died bool) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+        UPDATE battle_royale_entrants
+        SET placement = ?, eliminated_by = ?, eliminated_round = ?, eliminated_tick = ?, final_health = ?
+        WHERE royale_id = ? AND fighter_id = ? AND placement = 0`,
+		placement, killerID, round, tick, finalHealth, royaleID, fighterID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil
+	}
+
+	if killerID != 0 {
+		if _, err := tx.Exec(`
+            INSERT INTO fighter_kills (killer_fighter_id, victim_fighter_id, fight_id, round, tick, battle_royale_id)
+            VALUES (?, ?, 0, ?, ?, ?)`, killerID, fighterID, round, tick, royaleID); err != nil {
+			return err
+		}
+	}
+	if died {
+		if _, err := tx.Exec(`UPDATE fighters SET is_dead = TRUE WHERE id = ?`, fighterID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// CompleteBattleRoyale places the fighters still standing, crowns the winner
+// and settles every pending bet in one transaction. standing is ordered from
+// first place down.
+func (r *Repository) CompleteBattleRoyale(royaleID int, standing []RoyaleEntrant) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status string
+	if err := tx.Get(&status, `SELECT status FROM battle_royales WHERE id = ?`, royaleID); err != nil {
+		return err
+	}
+	if status == "completed" {
+		return nil
+	}
+
+	for i, e := range standing {
+		if _, err := tx.Exec(`
+            UPDATE battle_royale_entrants SET placement = ?, final_health = ?
+            WHERE royale_id = ? AND fighter_id = ?`, i+1, e.FinalHealth, royaleID, e.FighterID); err != nil {
+			return err
+		}
+	}
+	placements := map[int]int{}
+	rows := []struct {
+		FighterID int `db:"fighter_id"`
+		Placement int `db:"placement"`
+	}{}
+	if err := tx.Select(&rows, `SELECT fighter_id, placement FROM battle_royale_entrants WHERE royale_id = ?`, royaleID); err != nil {
+		return err
+	}
+	// The winner is usually still standing, but a double knockout can leave
+	// the last fighter out in first place
+	winnerID := sql.NullInt64{}
+	for _, row := range rows {
+		placements[row.FighterID] = row.Placement
+		if row.Placement == 1 {
+			winnerID = sql.NullInt64{Int64: int64(row.FighterID), Valid: true}
+		}
+	}
+	if _, err := tx.Exec(`
+        UPDATE battle_royales SET status = 'completed', winner_id = ?, completed_at = ?
+        WHERE id = ?`, winnerID, r.sqlNow(), royaleID); err != nil {
+		return err
+	}
+
+	bets := []RoyaleBet{}
+	if err := tx.Select(&bets, `SELECT * FROM battle_royale_bets WHERE royale_id = ? AND status = 'pending'`, royaleID); err != nil {
+		return err
+	}
+	for _, bet := range bets {
+		status, payout := "lost", 0
+		if bet.Wins(placements[bet.FighterID]) {
+			status, payout = "won", int(float64(bet.Amount)*bet.Odds)
+		}
+		if _, err := tx.Exec(`
+            UPDATE battle_royale_bets SET status = ?, payout = ?, resolved_at = ?
+            WHERE id = ?`, status, payout, r.sqlNow(), bet.ID); err != nil {
+			return err
+		}
+		if payout > 0 {
+			if _, err := r.postCredit(tx, CreditMove{
+				UserID: bet.UserID,
+				Amount: payout,
+				Reason: ReasonBetPayout,
+				Ref:    RoyaleBetRef(bet.ID),
+				Memo:   fmt.Sprintf("Battle royale %d", royaleID),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// VoidBattleRoyale calls off a royale that hasn't finished and refunds its bets
+func (r *Repository) VoidBattleRoyale(royaleID int) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+        UPDATE battle_royales SET status = 'voided', completed_at = ?
+        WHERE id = ? AND status IN ('scheduled', 'active')`, r.sqlNow(), royaleID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("battle royale %d has already finished", royaleID)
+	}
+
+	var bets []RoyaleBet
+	if err := tx.Select(&bets, `SELECT * FROM battle_royale_bets WHERE royale_id = ? AND status = 'pending'`, royaleID); err != nil {
+		return err
+	}
+	for _, bet := range bets {
+		if _, err := r.postCredit(tx, CreditMove{
+			UserID: bet.UserID,
+			Amount: bet.Amount,
+			Reason: ReasonBetRefund,
+			Ref:    RoyaleBetRef(bet.ID),
+			Memo:   fmt.Sprintf("Battle royale %d voided", royaleID),
+		}); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`
+        UPDATE battle_royale_bets SET status = 'voided', payout = amount, resolved_at = ?
+        WHERE royale_id = ? AND status = 'pending'`, r.sqlNow(), royaleID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CreateRoyaleBet places a bet on a scheduled royale at the quoted decimal
+// odds, taking the stake in the same transaction
+func (r *Repository) CreateRoyaleBet(userID, royaleID, fighterID int, betType string, amount int, odds float64) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var status string
+	if err := tx.QueryRow(`SELECT status FROM battle_royales WHERE id = ?`, royaleID).Scan(&status); err != nil {
+		return 0, err
+	}
+	if status != "scheduled" {
+		return 0, fmt.Errorf("betting is closed for this battle royale")
+	}
+
+	res, err := tx.Exec(`
+        INSERT INTO battle_royale_bets (user_id, royale_id, fighter_id, bet_type, amount, odds, status, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, 'pending', ?)`, userID, royaleID, fighterID, betType, amount, odds, r.sqlNow())
+	if err != nil {
+		return 0, err
+	}
+	betID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := r.postCredit(tx, CreditMove{
+		UserID: userID,
+		Amount: -amount,
+		Reason: ReasonBetStake,
+		Ref:    RoyaleBetRef(int(betID)),
+		Memo:   fmt.Sprintf("Battle royale %d, %s", royaleID, betType),
+	}); err != nil {
+		return 0, err
+	}
+	return int(betID), tx.Commit()
+}
+
+// GetUserRoyaleBets returns a user's bets on one royale, newest first
+func (r *Repository) GetUserRoyaleBets(userID, royaleID int) ([]RoyaleBet, error) {
+	bets := []RoyaleBet{}
+	err := r.db.Select(&bets, `
+        SELECT * FROM battle_royale_bets WHERE user_id = ? AND royale_id = ?
+        ORDER BY created_at DESC`, userID, royaleID)
+	return bets, err
+}
+
+// Wins reports whether the bet pays for a fighter finishing at placement
+func (b RoyaleBet) Wins(placement int) bool {
+	switch b.BetType {
+	case RoyaleBetWinner:
+		return placement == 1
+	case RoyaleBetTop3:
+		return placement >= 1 && placement <= 3
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package database
+
+import "fmt"
+
+// ensureRulesetColumns adds the per-tournament and per-fight ruleset selectors.
+// An empty value means "inherit": fights fall back to their tournament, and
+// tournaments fall back to the engine default.
+func (r *Repository) ensureRulesetColumns() error {
+	for _, table := range []string{"tournaments", "fights"} {
+		exists, err := r.columnExists(table, "ruleset")
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if _, err := r.db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN ruleset TEXT NOT NULL DEFAULT ''`, table)); err != nil {
+			return fmt.Errorf("add column %s.ruleset: %w", table, err)
+		}
+	}
+	return r.ensureFightAlgoVersionColumn()
+}
+
+// LegacyAlgoVersion marks fights that ran before algorithm versions existed.
+// They replay under the legacy ruleset, which reproduces the old engine's
+// separate catch-up and live tick paths.
+const LegacyAlgoVersion = "legacy"
+
+// ensureFightAlgoVersionColumn adds fights.algo_version. Every fight that ran
+// before versioning existed is stamped legacy.
+func (r *Repository) ensureFightAlgoVersionColumn() error {
+	exists, err := r.columnExists("fights", "algo_version")
+	if err != nil || exists {
+		return err
+	}
+	if _, err := r.db.Exec(`ALTER TABLE fights ADD COLUMN algo_version TEXT NOT NULL DEFAULT ''`); err != nil {
+		return fmt.Errorf("add column fights.algo_version: %w", err)
+	}
+	_, err = r.db.Exec(`
+		UPDATE fights
+		SET algo_version = ?,
+			ruleset = CASE WHEN ruleset = '' THEN 'standard' ELSE ruleset END
+		WHERE status IN ('active', 'completed')`, LegacyAlgoVersion)
+	return err
+}
+
+// restampLegacyFights moves pre-versioning fights that an earlier migration
+// stamped "v1" over to legacy. They are recognisable by having no recorded
+// fighter snapshot. Any genuine v1 fight caught up in this still replays the
+// same, since legacy with no catch-up ticks plays exactly as v1.
+func (r *Repository) restampLegacyFights() error {
+	_, err := r.db.Exec(`
+		UPDATE fights
+		SET algo_version = ?
+		WHERE algo_version = 'v1'
+		  AND ruleset = 'standard'
+		  AND NOT EXISTS (
+			SELECT 1 FROM fight_inputs fi
+			WHERE fi.fight_id = fights.id AND fi.input_type = ?
+		  )`, LegacyAlgoVersion, FightInputFighterSnapshot)
+	return err
+}
+
+// SetTournamentRuleset selects the combat ruleset for every fight in a tournament
+// that doesn't pick its own
+func (r *Repository) SetTournamentRuleset(tournamentID int, ruleset string) error {
+	_, err := r.db.Exec(`UPDATE tournaments SET ruleset = ? WHERE id = ?`, ruleset, tournamentID)
+	return err
+}
+
+// SetFightRuleset overrides the combat ruleset for a single fight. Only
+// scheduled fights can change rules; anything already underway keeps its own.
+func (r *Repository) SetFightRuleset(fightID int, ruleset string) error {
+	res, err := r.db.Exec(`UPDATE fights SET ruleset = ? WHERE id = ? AND status = 'scheduled'`, ruleset, fightID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("fight %d is not scheduled", fightID)
+	}
+	return nil
+}
+
+// StampFightAlgorithm pins the ruleset and algorithm version a fight runs under.
+// It only writes the first time so a fight can never be re-stamped mid-flight.
+func (r *Repository) StampFightAlgorithm(fightID int, ruleset, algoVersion string) error {
+	_, err := r.db.Exec(`UPDATE fights SET ruleset = ?, algo_version = ? WHERE id = ? AND algo_version = ''`,
+		ruleset, algoVersion, fightID)
+	return err
+}
@@ -0,0 +1,192 @@
+package database
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+)
+
+// Bet settlement modes. An empty mode on a fight or tournament means "inherit";
+// anything that inherits all the way down settles at fixed odds.
+const (
+	SettlementFixed      = "fixed"
+	SettlementParimutuel = "parimutuel"
+)
+
+// DefaultParimutuelRakePercent is the house cut of a parimutuel pool when
+// PARIMUTUEL_RAKE_PERCENT isn't set
+const DefaultParimutuelRakePercent = 10
+
+// ensureSettlementModeColumns adds the per-tournament and per-fight settlement
+// mode selectors
+func (r *Repository) ensureSettlementModeColumns() error {
+	for _, table := range []string{"tournaments", "fights"} {
+		exists, err := r.columnExists(table, "settlement_mode")
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if _, err := r.db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN settlement_mode TEXT NOT NULL DEFAULT ''`, table)); err != nil {
+			return fmt.Errorf("add column %s.settlement_mode: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// ValidSettlementMode reports whether mode can be stored; empty means inherit
+func ValidSettlementMode(mode string) bool {
+	return mode == "" || mode == SettlementFixed || mode == SettlementParimutuel
+}
+
+// SetTournamentSettlementMode selects how bets settle for every fight in a
+// tournament that doesn't pick its own mode
+func (r *Repository) SetTournamentSettlementMode(tournamentID int, mode string) error {
+	_, err := r.db.Exec(`UPDATE tournaments SET settlement_mode = ? WHERE id = ?`, mode, tournamentID)
+	return err
+}
+
+// SetFightSettlementMode overrides the settlement mode for a single fight.
+// Only scheduled fights can switch; bets on a fight underway keep their terms.
+func (r *Repository) SetFightSettlementMode(fightID int, mode string) error {
+	res, err := r.db.Exec(`UPDATE fights SET settlement_mode = ? WHERE id = ? AND status = 'scheduled'`, mode, fightID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("fight %d is not scheduled", fightID)
+	}
+	return nil
+}
+
+// SettlementModeForFight resolves a fight's settlement mode: the fight's own
+// choice, then its tournament's, then fixed odds
+func (r *Repository) SettlementModeForFight(fightID int) (string, error) {
+	var mode string
+	err := r.db.Get(&mode, `
+        SELECT COALESCE(NULLIF(f.settlement_mode, ''), NULLIF(t.settlement_mode, ''), ?)
+        FROM fights f
+        LEFT JOIN tournaments t ON t.id = f.tournament_id
+        WHERE f.id = ?`, SettlementFixed, fightID)
+	return mode, err
+}
+
+// ParimutuelRakePercent returns the house cut taken from parimutuel pools,
+// configured with PARIMUTUEL_RAKE_PERCENT (0-50)
+func ParimutuelRakePercent() int {
+	rake := DefaultParimutuelRakePercent
+	if v := os.Getenv("PARIMUTUEL_RAKE_PERCENT"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			rake = n
+		}
+	}
+	if rake < 0 {
+		rake = 0
+	}
+	if rake > 50 {
+		rake = 50
+	}
+	return rake
+}
+
+// BetPool is the pending stake on each side of a fight
+type BetPool struct {
+	FightID       int `json:"fight_id"`
+	Fighter1ID    int `json:"fighter1_id"`
+	Fighter2ID    int `json:"fighter2_id"`
+	Fighter1Stake int `json:"fighter1_stake"`
+	Fighter2Stake int `json:"fighter2_stake"`
+	Fighter1Bets  int `json:"fighter1_bets"`
+	Fighter2Bets  int `json:"fighter2_bets"`
+	RakePercent   int `json:"rake_percent"`
+}
+
+// Total is everything staked on the fight
+func (p BetPool) Total() int {
+	return p.Fighter1Stake + p.Fighter2Stake
+}
+
+// Net is the pool left for winners once the rake is taken
+func (p BetPool) Net() int {
+	return p.Total() - p.Total()*p.RakePercent/100
+}
+
+// ImpliedPayout is what one credit staked on fighterID returns if they win,
+// as things stand now. Zero when nobody has backed that side yet; never less
+// than the stake back, as parimutuelOutcomes settles it.
+func (p BetPool) ImpliedPayout(fighterID int) float64 {
+	stake := p.Fighter2Stake
+	if fighterID == p.Fighter1ID {
+		stake = p.Fighter1Stake
+	}
+	if stake == 0 {
+		return 0
+	}
+	return math.Max(1, float64(p.Net())/float64(stake))
+}
+
+// GetBetPool totals the pending stakes on each side of a fight
+func (r *Repository) GetBetPool(fightID int) (*BetPool, error) {
+	pool := BetPool{FightID: fightID, RakePercent: ParimutuelRakePercent()}
+	err := r.db.QueryRow(`
+        SELECT f.fighter1_id, f.fighter2_id,
+            COALESCE(SUM(CASE WHEN b.fighter_id = f.fighter1_id THEN b.amount END), 0),
+            COALESCE(SUM(CASE WHEN b.fighter_id = f.fighter2_id THEN b.amount END), 0),
+            COUNT(CASE WHEN b.fighter_id = f.fighter1_id THEN 1 END),
+            COUNT(CASE WHEN b.fighter_id = f.fighter2_id THEN 1 END)
+        FROM fights f
+        LEFT JOIN bets b ON b.fight_id = f.id AND b.status = 'pending'
+        WHERE f.id = ?
+        GROUP BY f.id`, fightID).Scan(&pool.Fighter1ID, &pool.Fighter2ID,
+		&pool.Fighter1Stake, &pool.Fighter2Stake, &pool.Fighter1Bets, &pool.Fighter2Bets)
+	if err != nil {
+		return nil, err
+	}
+	return &pool, nil
+}
+
+// betOutcome is how a single bet settles
+type betOutcome struct {
+	status string
+	payout int
+}
+
+// parimutuelOutcomes splits the pool among the winning side in proportion to
+// stake, after the rake. Draws, and pools where nobody backed the winner or
+// nobody backed anyone else, are refunded in full. A winner never gets back
+// less than their stake, even when the rake outweighs the losing side; the
+// house covers the difference. Rounding crumbs stay with the house.
+func parimutuelOutcomes(bets []Bet, winnerID *int, rakePercent int) map[int]betOutcome {
+	outcomes := make(map[int]betOutcome, len(bets))
+
+	total, winningStake := 0, 0
+	for _, bet := range bets {
+		total += bet.Amount
+		if winnerID != nil && bet.FighterID == *winnerID {
+			winningStake += bet.Amount
+		}
+	}
+
+	if winnerID == nil || winningStake == 0 || winningStake == total {
+		for _, bet := range bets {
+			outcomes[bet.ID] = betOutcome{status: "voided", payout: bet.Amount}
+		}
+		return outcomes
+	}
+
+	net := int64(total - total*rakePercent/100)
+	for _, bet := range bets {
+		if bet.FighterID == *winnerID {
+			payout := int(net * int64(bet.Amount) / int64(winningStake))
+			if payout < bet.Amount {
+				payout = bet.Amount
+			}
+			outcomes[bet.ID] = betOutcome{status: "won", payout: payout}
+		} else {
+			outcomes[bet.ID] = betOutcome{status: "lost"}
+		}
+	}
+	return outcomes
+}
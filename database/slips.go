@@ -0,0 +1,310 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// BetSlip is a parlay: one stake riding on every leg. Odds are the product of
+// the legs still in play, so they shrink when a leg is voided out.
+type BetSlip struct {
+	ID         int          `db:"id" json:"id"`
+	UserID     int          `db:"user_id" json:"user_id"`
+	Stake      int          `db:"stake" json:"stake"`
+	Odds       float64      `db:"odds" json:"odds"`
+	Status     string       `db:"status" json:"status"` // pending, won, lost or voided
+	Payout     int          `db:"payout" json:"payout"`
+	CreatedAt  time.Time    `db:"created_at" json:"created_at"`
+	ResolvedAt sql.NullTime `db:"resolved_at" json:"resolved_at"`
+}
+
+// BetSlipLeg backs one fighter on one fight of a slip at odds fixed when the
+// slip was placed
+type BetSlipLeg struct {
+	ID         int          `db:"id" json:"id"`
+	SlipID     int          `db:"slip_id" json:"slip_id"`
+	FightID    int          `db:"fight_id" json:"fight_id"`
+	FighterID  int          `db:"fighter_id" json:"fighter_id"`
+	Odds       float64      `db:"odds" json:"odds"`
+	Status     string       `db:"status" json:"status"` // pending, won, lost or voided
+	VoidReason string       `db:"void_reason" json:"void_reason,omitempty"`
+	ResolvedAt sql.NullTime `db:"resolved_at" json:"resolved_at"`
+}
+
+// BetSlipLegWithFight is a leg with enough of its fight to display it
+type BetSlipLegWithFight struct {
+	BetSlipLeg
+	Fighter1Name  string    `db:"fighter1_name" json:"fighter1_name"`
+	Fighter2Name  string    `db:"fighter2_name" json:"fighter2_name"`
+	FighterName   string    `db:"fighter_name" json:"fighter_name"`
+	ScheduledTime time.Time `db:"scheduled_time" json:"scheduled_time"`
+}
+
+// BetSlipWithLegs is a slip and its legs in fight order
+type BetSlipWithLegs struct {
+	BetSlip
+	Legs []BetSlipLegWithFight `json:"legs"`
+}
+
+// LegsLeft counts the legs still waiting on their fight
+func (s BetSlipWithLegs) LegsLeft() int {
+	left := 0
+	for _, leg := range s.Legs {
+		if leg.Status == "pending" {
+			left++
+		}
+	}
+	return left
+}
+
+// PotentialPayout is what the slip returns if every remaining leg comes in
+func (s BetSlip) PotentialPayout() int {
+	return int(float64(s.Stake) * s.Odds)
+}
+
+func (r *Repository) ensureBetSlipTables() error {
+	exists, err := r.tableExists("bet_slips")
+	if err != nil {
+		return err
+	}
+	if !exists {
+		_, err = r.db.Exec(`
+            CREATE TABLE bet_slips (
+                id INTEGER PRIMARY KEY AUTOINCREMENT,
+                user_id INTEGER NOT NULL,
+                stake INTEGER NOT NULL,
+                odds REAL NOT NULL,
+                status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'won', 'lost', 'voided')),
+                payout INTEGER NOT NULL DEFAULT 0,
+                created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                resolved_at DATETIME,
+                FOREIGN KEY (user_id) REFERENCES users(id)
+            );
+            CREATE INDEX idx_bet_slips_user ON bet_slips(user_id, created_at);
+        `)
+		if err != nil {
+			return err
+		}
+	}
+
+	exists, err = r.tableExists("bet_slip_legs")
+	if err != nil {
+		return err
+	}
+	if !exists {
+		_, err = r.db.Exec(`
+            CREATE TABLE bet_slip_legs (
+                id INTEGER PRIMARY KEY AUTOINCREMENT,
+                slip_id INTEGER NOT NULL,
+                fight_id INTEGER NOT NULL,
+                fighter_id INTEGER NOT NULL,
+                odds REAL NOT NULL,
+                status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'won', 'lost', 'voided')),
+                resolved_at DATETIME,
+                UNIQUE (slip_id, fight_id),
+                FOREIGN KEY (slip_id) REFERENCES bet_slips(id),
+                FOREIGN KEY (fight_id) REFERENCES fights(id),
+                FOREIGN KEY (fighter_id) REFERENCES fighters(id)
+            );
+            CREATE INDEX idx_bet_slip_legs_fight ON bet_slip_legs(fight_id, status);
+        `)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateBetSlip places a parlay over legs on scheduled fights, taking the
+// stake in the same transaction. Leg odds are the caller's quote.
+func (r *Repository) CreateBetSlip(userID, stake int, legs []BetSlipLeg) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	odds := 1.0
+	for _, leg := range legs {
+		var status string
+		if err := tx.QueryRow(`SELECT status FROM fights WHERE id = ?`, leg.FightID).Scan(&status); err != nil {
+			return 0, fmt.Errorf("fight %d: %w", leg.FightID, err)
+		}
+		if status != "scheduled" {
+			return 0, fmt.Errorf("betting is closed for fight %d", leg.FightID)
+		}
+		odds *= leg.Odds
+	}
+
+	res, err := tx.Exec(`
+        INSERT INTO bet_slips (user_id, stake, odds, status, created_at)
+        VALUES (?, ?, ?, 'pending', ?)`,
+		userID, stake, odds, r.sqlNow())
+	if err != nil {
+		return 0, err
+	}
+	slipID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	for _, leg := range legs {
+		if _, err := tx.Exec(`
+            INSERT INTO bet_slip_legs (slip_id, fight_id, fighter_id, odds, status)
+            VALUES (?, ?, ?, ?, 'pending')`,
+			slipID, leg.FightID, leg.FighterID, leg.Odds); err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err := r.postCredit(tx, CreditMove{
+		UserID: userID,
+		Amount: -stake,
+		Reason: ReasonBetStake,
+		Ref:    BetSlipRef(int(slipID)),
+		Memo:   fmt.Sprintf("%d-leg parlay", len(legs)),
+	}); err != nil {
+		return 0, err
+	}
+	return int(slipID), tx.Commit()
+}
+
+// GetUserBetSlips returns a user's most recent slips with their legs
+func (r *Repository) GetUserBetSlips(userID, limit int) ([]BetSlipWithLegs, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	slips := []BetSlip{}
+	if err := r.db.Select(&slips, `
+        SELECT * FROM bet_slips WHERE user_id = ?
+        ORDER BY created_at DESC, id DESC LIMIT ?`, userID, limit); err != nil {
+		return nil, err
+	}
+
+	result := make([]BetSlipWithLegs, 0, len(slips))
+	for _, slip := range slips {
+		legs := []BetSlipLegWithFight{}
+		if err := r.db.Select(&legs, `
+            SELECT l.*, f.fighter1_name, f.fighter2_name, f.scheduled_time, fr.name AS fighter_name
+            FROM bet_slip_legs l
+            JOIN fights f ON l.fight_id = f.id
+            JOIN fighters fr ON l.fighter_id = fr.id
+            WHERE l.slip_id = ?
+            ORDER BY f.scheduled_time, l.id`, slip.ID); err != nil {
+			return nil, err
+		}
+		result = append(result, BetSlipWithLegs{BetSlip: slip, Legs: legs})
+	}
+	return result, nil
+}
+
+// ProcessSlipLegsForFight settles every pending slip leg on a fight. A nil
+// winner voids the legs, which drop out of their slips. Each slip touched is
+// then settled once it's lost a leg or run out of pending ones.
+func (r *Repository) ProcessSlipLegsForFight(fightID int, winnerID *int) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := r.settleSlipLegs(tx, fightID, winnerID, ""); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// settleSlipLegs resolves a fight's pending slip legs inside tx, recording
+// voidReason on legs voided by a nil winner, and returns how many legs it
+// touched
+func (r *Repository) settleSlipLegs(tx *sqlx.Tx, fightID int, winnerID *int, voidReason string) (int, error) {
+	legs := []BetSlipLeg{}
+	if err := tx.Select(&legs, `SELECT * FROM bet_slip_legs WHERE fight_id = ? AND status = 'pending'`, fightID); err != nil {
+		return 0, err
+	}
+	for _, leg := range legs {
+		status, reason := "lost", ""
+		if winnerID == nil {
+			status, reason = "voided", voidReason
+		} else if leg.FighterID == *winnerID {
+			status = "won"
+		}
+		if _, err := tx.Exec(`
+            UPDATE bet_slip_legs SET status = ?, void_reason = ?, resolved_at = ?
+            WHERE id = ?`, status, reason, r.sqlNow(), leg.ID); err != nil {
+			return 0, err
+		}
+	}
+
+	settled := map[int]bool{}
+	for _, leg := range legs {
+		if settled[leg.SlipID] {
+			continue
+		}
+		settled[leg.SlipID] = true
+
+		var slip BetSlip
+		if err := tx.Get(&slip, `SELECT * FROM bet_slips WHERE id = ?`, leg.SlipID); err != nil {
+			return 0, err
+		}
+		if slip.Status != "pending" {
+			continue // already lost on an earlier leg
+		}
+		if err := r.settleBetSlip(tx, slip); err != nil {
+			return 0, fmt.Errorf("slip %d: %w", slip.ID, err)
+		}
+	}
+	return len(legs), nil
+}
+
+// settleBetSlip reprices a pending slip from its legs and closes it out when
+// it can't go on: lost on any losing leg, refunded when every leg was voided,
+// paid when the rest came in
+func (r *Repository) settleBetSlip(tx *sqlx.Tx, slip BetSlip) error {
+	legs := []BetSlipLeg{}
+	if err := tx.Select(&legs, `SELECT * FROM bet_slip_legs WHERE slip_id = ?`, slip.ID); err != nil {
+		return err
+	}
+
+	odds, pending, live := 1.0, 0, 0
+	for _, leg := range legs {
+		switch leg.Status {
+		case "lost":
+			_, err := tx.Exec(`
+                UPDATE bet_slips SET status = 'lost', payout = 0, resolved_at = ?
+                WHERE id = ?`, r.sqlNow(), slip.ID)
+			return err
+		case "voided":
+			continue
+		case "pending":
+			pending++
+		}
+		odds *= leg.Odds
+		live++
+	}
+
+	if pending > 0 {
+		_, err := tx.Exec(`UPDATE bet_slips SET odds = ? WHERE id = ?`, odds, slip.ID)
+		return err
+	}
+
+	status, reason, payout := "won", ReasonBetPayout, int(float64(slip.Stake)*odds)
+	if live == 0 {
+		status, reason, payout = "voided", ReasonBetRefund, slip.Stake
+	}
+	if _, err := tx.Exec(`
+        UPDATE bet_slips SET status = ?, odds = ?, payout = ?, resolved_at = ?
+        WHERE id = ?`, status, odds, payout, r.sqlNow(), slip.ID); err != nil {
+		return err
+	}
+	_, err := r.postCredit(tx, CreditMove{
+		UserID: slip.UserID,
+		Amount: payout,
+		Reason: reason,
+		Ref:    BetSlipRef(slip.ID),
+		Memo:   fmt.Sprintf("%d-leg parlay", len(legs)),
+	})
+	return err
+}
@@ -0,0 +1,72 @@
+package database
+
+import "fmt"
+
+// Fight formats
+const (
+	FightFormatSingles = "singles"
+	FightFormatTagTeam = "tag_team"
+)
+
+// Tag-team health modes: each fighter has their own health and must be knocked
+// out separately, or the pair share one pool
+const (
+	TagHealthIndividual = "individual"
+	TagHealthShared     = "shared"
+)
+
+// ensureTagTeamColumns adds the fight format and the partner fighting
+// alongside each lane's captain. Singles fights leave the partners at zero.
+func (r *Repository) ensureTagTeamColumns() error {
+	columns := []struct {
+		Name string
+		DDL  string
+	}{
+		{"format", "ALTER TABLE fights ADD COLUMN format TEXT NOT NULL DEFAULT 'singles'"},
+		{"partner1_id", "ALTER TABLE fights ADD COLUMN partner1_id INTEGER NOT NULL DEFAULT 0"},
+		{"partner2_id", "ALTER TABLE fights ADD COLUMN partner2_id INTEGER NOT NULL DEFAULT 0"},
+		{"partner1_name", "ALTER TABLE fights ADD COLUMN partner1_name TEXT NOT NULL DEFAULT ''"},
+		{"partner2_name", "ALTER TABLE fights ADD COLUMN partner2_name TEXT NOT NULL DEFAULT ''"},
+		{"tag_health", "ALTER TABLE fights ADD COLUMN tag_health TEXT NOT NULL DEFAULT ''"},
+	}
+	for _, c := range columns {
+		exists, err := r.columnExists("fights", c.Name)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			if _, err := r.db.Exec(c.DDL); err != nil {
+				return fmt.Errorf("add column %s: %w", c.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// IsTagTeam reports whether the fight is two fighters a side
+func (f Fight) IsTagTeam() bool {
+	return f.Format == FightFormatTagTeam
+}
+
+// SideOf returns 1 or 2 for the side fighterID fights on, or 0 if they aren't in the fight
+func (f Fight) SideOf(fighterID int) int {
+	switch {
+	case fighterID == 0:
+		return 0
+	case fighterID == f.Fighter1ID || fighterID == f.Partner1ID:
+		return 1
+	case fighterID == f.Fighter2ID || fighterID == f.Partner2ID:
+		return 2
+	}
+	return 0
+}
+
+// WonBy reports whether fighterID was on the winning side of the fight
+func (f Fight) WonBy(fighterID int) bool {
+	return f.WinnerID.Valid && f.SideOf(fighterID) != 0 && f.SideOf(int(f.WinnerID.Int64)) == f.SideOf(fighterID)
+}
+
+// TagTeamName is how a pair is billed on the card
+func TagTeamName(captain, partner string) string {
+	return captain + " & " + partner
+}
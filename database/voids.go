@@ -0,0 +1,200 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Void policies decide what happens to stakes on a fight that never happened
+const (
+	VoidPolicyRefund  = "refund"  // the whole stake comes back
+	VoidPolicyFee     = "fee"     // the stake comes back less the cancellation fee
+	VoidPolicyForfeit = "forfeit" // the house keeps the stake
+)
+
+// ValidVoidPolicy reports whether policy is one of the void policies
+func ValidVoidPolicy(policy string) bool {
+	return policy == VoidPolicyRefund || policy == VoidPolicyFee || policy == VoidPolicyForfeit
+}
+
+// VoidBetPolicy returns how stakes on voided fights are settled, configured
+// with VOID_BET_POLICY. Anything unrecognised falls back to a full refund.
+func VoidBetPolicy() string {
+	policy := strings.ToLower(strings.TrimSpace(os.Getenv("VOID_BET_POLICY")))
+	if !ValidVoidPolicy(policy) {
+		return VoidPolicyRefund
+	}
+	return policy
+}
+
+// voidRefund is what a stake of amount gets back under policy
+func voidRefund(amount int, policy string) int {
+	switch policy {
+	case VoidPolicyFee:
+		return amount - BetCancelFee(amount)
+	case VoidPolicyForfeit:
+		return 0
+	}
+	return amount
+}
+
+// VoidedStake is one stake settled by a void
+type VoidedStake struct {
+	UserID int
+	Kind   string // RefBet, RefPropBet or RefChallenge
+	BetID  int
+	Amount int
+	Refund int
+}
+
+// VoidSettlement is everything settled when a voided fight's bets were closed out
+type VoidSettlement struct {
+	FightID  int
+	Reason   string
+	Policy   string
+	Stakes   []VoidedStake
+	SlipLegs int // parlay legs that dropped out of their slips
+}
+
+// UserRefunds totals each affected user's stake and refund
+func (v VoidSettlement) UserRefunds() map[int]VoidedStake {
+	totals := map[int]VoidedStake{}
+	for _, s := range v.Stakes {
+		t := totals[s.UserID]
+		t.UserID = s.UserID
+		t.Amount += s.Amount
+		t.Refund += s.Refund
+		totals[s.UserID] = t
+	}
+	return totals
+}
+
+// ensureVoidReasonColumns records on each bet why its fight was voided
+func (r *Repository) ensureVoidReasonColumns() error {
+	for _, table := range []string{"bets", "prop_bets", "bet_slip_legs"} {
+		exists, err := r.columnExists(table, "void_reason")
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if _, err := r.db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN void_reason TEXT NOT NULL DEFAULT ''`, table)); err != nil {
+			return fmt.Errorf("add column %s.void_reason: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// SettleVoidedFight closes out every pending bet, prop bet and parlay leg on
+// a voided fight under policy, stamping the fight's void reason on each.
+// Settling a fight with nothing pending is a no-op, so it's safe to repeat.
+func (r *Repository) SettleVoidedFight(fightID int, policy string) (*VoidSettlement, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var fight Fight
+	if err := tx.Get(&fight, `SELECT * FROM fights WHERE id = ?`, fightID); err != nil {
+		return nil, err
+	}
+	if fight.Status != "voided" {
+		return nil, fmt.Errorf("fight %d is %s, not voided", fightID, fight.Status)
+	}
+	settlement := &VoidSettlement{FightID: fightID, Reason: fight.VoidedReason.String, Policy: policy}
+	memo := fmt.Sprintf("Fight %d voided (%s)", fightID, policy)
+
+	var bets []Bet
+	if err := tx.Select(&bets, `SELECT * FROM bets WHERE fight_id = ? AND status = 'pending'`, fightID); err != nil {
+		return nil, err
+	}
+	for _, bet := range bets {
+		refund := voidRefund(bet.Amount, policy)
+		if _, err := tx.Exec(`
+            UPDATE bets SET status = 'voided', payout = ?, void_reason = ?, resolved_at = ?
+            WHERE id = ?`, refund, settlement.Reason, r.sqlNow(), bet.ID); err != nil {
+			return nil, err
+		}
+		if refund > 0 {
+			if _, err := r.postCredit(tx, CreditMove{
+				UserID: bet.UserID,
+				Amount: refund,
+				Reason: ReasonBetRefund,
+				Ref:    BetRef(bet.ID),
+				Memo:   memo,
+			}); err != nil {
+				return nil, err
+			}
+		}
+		settlement.Stakes = append(settlement.Stakes, VoidedStake{bet.UserID, RefBet, bet.ID, bet.Amount, refund})
+	}
+
+	var props []PropBet
+	if err := tx.Select(&props, `SELECT * FROM prop_bets WHERE fight_id = ? AND status = 'pending'`, fightID); err != nil {
+		return nil, err
+	}
+	for _, bet := range props {
+		refund := voidRefund(bet.Amount, policy)
+		if _, err := tx.Exec(`
+            UPDATE prop_bets SET status = 'voided', payout = ?, void_reason = ?, resolved_at = ?
+            WHERE id = ?`, refund, settlement.Reason, r.sqlNow(), bet.ID); err != nil {
+			return nil, err
+		}
+		if refund > 0 {
+			if _, err := r.postCredit(tx, CreditMove{
+				UserID: bet.UserID,
+				Amount: refund,
+				Reason: ReasonBetRefund,
+				Ref:    PropBetRef(bet.ID),
+				Memo:   memo,
+			}); err != nil {
+				return nil, err
+			}
+		}
+		settlement.Stakes = append(settlement.Stakes, VoidedStake{bet.UserID, RefPropBet, bet.ID, bet.Amount, refund})
+	}
+
+	// Parlay legs drop out of their slips whatever the policy; the slip
+	// carries on with the legs it has left
+	settlement.SlipLegs, err = r.settleSlipLegs(tx, fightID, nil, settlement.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	// Challenges between users come back in full whatever the policy, since
+	// the house never had a stake in them
+	challenges, err := r.settleChallenges(tx, fightID, nil, memo)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range challenges {
+		settlement.Stakes = append(settlement.Stakes, VoidedStake{c.ChallengerID, RefChallenge, c.ID, c.Stake, c.Stake})
+		if c.AcceptorID.Valid {
+			settlement.Stakes = append(settlement.Stakes, VoidedStake{int(c.AcceptorID.Int64), RefChallenge, c.ID, c.Stake, c.Stake})
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return settlement, nil
+}
+
+// GetVoidedFightsWithPendingBets returns voided fights that still have stakes
+// waiting on them, oldest first
+func (r *Repository) GetVoidedFightsWithPendingBets() ([]Fight, error) {
+	var fights []Fight
+	err := r.db.Select(&fights, `
+        SELECT * FROM fights f
+        WHERE f.status = 'voided' AND (
+            EXISTS (SELECT 1 FROM bets b WHERE b.fight_id = f.id AND b.status = 'pending')
+            OR EXISTS (SELECT 1 FROM prop_bets p WHERE p.fight_id = f.id AND p.status = 'pending')
+            OR EXISTS (SELECT 1 FROM bet_slip_legs l WHERE l.fight_id = f.id AND l.status = 'pending')
+            OR EXISTS (SELECT 1 FROM challenges c WHERE c.fight_id = f.id AND c.status IN ('open', 'matched'))
+        )
+        ORDER BY f.scheduled_time, f.id`)
+	return fights, err
+}
@@ -106,7 +106,7 @@ func (n *Notifier) NotifyFightResult(fightData database.Fight, state *FightState
 		return nil
 	}
 
-	// Get applied effects for both fighters, limited to the fight's day (Central Time),
+	// Get applied effects for both fighters, limited to the fight's day in league time,
 	// matching how the website displays them
 	var fighter1Effects, fighter2Effects []database.AppliedEffect
 	{
@@ -114,8 +114,8 @@ func (n *Notifier) NotifyFightResult(fightData database.Fight, state *FightState
 		// For completed fights (which this notifier handles), use the fight's scheduled date
 		effectDate := fightData.ScheduledTime
 		// Compute day bounds in the same timezone used in web layer
-		centralTime, _ := time.LoadLocation("America/Chicago")
-		startDate := time.Date(effectDate.In(centralTime).Year(), effectDate.In(centralTime).Month(), effectDate.In(centralTime).Day(), 0, 0, 0, 0, centralTime)
+		leagueTime := n.repo.Clock().Location()
+		startDate := time.Date(effectDate.In(leagueTime).Year(), effectDate.In(leagueTime).Month(), effectDate.In(leagueTime).Day(), 0, 0, 0, 0, leagueTime)
 		endDate := startDate.Add(24 * time.Hour)
 
 		fighter1Effects, _ = n.repo.GetAppliedEffectsForDate("fighter", fightData.Fighter1ID, startDate, endDate)
@@ -564,6 +564,117 @@ func (n *Notifier) NotifyActionSummary(fightData database.Fight, winnerID int) e
 	return n.sendTextViaBot(n.actionChannelID, content)
 }
 
+// NotifyVoidSettlement tells everyone with stakes on a voided fight what
+// they got back, pinging each of them in the action channel
+func (n *Notifier) NotifyVoidSettlement(fightData database.Fight, settlement *database.VoidSettlement) error {
+	if n.botToken == "" || settlement == nil {
+		return nil
+	}
+	refunds := settlement.UserRefunds()
+	if len(refunds) == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("~~%s vs %s~~ VOIDED (%s/fight/%d)", fightData.Fighter1Name, fightData.Fighter2Name, n.serverBaseURL, fightData.ID))
+	if settlement.Reason != "" {
+		sb.WriteString("\n*" + settlement.Reason + "*")
+	}
+
+	userIDs := make([]int, 0, len(refunds))
+	for id := range refunds {
+		userIDs = append(userIDs, id)
+	}
+	sort.Ints(userIDs)
+	for _, id := range userIDs {
+		r := refunds[id]
+		who := fmt.Sprintf("user %d", id)
+		if user, err := n.repo.GetUser(id); err == nil {
+			who = "<@" + user.DiscordID + ">"
+		}
+		sb.WriteString("\n")
+		switch {
+		case r.Refund == r.Amount:
+			sb.WriteString(fmt.Sprintf("%s refunded %s", who, formatNumber(r.Refund)))
+		case r.Refund == 0:
+			sb.WriteString(fmt.Sprintf("%s forfeits %s to the void", who, formatNumber(r.Amount)))
+		default:
+			sb.WriteString(fmt.Sprintf("%s refunded %s of %s", who, formatNumber(r.Refund), formatNumber(r.Amount)))
+		}
+	}
+
+	return n.sendTextViaBot(n.actionChannelID, sb.String())
+}
+
+// AnnounceChallenge posts a new challenge to the action channel so someone
+// can take the other side
+func (n *Notifier) AnnounceChallenge(fightData database.Fight, challenge *database.Challenge) error {
+	if n.botToken == "" || challenge == nil {
+		return nil
+	}
+	content := fmt.Sprintf("🤝 %s puts %s on **%s** over %s. Who'll take it? (%s/fight/%d)",
+		n.mention(challenge.ChallengerID), formatNumber(challenge.Stake),
+		fighterNameInFight(fightData, challenge.ChallengerFighterID), fighterNameInFight(fightData, challenge.AcceptorFighterID),
+		n.serverBaseURL, fightData.ID)
+	return n.sendTextViaBot(n.actionChannelID, content)
+}
+
+// AnnounceChallengeAccepted posts when a challenge is matched and both stakes are in escrow
+func (n *Notifier) AnnounceChallengeAccepted(fightData database.Fight, challenge *database.Challenge) error {
+	if n.botToken == "" || challenge == nil || !challenge.AcceptorID.Valid {
+		return nil
+	}
+	content := fmt.Sprintf("🤝 %s takes %s's challenge: %s on %s vs %s on %s, %s in escrow (%s/fight/%d)",
+		n.mention(int(challenge.AcceptorID.Int64)), n.mention(challenge.ChallengerID),
+		n.mention(challenge.ChallengerID), fighterNameInFight(fightData, challenge.ChallengerFighterID),
+		n.mention(int(challenge.AcceptorID.Int64)), fighterNameInFight(fightData, challenge.AcceptorFighterID),
+		formatNumber(challenge.Pot()), n.serverBaseURL, fightData.ID)
+	return n.sendTextViaBot(n.actionChannelID, content)
+}
+
+// NotifyChallengeResults announces who collected on a finished fight's
+// challenges and whose stakes came back
+func (n *Notifier) NotifyChallengeResults(fightData database.Fight, challenges []database.Challenge) error {
+	if n.botToken == "" || len(challenges) == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🤝 Challenges on %s vs %s (%s/fight/%d)", fightData.Fighter1Name, fightData.Fighter2Name, n.serverBaseURL, fightData.ID))
+	for _, c := range challenges {
+		sb.WriteString("\n")
+		switch {
+		case c.Status == database.ChallengeSettled:
+			loser := c.ChallengerID
+			if int(c.WinnerID.Int64) == c.ChallengerID {
+				loser = int(c.AcceptorID.Int64)
+			}
+			sb.WriteString(fmt.Sprintf("%s takes %s from %s", n.mention(int(c.WinnerID.Int64)), formatNumber(c.Pot()), n.mention(loser)))
+		case c.AcceptorID.Valid:
+			sb.WriteString(fmt.Sprintf("%s and %s get %s back each", n.mention(c.ChallengerID), n.mention(int(c.AcceptorID.Int64)), formatNumber(c.Stake)))
+		default:
+			sb.WriteString(fmt.Sprintf("%s's %s went untaken and was refunded", n.mention(c.ChallengerID), formatNumber(c.Stake)))
+		}
+	}
+	return n.sendTextViaBot(n.actionChannelID, sb.String())
+}
+
+// mention pings a user by Discord ID, falling back to their user ID
+func (n *Notifier) mention(userID int) string {
+	if user, err := n.repo.GetUser(userID); err == nil && user.DiscordID != "" {
+		return "<@" + user.DiscordID + ">"
+	}
+	return fmt.Sprintf("user %d", userID)
+}
+
+// fighterNameInFight names one of a fight's two sides
+func fighterNameInFight(fightData database.Fight, fighterID int) string {
+	if fighterID == fightData.Fighter2ID {
+		return fightData.Fighter2Name
+	}
+	return fightData.Fighter1Name
+}
+
 // Helper functions
 
 func formatNumber(n int) string {
@@ -503,3 +503,47 @@ func (rm *RoleManager) AssignSuspectRole(user *database.User) error {
 	}
 	return nil
 }
+
+// DebtorRoleName marks users in default with the Department loan office
+const DebtorRoleName = "🧾 Debtor"
+
+// SyncDebtorRole gives a user the Debtor role while they're in default and
+// takes it away once the loan is cleared
+func (rm *RoleManager) SyncDebtorRole(user *database.User, debtor bool) error {
+	if rm.botToken == "" || rm.guildID == "" || user == nil || user.DiscordID == "" {
+		return nil
+	}
+	member, err := rm.getGuildMember(user.DiscordID)
+	if err != nil {
+		return nil
+	}
+	if rm.memberHasRole(member, DebtorRoleName) == debtor {
+		return nil
+	}
+
+	if debtor {
+		roleID, err := rm.ensureRoleExistsWithColor(DebtorRoleName, "8B4513")
+		if err != nil {
+			return fmt.Errorf("failed to ensure debtor role: %w", err)
+		}
+		if err := rm.addRoleIDToUser(user.DiscordID, roleID); err != nil {
+			return fmt.Errorf("failed to assign debtor role: %w", err)
+		}
+		log.Printf("🧾 Assigned Debtor role to %s", user.Username)
+		return nil
+	}
+
+	guildRoles, err := rm.getGuildRoles()
+	if err != nil {
+		return err
+	}
+	for _, role := range guildRoles {
+		if role.Name == DebtorRoleName {
+			if err := rm.removeRoleFromUser(user.DiscordID, role.ID); err != nil {
+				return fmt.Errorf("failed to remove debtor role: %w", err)
+			}
+		}
+	}
+	log.Printf("Removed Debtor role from %s", user.Username)
+	return nil
+}
@@ -0,0 +1,116 @@
+package fight
+
+import (
+	"math/big"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// coinFlipAdvantage flips one coin per stat point for each fighter; the side
+// with more heads wins and ties break on one more coin. Cost grows with the stats.
+func coinFlipAdvantage(v1, v2 int, rng *rand.Rand) bool {
+	heads1 := 0
+	heads2 := 0
+
+	for i := 0; i < v1; i++ {
+		if rng.Intn(2) == 0 {
+			heads1++
+		}
+	}
+	for i := 0; i < v2; i++ {
+		if rng.Intn(2) == 0 {
+			heads2++
+		}
+	}
+
+	if heads1 == heads2 {
+		// random tie-breaker
+		return rng.Intn(2) == 0
+	}
+	return heads1 > heads2
+}
+
+// binomialAdvantage settles the same contest as coinFlipAdvantage with a single
+// draw against the exact chance that fighter 1 comes out ahead
+func binomialAdvantage(v1, v2 int, rng *rand.Rand) bool {
+	return rng.Float64() < coinFlipWinChance(v1, v2)
+}
+
+// coinFlipWinChances caches coinFlipWinChance by stat pair. Stats only move
+// between fights, so a fight looks its pairs up hundreds of times.
+var coinFlipWinChances sync.Map // [2]int -> float64
+
+// coinFlipWinChance is the chance fighter 1 wins the coin flips: more heads
+// from v1 coins than from v2, or an equal count and the tie-breaker.
+//
+// With X ~ Bin(v1, ½) and Y ~ Bin(v2, ½), v2-Y is also Bin(v2, ½), so
+// Z = X + v2 - Y ~ Bin(v1+v2, ½) and X > Y exactly when Z > v2. The chance is
+// worked out in exact integer arithmetic and rounded once, so every platform
+// gets the same float and replays stay deterministic.
+func coinFlipWinChance(v1, v2 int) float64 {
+	v1, v2 = max(v1, 0), max(v2, 0)
+	key := [2]int{v1, v2}
+	if p, ok := coinFlipWinChances.Load(key); ok {
+		return p.(float64)
+	}
+
+	// Count outcomes out of 2^(n+1): every Z > v2 twice, Z == v2 once for the tie-breaker
+	n := v1 + v2
+	favourable := new(big.Int)
+	choose := big.NewInt(1) // C(n, k)
+	for k := 0; k <= n; k++ {
+		if k > 0 {
+			choose.Mul(choose, big.NewInt(int64(n-k+1)))
+			choose.Quo(choose, big.NewInt(int64(k)))
+		}
+		switch {
+		case k == v2:
+			favourable.Add(favourable, choose)
+		case k > v2:
+			favourable.Add(favourable, choose)
+			favourable.Add(favourable, choose)
+		}
+	}
+	outcomes := new(big.Int).Lsh(big.NewInt(1), uint(n+1))
+	p, _ := new(big.Rat).SetFrac(favourable, outcomes).Float64()
+
+	coinFlipWinChances.Store(key, p)
+	return p
+}
+
+// AdvantageBench compares the coin-flip and binomial stat contests at one stat level
+type AdvantageBench struct {
+	Stat         int
+	Edge         int // how far fighter 2's stat is ahead
+	Contests     int
+	CoinFlip     time.Duration // per contest
+	Binomial     time.Duration // per contest, cache already warm
+	CoinFlipWins float64       // share won by fighter 1, which should sit near the exact chance
+	BinomialWins float64
+	ExactChance  float64
+}
+
+// BenchStatAdvantage times seeded contests between a stat and stat+edge, once
+// flipping coins and once sampling the binomial
+func BenchStatAdvantage(stat, edge, contests int, seed int64) AdvantageBench {
+	bench := AdvantageBench{Stat: stat, Edge: edge, Contests: contests, ExactChance: coinFlipWinChance(stat, stat+edge)}
+	if contests <= 0 {
+		return bench
+	}
+
+	run := func(contest func(v1, v2 int, rng *rand.Rand) bool) (time.Duration, float64) {
+		rng := rand.New(rand.NewSource(seed))
+		wins := 0
+		began := time.Now()
+		for i := 0; i < contests; i++ {
+			if contest(stat, stat+edge, rng) {
+				wins++
+			}
+		}
+		return time.Since(began) / time.Duration(contests), float64(wins) / float64(contests)
+	}
+	bench.CoinFlip, bench.CoinFlipWins = run(coinFlipAdvantage)
+	bench.Binomial, bench.BinomialWins = run(binomialAdvantage)
+	return bench
+}
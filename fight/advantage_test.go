@@ -0,0 +1,89 @@
+package fight
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// advantageStats are the stat levels the contests are timed and checked at,
+// from a fresh fighter up to heavily blessed legacy stats
+var advantageStats = []int{10, 100, 1000, 10000}
+
+func BenchmarkCoinFlipAdvantage(b *testing.B) {
+	for _, stat := range advantageStats {
+		b.Run(fmt.Sprintf("stat=%d", stat), func(b *testing.B) {
+			rng := rand.New(rand.NewSource(1))
+			for i := 0; i < b.N; i++ {
+				coinFlipAdvantage(stat, stat+stat/10, rng)
+			}
+		})
+	}
+}
+
+func BenchmarkBinomialAdvantage(b *testing.B) {
+	for _, stat := range advantageStats {
+		b.Run(fmt.Sprintf("stat=%d", stat), func(b *testing.B) {
+			rng := rand.New(rand.NewSource(1))
+			coinFlipWinChance(stat, stat+stat/10) // warm the cache, as a fight would
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				binomialAdvantage(stat, stat+stat/10, rng)
+			}
+		})
+	}
+}
+
+func TestCoinFlipWinChanceEvenStats(t *testing.T) {
+	for _, stat := range append([]int{0}, advantageStats...) {
+		if p := coinFlipWinChance(stat, stat); p != 0.5 {
+			t.Errorf("coinFlipWinChance(%d, %d) = %v, want 0.5", stat, stat, p)
+		}
+	}
+}
+
+func TestBinomialAdvantageMatchesCoinFlipChance(t *testing.T) {
+	const contests = 200000
+	pairs := [][2]int{{0, 0}, {1, 0}, {10, 12}, {50, 40}, {100, 110}, {1000, 1030}, {10000, 9900}}
+	for _, pair := range pairs {
+		v1, v2 := pair[0], pair[1]
+		want := coinFlipWinChance(v1, v2)
+		// Four standard errors either side fails a correct sampler about once in 16,000 runs
+		tolerance := 4 * math.Sqrt(want*(1-want)/contests)
+		if tolerance == 0 {
+			tolerance = 1e-9
+		}
+
+		rng := rand.New(rand.NewSource(int64(v1*31 + v2)))
+		wins := 0
+		for i := 0; i < contests; i++ {
+			if binomialAdvantage(v1, v2, rng) {
+				wins++
+			}
+		}
+		if got := float64(wins) / contests; math.Abs(got-want) > tolerance {
+			t.Errorf("binomialAdvantage(%d, %d) won %.4f of contests, coinFlipWinChance says %.4f (±%.4f)", v1, v2, got, want, tolerance)
+		}
+	}
+}
+
+func TestCoinFlipAdvantageMatchesCoinFlipChance(t *testing.T) {
+	const contests = 100000
+	for _, pair := range [][2]int{{1, 0}, {10, 12}, {50, 40}} {
+		v1, v2 := pair[0], pair[1]
+		want := coinFlipWinChance(v1, v2)
+		tolerance := 4 * math.Sqrt(want*(1-want)/contests)
+
+		rng := rand.New(rand.NewSource(int64(v1*31 + v2)))
+		wins := 0
+		for i := 0; i < contests; i++ {
+			if coinFlipAdvantage(v1, v2, rng) {
+				wins++
+			}
+		}
+		if got := float64(wins) / contests; math.Abs(got-want) > tolerance {
+			t.Errorf("coinFlipAdvantage(%d, %d) won %.4f of contests, coinFlipWinChance says %.4f (±%.4f)", v1, v2, got, want, tolerance)
+		}
+	}
+}
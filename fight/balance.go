@@ -0,0 +1,400 @@
+package fight
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"spoodblort/database"
+	"spoodblort/utils"
+)
+
+// Balance runs play on seeds of their own. Unsalted fight seeds sit below 2^40,
+// unsalted royale seeds just above 2^44 and odds pricing for either below 2^52, so
+// balance seeds start at 3<<60 and each run seed gets a 2^40 block, room for
+// a million fights.
+const (
+	balanceSeedOffset = int64(3) << 60
+	balanceSeedStride = int64(1) << 40
+)
+
+// balanceSeedBase is the first seed a balance run with the given seed plays on
+func balanceSeedBase(seed int64) int64 {
+	return balanceSeedOffset + seed*balanceSeedStride
+}
+
+// BalanceBucket is the win rate of fighters whose stat total (or stat edge)
+// falls in [Low, High)
+type BalanceBucket struct {
+	Low    int
+	High   int
+	Fights int
+	Wins   int
+	Draws  int
+}
+
+// WinRate is the share of the bucket's fights that were won
+func (b BalanceBucket) WinRate() float64 {
+	if b.Fights == 0 {
+		return 0
+	}
+	return float64(b.Wins) / float64(b.Fights)
+}
+
+// BalanceReport is what a batch of simulated fights says about the tuning
+type BalanceReport struct {
+	Ruleset     string
+	Version     string
+	Fights      int
+	Days        int // simulated fight days; zero for random pairings
+	Decisions   int // went the distance and the judges picked a winner
+	KOs         int
+	Deaths      int
+	Draws       int
+	TotalTicks  int
+	TotalRounds int
+	// ByStatTotal is each fighter's win rate by their combat stat total
+	ByStatTotal []BalanceBucket
+	// ByStatEdge is the win rate of the fighter with the higher stat total,
+	// by how many points ahead they were
+	ByStatEdge []BalanceBucket
+	// Undead against the living
+	UndeadFights int
+	UndeadWins   int
+	UndeadDraws  int
+}
+
+// DeathsPer1000 is the death rate per thousand fights
+func (r BalanceReport) DeathsPer1000() float64 {
+	return r.share(r.Deaths) * 1000
+}
+
+// AverageTicks is the mean fight length in ticks
+func (r BalanceReport) AverageTicks() float64 {
+	return r.share(r.TotalTicks)
+}
+
+// AverageRounds is the mean number of rounds fought
+func (r BalanceReport) AverageRounds() float64 {
+	return r.share(r.TotalRounds)
+}
+
+// UndeadWinRate is how often the undead beat the living
+func (r BalanceReport) UndeadWinRate() float64 {
+	if r.UndeadFights == 0 {
+		return 0
+	}
+	return float64(r.UndeadWins) / float64(r.UndeadFights)
+}
+
+// share divides n by the number of fights
+func (r BalanceReport) share(n int) float64 {
+	if r.Fights == 0 {
+		return 0
+	}
+	return float64(n) / float64(r.Fights)
+}
+
+// balanceTally accumulates results before they are folded into a report
+type balanceTally struct {
+	report      BalanceReport
+	bucketWidth int
+	byTotal     map[int]*BalanceBucket
+	byEdge      map[int]*BalanceBucket
+}
+
+func newBalanceTally(rules Ruleset, bucketWidth int) *balanceTally {
+	if bucketWidth <= 0 {
+		bucketWidth = 25
+	}
+	return &balanceTally{
+		report:      BalanceReport{Ruleset: rules.Name(), Version: rules.Version()},
+		bucketWidth: bucketWidth,
+		byTotal:     make(map[int]*BalanceBucket),
+		byEdge:      make(map[int]*BalanceBucket),
+	}
+}
+
+// statTotal is the combat stat total the generator pairs fighters by
+func statTotal(f database.Fighter) int {
+	return f.Strength + f.Speed + f.Endurance + f.Technique
+}
+
+// bucket returns the bucket value falls into, creating it on first use
+func (t *balanceTally) bucket(buckets map[int]*BalanceBucket, value int) *BalanceBucket {
+	key := value / t.bucketWidth
+	if value < 0 && value%t.bucketWidth != 0 {
+		key--
+	}
+	b, ok := buckets[key]
+	if !ok {
+		b = &BalanceBucket{Low: key * t.bucketWidth, High: (key + 1) * t.bucketWidth}
+		buckets[key] = b
+	}
+	return b
+}
+
+// add counts one finished fight between f1 and f2
+func (t *balanceTally) add(f1, f2 database.Fighter, state FightState, decided bool) {
+	r := &t.report
+	r.Fights++
+	r.TotalTicks += state.TickNumber
+	r.TotalRounds += (max(state.TickNumber, 1)-1)/TICKS_PER_ROUND + 1
+
+	switch {
+	case state.DeathOccurred:
+		r.Deaths++
+	case state.WinnerID == 0:
+		r.Draws++
+	case decided:
+		r.Decisions++
+	default:
+		r.KOs++
+	}
+
+	for _, f := range []database.Fighter{f1, f2} {
+		b := t.bucket(t.byTotal, statTotal(f))
+		b.Fights++
+		if state.WinnerID == f.ID {
+			b.Wins++
+		} else if state.WinnerID == 0 {
+			b.Draws++
+		}
+	}
+
+	favourite, underdog := f1, f2
+	if statTotal(f2) > statTotal(f1) {
+		favourite, underdog = f2, f1
+	}
+	b := t.bucket(t.byEdge, statTotal(favourite)-statTotal(underdog))
+	b.Fights++
+	if state.WinnerID == favourite.ID {
+		b.Wins++
+	} else if state.WinnerID == 0 {
+		b.Draws++
+	}
+
+	if f1.IsUndead != f2.IsUndead {
+		undead := f1
+		if f2.IsUndead {
+			undead = f2
+		}
+		r.UndeadFights++
+		if state.WinnerID == undead.ID {
+			r.UndeadWins++
+		} else if state.WinnerID == 0 {
+			r.UndeadDraws++
+		}
+	}
+}
+
+// merge folds another tally into this one
+func (t *balanceTally) merge(o *balanceTally) {
+	r := &t.report
+	r.Fights += o.report.Fights
+	r.Decisions += o.report.Decisions
+	r.KOs += o.report.KOs
+	r.Deaths += o.report.Deaths
+	r.Draws += o.report.Draws
+	r.TotalTicks += o.report.TotalTicks
+	r.TotalRounds += o.report.TotalRounds
+	r.UndeadFights += o.report.UndeadFights
+	r.UndeadWins += o.report.UndeadWins
+	r.UndeadDraws += o.report.UndeadDraws
+	for _, pair := range [][2]map[int]*BalanceBucket{{t.byTotal, o.byTotal}, {t.byEdge, o.byEdge}} {
+		for key, ob := range pair[1] {
+			b, ok := pair[0][key]
+			if !ok {
+				b = &BalanceBucket{Low: ob.Low, High: ob.High}
+				pair[0][key] = b
+			}
+			b.Fights += ob.Fights
+			b.Wins += ob.Wins
+			b.Draws += ob.Draws
+		}
+	}
+}
+
+// finish returns the report with its buckets sorted low to high
+func (t *balanceTally) finish() BalanceReport {
+	sorted := func(buckets map[int]*BalanceBucket) []BalanceBucket {
+		out := make([]BalanceBucket, 0, len(buckets))
+		for _, b := range buckets {
+			out = append(out, *b)
+		}
+		sort.Slice(out, func(i, j int) bool { return out[i].Low < out[j].Low })
+		return out
+	}
+	r := t.report
+	r.ByStatTotal = sorted(t.byTotal)
+	r.ByStatEdge = sorted(t.byEdge)
+	return r
+}
+
+// balanceBout plays one fresh singles fight between two roster fighters
+func balanceBout(fightID int, rules Ruleset, f1, f2 database.Fighter, seed int64) (FightState, bool) {
+	start := FightState{
+		Fighter1Health: STARTING_HEALTH,
+		Fighter2Health: STARTING_HEALTH,
+		CurrentRound:   1,
+		SimFighter1ID:  f1.ID,
+		SimFighter2ID:  f2.ID,
+	}
+	return playOut(fightID, rules, Lineup{Fighter1: f1, Fighter2: f2}, start, balanceSeedBase(seed))
+}
+
+// SimulateBalanceFights plays fights between random pairs drawn from the
+// roster. Every fighter opens fresh, with no blessings, curses or injuries.
+// The same roster, rules and seed always produce the same report.
+func SimulateBalanceFights(rules Ruleset, roster []database.Fighter, fights int, seed int64, bucketWidth int) (BalanceReport, error) {
+	if len(roster) < 2 {
+		return BalanceReport{}, fmt.Errorf("need at least 2 fighters to simulate (got %d)", len(roster))
+	}
+	if fights <= 0 {
+		return newBalanceTally(rules, bucketWidth).finish(), nil
+	}
+
+	workers := runtime.NumCPU()
+	if workers > fights {
+		workers = fights
+	}
+	tallies := make([]*balanceTally, workers)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		tallies[w] = newBalanceTally(rules, bucketWidth)
+		wg.Add(1)
+		go func(t *balanceTally, w int) {
+			defer wg.Done()
+			for i := w; i < fights; i += workers {
+				pick := utils.NewSeededRNG(balanceSeedBase(seed) + int64(i))
+				a := pick.Intn(len(roster))
+				b := pick.Intn(len(roster) - 1)
+				if b >= a {
+					b++
+				}
+				state, decided := balanceBout(i+1, rules, roster[a], roster[b], seed)
+				t.add(roster[a], roster[b], state, decided)
+			}
+		}(tallies[w], w)
+	}
+	wg.Wait()
+
+	for _, t := range tallies[1:] {
+		tallies[0].merge(t)
+	}
+	return tallies[0].finish(), nil
+}
+
+// SimulateBalanceWeeks runs whole tournament weeks starting on the Monday of
+// start: Monday to Friday cards are drawn and paired by the real generator,
+// and Saturday's round-robin groups are seeded from the week's winners
+// (playoffs are skipped). Records and deaths carry from fight to fight so the
+// roster evolves the way it would live, but nothing is written to the database.
+func (g *Generator) SimulateBalanceWeeks(rules Ruleset, roster []database.Fighter, weeks int, start time.Time, seed int64, bucketWidth int) (BalanceReport, error) {
+	if len(roster) < 2 {
+		return BalanceReport{}, fmt.Errorf("need at least 2 fighters to simulate (got %d)", len(roster))
+	}
+	tally := newBalanceTally(rules, bucketWidth)
+
+	fighters := make(map[int]*database.Fighter, len(roster))
+	order := make([]int, 0, len(roster))
+	for i := range roster {
+		f := roster[i]
+		fighters[f.ID] = &f
+		order = append(order, f.ID)
+	}
+	eligible := func() []database.Fighter {
+		var out []database.Fighter
+		for _, id := range order {
+			if f := fighters[id]; !f.IsDead || f.IsUndead {
+				out = append(out, *f)
+			}
+		}
+		return out
+	}
+
+	fightID := 0
+	play := func(card []database.Fight, wins map[int]int) {
+		for _, bout := range card {
+			f1, f2 := fighters[bout.Fighter1ID], fighters[bout.Fighter2ID]
+			// The card was drawn at the start of the day; anyone killed since sits it out
+			if (f1.IsDead && !f1.IsUndead) || (f2.IsDead && !f2.IsUndead) {
+				continue
+			}
+			fightID++
+			state, decided := balanceBout(fightID, rules, *f1, *f2, seed)
+			tally.add(*f1, *f2, state, decided)
+
+			if state.WinnerID != 0 {
+				wins[state.WinnerID]++
+			}
+			switch state.WinnerID {
+			case f1.ID:
+				f1.Wins++
+				f2.Losses++
+			case f2.ID:
+				f2.Wins++
+				f1.Losses++
+			default:
+				f1.Draws++
+				f2.Draws++
+			}
+			if state.DeathOccurred {
+				if state.WinnerID == f1.ID {
+					f2.IsDead = true
+				} else {
+					f1.IsDead = true
+				}
+			}
+		}
+	}
+
+	monday := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	monday = monday.AddDate(0, 0, -((int(monday.Weekday()) + 6) % 7))
+
+	for week := 0; week < weeks; week++ {
+		tournament := &database.Tournament{ID: week + 1, WeekNumber: week + 1}
+		weekWins := make(map[int]int)
+
+		for day := 0; day < 5; day++ {
+			date := monday.AddDate(0, 0, 7*week+day)
+			selected := g.SelectDailyFighters(eligible(), date)
+			card, err := g.GenerateFightSchedule(tournament, selected, date)
+			if err != nil {
+				return tally.finish(), fmt.Errorf("week %d day %d: %w", week+1, day+1, err)
+			}
+			before := tally.report.Fights
+			play(card, weekWins)
+			tally.report.Days++
+			log.Printf("Balance sim: week %d %s, %d fights", week+1, date.Weekday(), tally.report.Fights-before)
+		}
+
+		// Saturday: the week's winners, most wins first
+		saturday := monday.AddDate(0, 0, 7*week+5)
+		var entrants []database.Fighter
+		for _, f := range eligible() {
+			if weekWins[f.ID] > 0 {
+				entrants = append(entrants, f)
+			}
+		}
+		sort.Slice(entrants, func(i, j int) bool {
+			wi, wj := weekWins[entrants[i].ID], weekWins[entrants[j].ID]
+			if wi == wj {
+				return entrants[i].ID < entrants[j].ID
+			}
+			return wi > wj
+		})
+		card, err := g.GenerateRoundRobinGroups(tournament, entrants, saturday)
+		if err != nil {
+			log.Printf("Balance sim: week %d has no Saturday: %v", week+1, err)
+			continue
+		}
+		play(card, make(map[int]int))
+		tally.report.Days++
+	}
+	return tally.finish(), nil
+}
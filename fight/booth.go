@@ -0,0 +1,416 @@
+package fight
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"math/rand"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"text/template"
+	"time"
+
+	"spoodblort/database"
+)
+
+// The commentary booth: announcers, their lines and the combat action names
+// live in JSON files under commentary/. They are compiled into the binary and
+// can be swapped out at runtime by pointing SPOODBLORT_COMMENTARY_DIR at a
+// directory laid out the same way.
+//
+// actions.json holds the combat action names and death messages. Every other
+// file is one announcer:
+//
+//	{
+//	  "name": "Chud Puncherson",
+//	  "style": "enthusiastic",
+//	  "weight": 1,                    // how often they get the mic
+//	  "affinity": {"critical": 3},    // weight multiplier per action type
+//	  "lines": [
+//	    {"text": "{{.Attacker.Name}} is on a {{.Attacker.WinStreak}} fight tear!",
+//	     "weight": 25, "on": ["damage"], "when": {"attacker_win_streak": 3}}
+//	  ]
+//	}
+//
+// Lines are text/template strings rendered with commentaryData. A line with no
+// "on" list is said during ordinary exchanges (damage, critical and low_health
+// actions). A line is only eligible when every condition in "when" holds.
+// Lines default to weight 1, so context lines want a much larger weight to get
+// heard over an announcer's general banter.
+
+//go:embed commentary/*.json
+var embeddedCommentary embed.FS
+
+// exchangeTypes are the action types a line with no "on" list covers
+var exchangeTypes = []string{"damage", "critical", "low_health"}
+
+// Announcer represents a fictitious commentator
+type Announcer struct {
+	Name     string         `json:"name"`
+	Style    string         `json:"style"` // personality/style of commentary
+	Weight   int            `json:"weight"`
+	Affinity map[string]int `json:"affinity"`
+	Lines    []*boothLine   `json:"lines"`
+}
+
+// boothLine is one thing an announcer can say
+type boothLine struct {
+	Text   string         `json:"text"`
+	Weight int            `json:"weight"`
+	On     []string       `json:"on"`
+	When   lineConditions `json:"when"`
+	tmpl   *template.Template
+}
+
+// lineConditions must all hold for a line to be said. Zero values don't constrain.
+type lineConditions struct {
+	AttackerWinStreak int  `json:"attacker_win_streak"` // at least this many straight wins
+	VictimLossStreak  int  `json:"victim_loss_streak"`  // at least this many straight losses
+	AttackerKills     int  `json:"attacker_kills"`      // at least this many prior kills
+	VictimKills       int  `json:"victim_kills"`
+	MinDamage         int  `json:"min_damage"`
+	Rivalry           int  `json:"rivalry"` // rivalry score of at least this
+	Rematch           bool `json:"rematch"` // the pair have met before
+	GrudgeMatch       bool `json:"grudge_match"`
+	AttackerUndead    bool `json:"attacker_undead"`
+	VictimUndead      bool `json:"victim_undead"`
+	SaturdayFinal     bool `json:"saturday_final"`
+}
+
+// booth is a loaded set of commentary data
+type booth struct {
+	announcers    []*Announcer
+	combatActions []string
+	deathMessages []*template.Template
+}
+
+// BoothContext is what the booth knows about a fight before it starts: each
+// fighter's history and what's riding on it. A nil context gets general banter.
+type BoothContext struct {
+	Fighters      map[int]database.FighterHistory
+	HeadToHead    database.HeadToHead // the captains' record before this fight
+	Grudge        bool
+	SaturdayFinal bool
+}
+
+// commentaryFighter is one side of a line as templates see it
+type commentaryFighter struct {
+	database.FighterHistory
+	Name   string
+	Undead bool
+}
+
+// commentaryData is what a line is rendered with
+type commentaryData struct {
+	Attacker commentaryFighter
+	Victim   commentaryFighter
+	Damage   string
+	Round    int
+	Previous int // earlier meetings between the pair
+	Meeting  int // which meeting this is
+	// The pair's record from the attacker's side, and how heated it is
+	HeadToHead database.Rival
+	Rivalry    int
+}
+
+var commentaryFuncs = template.FuncMap{
+	"inc": func(n int) int { return n + 1 },
+}
+
+var (
+	boothOnce   sync.Once
+	activeBooth *booth
+)
+
+// currentBooth returns the commentary data, loading it on first use
+func currentBooth() *booth {
+	boothOnce.Do(func() {
+		if dir := os.Getenv("SPOODBLORT_COMMENTARY_DIR"); dir != "" {
+			b, err := loadBooth(os.DirFS(dir), ".")
+			if err == nil {
+				activeBooth = b
+				return
+			}
+			log.Printf("Commentary: failed to load %s, using built-in lines: %v", dir, err)
+		}
+		b, err := loadBooth(embeddedCommentary, "commentary")
+		if err != nil {
+			log.Printf("Commentary: failed to load built-in lines: %v", err)
+			b = &booth{}
+		}
+		activeBooth = b
+	})
+	return activeBooth
+}
+
+// loadBooth reads actions.json and every announcer file from dir
+func loadBooth(fsys fs.FS, dir string) (*booth, error) {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	b := &booth{}
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		if path.Base(file) == "actions.json" {
+			if err := b.loadActions(data); err != nil {
+				return nil, fmt.Errorf("%s: %w", file, err)
+			}
+			continue
+		}
+		announcer, err := parseAnnouncer(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		b.announcers = append(b.announcers, announcer)
+	}
+	if len(b.announcers) == 0 || len(b.combatActions) == 0 {
+		return nil, fmt.Errorf("no announcers or combat actions in %s", dir)
+	}
+	return b, nil
+}
+
+func (b *booth) loadActions(data []byte) error {
+	var actions struct {
+		CombatActions []string `json:"combat_actions"`
+		DeathMessages []string `json:"death_messages"`
+	}
+	if err := json.Unmarshal(data, &actions); err != nil {
+		return err
+	}
+	b.combatActions = actions.CombatActions
+	for _, text := range actions.DeathMessages {
+		tmpl, err := template.New("death").Funcs(commentaryFuncs).Parse(text)
+		if err != nil {
+			return err
+		}
+		b.deathMessages = append(b.deathMessages, tmpl)
+	}
+	return nil
+}
+
+func parseAnnouncer(data []byte) (*Announcer, error) {
+	var a Announcer
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, err
+	}
+	if a.Name == "" {
+		return nil, fmt.Errorf("announcer has no name")
+	}
+	if a.Weight <= 0 {
+		a.Weight = 1
+	}
+	for i, line := range a.Lines {
+		if line.Weight <= 0 {
+			line.Weight = 1
+		}
+		if len(line.On) == 0 {
+			line.On = exchangeTypes
+		}
+		tmpl, err := template.New(fmt.Sprintf("%s#%d", a.Name, i)).Funcs(commentaryFuncs).Parse(line.Text)
+		if err != nil {
+			return nil, err
+		}
+		line.tmpl = tmpl
+	}
+	return &a, nil
+}
+
+// combatAction picks the name of the move that just landed
+func (b *booth) combatAction(rng *rand.Rand) string {
+	if len(b.combatActions) == 0 {
+		return "HIT"
+	}
+	return b.combatActions[rng.Intn(len(b.combatActions))]
+}
+
+// deathMessage renders the announcement for a fighter being killed
+func (b *booth) deathMessage(rng *rand.Rand, data commentaryData) string {
+	if len(b.deathMessages) == 0 {
+		return fmt.Sprintf("FATALITY! %s is dead!", data.Victim.Name)
+	}
+	return render(b.deathMessages[rng.Intn(len(b.deathMessages))], data)
+}
+
+// comment picks an announcer and one of their lines for an action of the given
+// type. Announcers are weighted by their affinity for the action and only
+// considered when they have something eligible to say. Returns empty strings
+// when nobody has a line.
+func (b *booth) comment(rng *rand.Rand, actionType string, ctx *BoothContext, data commentaryData, damage int) (string, string) {
+	type candidate struct {
+		announcer *Announcer
+		lines     []*boothLine
+		weight    int
+	}
+	var candidates []candidate
+	total := 0
+	for _, a := range b.announcers {
+		var lines []*boothLine
+		for _, line := range a.Lines {
+			if line.says(actionType) && line.When.hold(ctx, data, damage) {
+				lines = append(lines, line)
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		weight := a.Weight
+		if mult, ok := a.Affinity[actionType]; ok && mult > 0 {
+			weight *= mult
+		}
+		candidates = append(candidates, candidate{a, lines, weight})
+		total += weight
+	}
+	if total == 0 {
+		return "", ""
+	}
+
+	pick := rng.Intn(total)
+	chosen := candidates[len(candidates)-1]
+	for _, c := range candidates {
+		if pick < c.weight {
+			chosen = c
+			break
+		}
+		pick -= c.weight
+	}
+
+	lineTotal := 0
+	for _, line := range chosen.lines {
+		lineTotal += line.Weight
+	}
+	pick = rng.Intn(lineTotal)
+	for _, line := range chosen.lines {
+		if pick < line.Weight {
+			return chosen.announcer.Name, render(line.tmpl, data)
+		}
+		pick -= line.Weight
+	}
+	return chosen.announcer.Name, render(chosen.lines[0].tmpl, data)
+}
+
+// says reports whether the line is meant for an action type
+func (l *boothLine) says(actionType string) bool {
+	for _, t := range l.On {
+		if t == actionType {
+			return true
+		}
+	}
+	return false
+}
+
+// hold reports whether every condition is met. Conditions about fighter
+// history never hold without a context.
+func (c lineConditions) hold(ctx *BoothContext, data commentaryData, damage int) bool {
+	if damage < c.MinDamage {
+		return false
+	}
+	if c.AttackerUndead && !data.Attacker.Undead {
+		return false
+	}
+	if c.VictimUndead && !data.Victim.Undead {
+		return false
+	}
+	historyNeeded := c.AttackerWinStreak > 0 || c.VictimLossStreak > 0 || c.AttackerKills > 0 ||
+		c.VictimKills > 0 || c.Rivalry > 0 || c.Rematch || c.GrudgeMatch || c.SaturdayFinal
+	if !historyNeeded {
+		return true
+	}
+	if ctx == nil {
+		return false
+	}
+	return data.Attacker.WinStreak >= c.AttackerWinStreak &&
+		data.Victim.LossStreak >= c.VictimLossStreak &&
+		data.Attacker.Kills >= c.AttackerKills &&
+		data.Victim.Kills >= c.VictimKills &&
+		data.Rivalry >= c.Rivalry &&
+		(!c.Rematch || data.Previous > 0) &&
+		(!c.GrudgeMatch || ctx.Grudge) &&
+		(!c.SaturdayFinal || ctx.SaturdayFinal)
+}
+
+// render executes a line, falling back to its raw text if it can't be rendered
+func render(tmpl *template.Template, data commentaryData) string {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		log.Printf("Commentary: failed to render %q: %v", tmpl.Name(), err)
+		return tmpl.Root.String()
+	}
+	return buf.String()
+}
+
+// commentaryData builds what a line about attacker hitting victim is rendered with
+func (ctx *BoothContext) commentaryData(attacker, victim database.Fighter, damage, round int) commentaryData {
+	data := commentaryData{
+		Attacker: ctx.fighter(attacker),
+		Victim:   ctx.fighter(victim),
+		Damage:   formatNumber(damage),
+		Round:    round,
+	}
+	// Head-to-head only covers the captains, so tag partners get no record
+	if ctx != nil && ctx.isPair(attacker.ID, victim.ID) {
+		data.Previous = ctx.HeadToHead.Meetings
+		data.HeadToHead = ctx.HeadToHead.From(attacker.ID)
+		data.Rivalry = ctx.HeadToHead.Score
+	}
+	data.Meeting = data.Previous + 1
+	return data
+}
+
+// isPair reports whether two fighters are the pair the head-to-head record is about
+func (ctx *BoothContext) isPair(a, b int) bool {
+	h := ctx.HeadToHead
+	return (a == h.FighterA && b == h.FighterB) || (a == h.FighterB && b == h.FighterA)
+}
+
+func (ctx *BoothContext) fighter(f database.Fighter) commentaryFighter {
+	cf := commentaryFighter{Name: f.Name, Undead: f.IsUndead}
+	if ctx != nil {
+		cf.FighterHistory = ctx.Fighters[f.ID]
+	}
+	return cf
+}
+
+// boothContext looks up the history the booth can talk about for a fight's
+// lineup. Lookups that fail are left out rather than failing the fight.
+func (e *Engine) boothContext(fight database.Fight, lineup Lineup) *BoothContext {
+	ctx := &BoothContext{
+		Fighters:      make(map[int]database.FighterHistory),
+		Grudge:        fight.Grudge,
+		SaturdayFinal: isSaturdayFinal(fight.ScheduledTime, e.clock().Location()),
+	}
+	for _, f := range []database.Fighter{lineup.Fighter1, lineup.Fighter2, lineup.Partner1, lineup.Partner2} {
+		if f.ID == 0 {
+			continue
+		}
+		history, err := e.repo.GetFighterHistory(f.ID, fight.ScheduledTime)
+		if err != nil {
+			log.Printf("Commentary: failed to load history for fighter %d: %v", f.ID, err)
+			continue
+		}
+		ctx.Fighters[f.ID] = *history
+	}
+	if h2h, err := e.repo.GetHeadToHead(fight.Fighter1ID, fight.Fighter2ID, fight.ScheduledTime); err == nil {
+		ctx.HeadToHead = *h2h
+	} else {
+		log.Printf("Commentary: failed to load head-to-head for fight %d: %v", fight.ID, err)
+	}
+	return ctx
+}
+
+// isSaturdayFinal reports whether a fight is the 23:30 Saturday championship
+// final in the league timezone
+func isSaturdayFinal(scheduled time.Time, league *time.Location) bool {
+	t := scheduled.In(league)
+	return t.Weekday() == time.Saturday && t.Hour() == 23 && t.Minute() >= 30
+}
@@ -0,0 +1,98 @@
+package fight
+
+import (
+	"fmt"
+	"math"
+
+	"spoodblort/database"
+)
+
+// CashOutMarginPct is the house cut taken from a bet's live value when it's
+// cashed out. It's wider than the in-play margin since the user picks the moment.
+const CashOutMarginPct = 10
+
+// CashOutOffer is what a pending bet on a live fight can be settled for now
+type CashOutOffer struct {
+	BetID   int     `json:"bet_id"`
+	FightID int     `json:"fight_id"`
+	Tick    int     `json:"tick"`
+	WinP    float64 `json:"win_probability"` // live chance the bet's fighter wins
+	Return  int     `json:"return"`          // what the bet pays if it wins
+	Amount  int     `json:"amount"`          // credits offered to settle now, 0 if not offered
+}
+
+// betReturn is what a pending fixed-odds bet pays if its fighter wins: the
+// locked price for in-play bets, the flat 2x for pre-fight ones. The MVP
+// bonus isn't priced in, so MVP backers give it up by cashing out.
+func betReturn(bet database.Bet) int {
+	if bet.Odds > 0 {
+		return int(float64(bet.Amount) * bet.Odds)
+	}
+	return bet.Amount * 2
+}
+
+// QuoteCashOut prices a user's pending bet off its fight's live state. Bets
+// in parimutuel pools aren't offered a cash-out since their return isn't
+// known until the pool closes.
+func (e *Engine) QuoteCashOut(userID, betID int) (*CashOutOffer, error) {
+	bet, err := e.repo.GetBet(betID)
+	if err != nil || bet.UserID != userID || bet.Status != "pending" {
+		return nil, database.ErrBetNotOpen
+	}
+	mode, err := e.repo.SettlementModeForFight(bet.FightID)
+	if err != nil {
+		return nil, err
+	}
+	if mode == database.SettlementParimutuel {
+		return nil, fmt.Errorf("bets in a parimutuel pool can't be cashed out")
+	}
+
+	quote, err := e.QuoteInPlay(bet.FightID)
+	if err != nil {
+		return nil, err
+	}
+	offer := &CashOutOffer{BetID: bet.ID, FightID: bet.FightID, Tick: quote.Tick, Return: betReturn(*bet)}
+	switch bet.FighterID {
+	case quote.Fighter1ID:
+		offer.WinP = quote.Fighter1Win
+	case quote.Fighter2ID:
+		offer.WinP = quote.Fighter2Win
+	default:
+		return nil, fmt.Errorf("fighter %d is not in fight %d", bet.FighterID, bet.FightID)
+	}
+	offer.Amount = int(math.Floor(float64(offer.Return) * offer.WinP * float64(100-CashOutMarginPct) / 100))
+	return offer, nil
+}
+
+// CashOutBet settles a pending bet on a live fight at the offer for the tick
+// the fight is on when the request lands, re-quoting if the fight moves on
+func (e *Engine) CashOutBet(userID, betID int) (*CashOutOffer, error) {
+	for attempt := 0; attempt < inPlayQuoteRetries; attempt++ {
+		offer, err := e.QuoteCashOut(userID, betID)
+		if err != nil {
+			return nil, err
+		}
+		if offer.Amount <= 0 {
+			return nil, fmt.Errorf("no cash-out offer on that bet right now")
+		}
+
+		// Hold the live state still while the bet is settled so the offer matches the tick
+		e.live.mu.Lock()
+		lf, ok := e.live.fights[offer.FightID]
+		if !ok || !inPlayOpen(lf.state) {
+			e.live.mu.Unlock()
+			return nil, ErrInPlayClosed
+		}
+		if lf.state.TickNumber != offer.Tick {
+			e.live.mu.Unlock()
+			continue // the fight moved on while we were pricing; re-quote
+		}
+		err = e.repo.CashOutBet(userID, betID, offer.Amount, offer.Tick)
+		e.live.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		return offer, nil
+	}
+	return nil, fmt.Errorf("the fight is moving too fast to price, try again")
+}
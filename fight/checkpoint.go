@@ -0,0 +1,69 @@
+package fight
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"spoodblort/database"
+)
+
+// saveCheckpoint persists a live fight's state so a restart can resume from it
+func (e *Engine) saveCheckpoint(fightID int, rules Ruleset, state *FightState) {
+	payload, err := json.Marshal(state)
+	if err != nil {
+		log.Printf("Fight %d: failed to encode checkpoint: %v", fightID, err)
+		return
+	}
+	err = e.repo.SaveFightCheckpoint(database.FightCheckpoint{
+		FightID:     fightID,
+		Tick:        state.TickNumber,
+		Round:       state.CurrentRound,
+		Ruleset:     rules.Name(),
+		AlgoVersion: rules.Version(),
+		StateJSON:   string(payload),
+	})
+	if err != nil {
+		log.Printf("Fight %d: failed to save checkpoint at tick %d: %v", fightID, state.TickNumber, err)
+	}
+}
+
+// resumeState returns the checkpointed state a live fight should pick up from,
+// or the opening state when there is no usable checkpoint. A checkpoint is only
+// trusted if it was taken under the same rules, with the same fighters in the
+// same lanes, and no later than the tick the fight should be on by now.
+func (e *Engine) resumeState(fightID int, rules Ruleset, opening *FightState, elapsedTicks int) *FightState {
+	cp, err := e.repo.GetFightCheckpoint(fightID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Fight %d: failed to load checkpoint: %v", fightID, err)
+		}
+		return opening
+	}
+	if cp.Ruleset != rules.Name() || cp.AlgoVersion != rules.Version() {
+		log.Printf("Fight %d: checkpoint was taken under %s %s, replaying from the start", fightID, cp.Ruleset, cp.AlgoVersion)
+		return opening
+	}
+
+	var state FightState
+	if err := json.Unmarshal([]byte(cp.StateJSON), &state); err != nil {
+		log.Printf("Fight %d: unreadable checkpoint: %v", fightID, err)
+		return opening
+	}
+	if state.SimFighter1ID != opening.SimFighter1ID || state.SimFighter2ID != opening.SimFighter2ID ||
+		state.TickNumber > elapsedTicks || state.IsComplete {
+		log.Printf("Fight %d: checkpoint at tick %d doesn't fit this fight, replaying from the start", fightID, state.TickNumber)
+		return opening
+	}
+
+	log.Printf("Fight %d: resuming from checkpoint at tick %d", fightID, state.TickNumber)
+	return &state
+}
+
+// clearCheckpoint drops a fight's checkpoint once the fight is settled
+func (e *Engine) clearCheckpoint(fightID int) {
+	if err := e.repo.DeleteFightCheckpoint(fightID); err != nil {
+		log.Printf("Fight %d: failed to clear checkpoint: %v", fightID, err)
+	}
+}
@@ -3,11 +3,10 @@ package fight
 import (
 	"fmt"
 	"log"
-	"math"
-	"math/rand"
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"spoodblort/database"
@@ -16,21 +15,21 @@ import (
 	"spoodblort/wiki"
 )
 
-// Fight engine constants - adjust these to tune gameplay
+// Fight engine constants. Combat balance (damage, crits, death) lives in the
+// versioned ruleset tunings in ruleset.go so old fights keep replaying exactly.
 const (
 	TICK_DURATION_SECONDS = 3
-	DEATH_CHANCE          = 100000 // 1 in 1000 chance per damage tick
-	CRIT_CHANCE           = 2      // 1 in 2 chance per tick for the losing fighter to attempt a crit
-	STARTING_HEALTH       = 100000 // Increased from 100k for longer fights
-	MIN_DAMAGE            = 10
-	MAX_DAMAGE            = 1000 // Reduced from 5000 to balance simultaneous combat
+	STARTING_HEALTH       = 100000                            // Increased from 100k for longer fights
+	MAX_FIGHT_TICKS       = (30 * 60) / TICK_DURATION_SECONDS // 30 minutes worth of ticks
 )
 
 // Broadcaster interface for live fight updates
 type Broadcaster interface {
 	BroadcastAction(fightID int, action LiveAction)
 	BroadcastViewerCount(fightID int)
-	BroadcastRoundClapSummary(fightID, round int)
+	// RoundClapSummary builds the crowd summary for a clapping round that just
+	// ended, if there is one, and clears that round's totals.
+	RoundClapSummary(fightID, round int) (LiveAction, bool)
 	// ConsumeClapHealth returns the health deltas from aggregated claps for the two fighters
 	// and resets the internal counters for this fight and these fighters.
 	ConsumeClapHealth(fightID int, fighter1ID int, fighter2ID int) (int, int)
@@ -46,10 +45,16 @@ type FightState struct {
 	IsComplete     bool
 	WinnerID       int
 	DeathOccurred  bool
+	// Running totals the prop markets settle on
+	TotalDamage int // damage landed by both sides, crits included
+	Crits       int // comeback crits landed
+	Frenzies    int // undead frenzies triggered
 	// Simulation orientation bookkeeping: which DB fighter IDs correspond to the
 	// Fighter1Health/Fighter2Health lanes used during simulation.
 	SimFighter1ID int
 	SimFighter2ID int
+	// Tag-team bookkeeping; zero value in singles
+	Tag TagState
 }
 
 type Engine struct {
@@ -62,6 +67,16 @@ type Engine struct {
 	// Fight logging
 	fightLogs     map[int]*os.File // Track open log files for each fight
 	fightLogMutex sync.Mutex
+	// Odds pricing runs in flight: fightID -> another run requested meanwhile
+	pricing      map[int]bool
+	pricingMutex sync.Mutex
+	pricingSweep atomic.Bool
+	// Published state of fights running live, for in-play betting
+	live liveRegistry
+	// Battle royales running live (guarded by simulationsMutex) and their cached prices
+	liveRoyales  map[int]bool
+	royaleQuotes map[int]*RoyaleQuote
+	quotesMutex  sync.Mutex
 }
 
 func NewEngine(repo *database.Repository) *Engine {
@@ -77,6 +92,10 @@ func NewEngine(repo *database.Repository) *Engine {
 		discordNotifier: discord.NewNotifier(repo),
 		liveSimulations: make(map[int]bool),
 		fightLogs:       make(map[int]*os.File),
+		pricing:         make(map[int]bool),
+		live:            liveRegistry{fights: make(map[int]*liveFight)},
+		liveRoyales:     make(map[int]bool),
+		royaleQuotes:    make(map[int]*RoyaleQuote),
 	}
 
 	// Only initialize Role Manager if not disabled
@@ -87,6 +106,11 @@ func NewEngine(repo *database.Repository) *Engine {
 	return engine
 }
 
+// clock is the league clock the engine paces and dates fights by
+func (e *Engine) clock() utils.Clock {
+	return e.repo.Clock()
+}
+
 // SetBroadcaster allows setting a live broadcaster for the engine
 func (e *Engine) SetBroadcaster(broadcaster Broadcaster) {
 	e.broadcaster = broadcaster
@@ -212,50 +236,75 @@ func sanitizeName(name string) string {
 	return result
 }
 
-// SimulateFightFromStart runs a complete fight simulation from the beginning
+// SimulateFightFromStart runs a complete fight simulation from the beginning.
+// Recorded inputs (opening snapshot, clap healing) are replayed when present;
+// otherwise the opening snapshot is taken now and recorded. The fight's event
+// history is filled in for any ticks that weren't already recorded live.
 func (e *Engine) SimulateFightFromStart(fight database.Fight, fighter1, fighter2 database.Fighter) (*FightState, error) {
 	log.Printf("Starting fight simulation: %s vs %s", fighter1.Name, fighter2.Name)
+	return e.simulateFromStart(fight, fighter1, fighter2, true), nil
+}
 
-	// Orientation swap disabled
+// ReplayFight re-runs a fight from its recorded inputs without writing anything
+func (e *Engine) ReplayFight(fight database.Fight) (*FightState, error) {
+	setup, err := e.replaySetup(fight)
+	if err != nil {
+		return nil, err
+	}
+	return e.runFight(fight.ID, e.RulesetForFight(fight), setup, nil), nil
+}
+
+// replaySetup loads a fight's opening setup for replay without recording anything
+func (e *Engine) replaySetup(fight database.Fight) (*fightSetup, error) {
+	fighter1, err := e.repo.GetFighter(fight.Fighter1ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fighter1: %w", err)
+	}
+	fighter2, err := e.repo.GetFighter(fight.Fighter2ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fighter2: %w", err)
+	}
+	return e.loadFightSetup(fight, *fighter1, *fighter2, fight.ScheduledTime, false), nil
+}
 
-	// Apply stat effects to fighters for this fight's date
-	modifiedFighter1 := e.applyStatEffectsToFighter(fighter1, fight.ScheduledTime)
-	modifiedFighter2 := e.applyStatEffectsToFighter(fighter2, fight.ScheduledTime)
+func (e *Engine) simulateFromStart(fight database.Fight, fighter1, fighter2 database.Fighter, record bool) *FightState {
+	rules := e.RulesetForFight(fight)
 
-	// Calculate starting health (keeping original health calculation for now)
-	fighter1Health := e.calculateFighterHealthForDate(fighter1.ID, fight.ScheduledTime)
-	fighter2Health := e.calculateFighterHealthForDate(fighter2.ID, fight.ScheduledTime)
+	// Opening stats come from the recorded snapshot, or from effects applied on the fight's date
+	setup := e.loadFightSetup(fight, fighter1, fighter2, fight.ScheduledTime, record)
 
-	state := &FightState{
-		Fighter1Health: fighter1Health,
-		Fighter2Health: fighter2Health,
-		TickNumber:     0,
-		CurrentRound:   1,
+	var rec *eventRecorder
+	var emit func(LiveAction)
+	if record {
+		rec = newEventRecorder(fight.ID, setup)
+		emit = rec.record
+		setup.booth = e.boothContext(fight, setup.lineup())
 	}
 
-	maxTicks := (30 * 60) / TICK_DURATION_SECONDS // 30 minutes worth of ticks
+	state := e.runFight(fight.ID, rules, setup, emit)
 
-	for tick := 1; tick <= maxTicks && !state.IsComplete; tick++ {
-		e.simulateTick(fight.ID, tick, modifiedFighter1, modifiedFighter2, state)
+	if rec != nil {
+		e.flushEvents(rec)
+	}
+	return state
+}
 
-		if tick%6 == 0 { // Every minute, check for round progression
-			state.CurrentRound++
-		}
+// runFight plays a fight from its opening setup to the final bell under rules
+func (e *Engine) runFight(fightID int, rules Ruleset, setup *fightSetup, emit func(LiveAction)) *FightState {
+	state := setup.openingState()
+	for state.TickNumber < MAX_FIGHT_TICKS && !state.IsComplete {
+		heal1, heal2 := setup.clapHeal(state.TickNumber + 1)
+		e.playTick(fightID, rules, setup, state, heal1, heal2, emit)
 	}
 
-	// If fight went the distance, determine winner by remaining health
+	// If fight went the distance, let the judges decide
 	if !state.IsComplete {
-		if state.Fighter1Health > state.Fighter2Health {
-			state.WinnerID = fighter1.ID
-		} else if state.Fighter2Health > state.Fighter1Health {
-			state.WinnerID = fighter2.ID
+		rules.Decide(state)
+		if emit != nil {
+			log.Printf("Fight went to judges decision")
 		}
-		// If exactly equal health, it's a draw (WinnerID stays 0)
-		state.IsComplete = true
-		log.Printf("Fight went to judges decision")
 	}
-
-	return state, nil
+	return state
 }
 
 // CatchUpSimulation simulates a fight from start to current time for recovery
@@ -266,30 +315,14 @@ func (e *Engine) CatchUpSimulation(fight database.Fight, fighter1, fighter2 data
 
 	log.Printf("Catching up fight simulation: %d ticks elapsed", targetTick)
 
-	// Orientation swap disabled
-
-	// Apply stat effects to fighters for this fight's date
-	modifiedFighter1 := e.applyStatEffectsToFighter(fighter1, fight.ScheduledTime)
-	modifiedFighter2 := e.applyStatEffectsToFighter(fighter2, fight.ScheduledTime)
-
-	// Calculate starting health with applied effects from the fight's scheduled date
-	fighter1Health := e.calculateFighterHealthForDate(fighter1.ID, fight.ScheduledTime)
-	fighter2Health := e.calculateFighterHealthForDate(fighter2.ID, fight.ScheduledTime)
-
-	state := &FightState{
-		Fighter1Health: fighter1Health,
-		Fighter2Health: fighter2Health,
-		TickNumber:     0,
-		CurrentRound:   1,
-	}
+	rules := e.RulesetForFight(fight)
+	setup := e.loadFightSetup(fight, fighter1, fighter2, fight.ScheduledTime, false)
+	state := setup.openingState()
 
 	// Simulate all elapsed ticks at once
-	for tick := 1; tick <= targetTick && !state.IsComplete; tick++ {
-		e.simulateTick(fight.ID, tick, modifiedFighter1, modifiedFighter2, state)
-
-		if tick%6 == 0 {
-			state.CurrentRound++
-		}
+	for state.TickNumber < targetTick && !state.IsComplete {
+		heal1, heal2 := setup.clapHeal(state.TickNumber + 1)
+		e.playTick(fight.ID, rules, setup, state, heal1, heal2, nil)
 	}
 
 	return state, nil
@@ -310,43 +343,34 @@ func (e *Engine) StartLiveFightSimulation(fight database.Fight, fighter1, fighte
 	log.Printf("Starting live simulation for fight %d: %s vs %s", fight.ID, fighter1.Name, fighter2.Name)
 
 	// Initialize fight log
-	// Orientation swap disabled
-
 	err := e.initFightLog(fight, fighter1, fighter2)
 	if err != nil {
 		log.Printf("Failed to initialize fight log: %v", err)
 		// Continue without logging
 	}
 
+	rules := e.RulesetForFight(fight)
+
 	// Calculate how many ticks have already passed since fight started
-	elapsed := time.Since(fight.ScheduledTime)
+	elapsed := e.clock().Since(fight.ScheduledTime)
 	elapsedTicks := int(elapsed.Seconds()) / TICK_DURATION_SECONDS
 
-	// Apply stat effects to fighters for today (live fights use today's effects)
-	centralTime, _ := time.LoadLocation("America/Chicago")
-	now := time.Now().In(centralTime)
-	modifiedFighter1 := e.applyStatEffectsToFighter(fighter1, now)
-	modifiedFighter2 := e.applyStatEffectsToFighter(fighter2, now)
-
-	// Calculate starting health with applied effects from today
-	fighter1Health := e.calculateFighterHealthForDate(fighter1.ID, now)
-	fighter2Health := e.calculateFighterHealthForDate(fighter2.ID, now)
-
-	// Create initial state
-	state := &FightState{
-		Fighter1Health: fighter1Health,
-		Fighter2Health: fighter2Health,
-		TickNumber:     0,
-		CurrentRound:   1,
-	}
-
-	// Catch up to current time without broadcasting (for consistency)
-	for tick := 1; tick <= elapsedTicks && !state.IsComplete; tick++ {
-		e.simulateTickQuiet(fight.ID, tick, modifiedFighter1, modifiedFighter2, state)
-		if tick%6 == 0 {
-			state.CurrentRound++
-		}
+	// Live fights use today's effects, snapshotted at the opening bell so a
+	// restart (or a later replay) starts from the same stats
+	setup := e.loadFightSetup(fight, fighter1, fighter2, e.clock().Now(), true)
+	setup.booth = e.boothContext(fight, setup.lineup())
+	state := e.resumeState(fight.ID, rules, setup.openingState(), elapsedTicks)
+
+	// Catch up from the last checkpoint to current time without broadcasting,
+	// replaying any recorded crowd healing and filling in event history for
+	// ticks missed while we were down
+	rec := newEventRecorder(fight.ID, setup)
+	for state.TickNumber < elapsedTicks && !state.IsComplete {
+		heal1, heal2 := setup.clapHeal(state.TickNumber + 1)
+		e.playTick(fight.ID, rules, setup, state, heal1, heal2, rec.record)
 	}
+	e.flushEvents(rec)
+	e.saveCheckpoint(fight.ID, rules, state)
 
 	// If fight is already complete, finish it
 	if state.IsComplete {
@@ -356,22 +380,24 @@ func (e *Engine) StartLiveFightSimulation(fight database.Fight, fighter1, fighte
 		return e.CompleteFight(fight, state)
 	}
 
-	// Start real-time broadcasting from current state
-	go e.broadcastLiveFight(fight, modifiedFighter1, modifiedFighter2, state)
+	// Start real-time broadcasting from current state, open for in-play bets
+	e.publishLiveState(fight.ID, rules, setup, state)
+	go e.broadcastLiveFight(fight, rules, setup, state, rec)
 
 	return nil
 }
 
 // broadcastLiveFight runs the live fight simulation in a goroutine
-func (e *Engine) broadcastLiveFight(fight database.Fight, fighter1, fighter2 database.Fighter, state *FightState) {
+func (e *Engine) broadcastLiveFight(fight database.Fight, rules Ruleset, setup *fightSetup, state *FightState, rec *eventRecorder) {
 	defer func() {
+		e.retireLiveState(fight.ID)
 		e.simulationsMutex.Lock()
 		delete(e.liveSimulations, fight.ID)
 		e.simulationsMutex.Unlock()
 		log.Printf("Live simulation ended for fight %d", fight.ID)
 	}()
 
-	ticker := time.NewTicker(TICK_DURATION_SECONDS * time.Second)
+	ticker := e.clock().NewTicker(TICK_DURATION_SECONDS * time.Second)
 	defer ticker.Stop()
 
 	// Broadcast initial viewer count
@@ -379,34 +405,32 @@ func (e *Engine) broadcastLiveFight(fight database.Fight, fighter1, fighter2 dat
 		e.broadcaster.BroadcastViewerCount(fight.ID)
 	}
 
-	maxTicks := (30 * 60) / TICK_DURATION_SECONDS // 30 minutes worth of ticks
+	emit := e.liveEmitter(fight.ID, rec)
 
-	for !state.IsComplete && state.TickNumber < maxTicks {
+	for !state.IsComplete && state.TickNumber < MAX_FIGHT_TICKS {
 		select {
 		case <-ticker.C:
-			state.TickNumber++
-
-			// Simulate this tick with broadcasting
-			e.simulateTick(fight.ID, state.TickNumber, fighter1, fighter2, state)
-
-			// Check for round progression
-			if state.TickNumber%6 == 0 {
-				state.CurrentRound++
-				// Broadcast round change
-				if e.broadcaster != nil {
-					roundAction := GenerateRoundAction(state.CurrentRound, state.Fighter1Health, state.Fighter2Health)
-					e.broadcaster.BroadcastAction(fight.ID, roundAction)
-
-					// Log the round action
-					e.logFightAction(fight.ID, roundAction.Action)
-					if roundAction.Commentary != "" {
-						e.logFightAction(fight.ID, fmt.Sprintf("%s: \"%s\"", roundAction.Announcer, roundAction.Commentary))
-					}
-
-					// Broadcast clap summary for the previous round if it was a clapping round
-					e.broadcaster.BroadcastRoundClapSummary(fight.ID, state.CurrentRound)
+			// Crowd healing accumulated since the last tick, recorded so replays see it too
+			heal1, heal2 := 0, 0
+			if e.broadcaster != nil {
+				heal1, heal2 = e.broadcaster.ConsumeClapHealth(fight.ID, setup.fighter1.ID, setup.fighter2.ID)
+			}
+			if heal1 > 0 || heal2 > 0 {
+				e.recordClapHeal(fight.ID, state.TickNumber+1, setup, heal1, heal2)
+			}
+
+			newRound := e.playTick(fight.ID, rules, setup, state, heal1, heal2, emit)
+			e.publishLiveState(fight.ID, rules, setup, state)
+
+			// Announce the clap summary for the previous round if it was a clapping round
+			if newRound && e.broadcaster != nil {
+				if summary, ok := e.broadcaster.RoundClapSummary(fight.ID, state.CurrentRound); ok {
+					summary.TickNumber = state.TickNumber
+					emit(summary)
 				}
 			}
+			e.flushEvents(rec)
+			e.saveCheckpoint(fight.ID, rules, state)
 
 			// If fight is complete, finish it
 			if state.IsComplete {
@@ -419,542 +443,89 @@ func (e *Engine) broadcastLiveFight(fight database.Fight, fighter1, fighter2 dat
 
 	// If fight went the distance, complete it
 	if !state.IsComplete {
-		if state.Fighter1Health > state.Fighter2Health {
-			state.WinnerID = fighter1.ID
-		} else if state.Fighter2Health > state.Fighter1Health {
-			state.WinnerID = fighter2.ID
-		}
-		state.IsComplete = true
+		rules.Decide(state)
 		log.Printf("Live fight %d went to judges decision", fight.ID)
 		e.CompleteFight(fight, state)
 	}
 }
 
-// simulateTick runs one 10-second combat tick
-func (e *Engine) simulateTick(fightID, tickNumber int, fighter1, fighter2 database.Fighter, state *FightState) {
-	seed := utils.FightTickSeed(fightID, tickNumber)
-	rng := utils.NewSeededRNG(seed)
-
-	// Determine advantage using a random stat and coinflip aggregation
-	fighter1Advantage := e.determineStatBasedAdvantage(fighter1, fighter2, rng)
-
-	// Undead frenzy (25% chance). If frenzied, zero one stat and apply 2x/3x/4x multiplier to outgoing damage.
-	frenzy1 := false
-	frenzy2 := false
-	frenzy1Mult := 1
-	frenzy2Mult := 1
-	frenzy1Zero := ""
-	frenzy2Zero := ""
-	if fighter1.IsUndead && rng.Intn(4) == 0 {
-		frenzy1 = true
-		switch rng.Intn(4) {
-		case 0:
-			fighter1.Strength, frenzy1Zero = 0, "strength"
-		case 1:
-			fighter1.Speed, frenzy1Zero = 0, "speed"
-		case 2:
-			fighter1.Endurance, frenzy1Zero = 0, "endurance"
-		default:
-			fighter1.Technique, frenzy1Zero = 0, "technique"
-		}
-		switch rng.Intn(3) {
-		case 0:
-			frenzy1Mult = 2
-		case 1:
-			frenzy1Mult = 3
-		default:
-			frenzy1Mult = 4
-		}
-		fighter1Advantage = e.determineStatBasedAdvantage(fighter1, fighter2, rng)
-	}
-	if fighter2.IsUndead && rng.Intn(4) == 0 {
-		frenzy2 = true
-		switch rng.Intn(4) {
-		case 0:
-			fighter2.Strength, frenzy2Zero = 0, "strength"
-		case 1:
-			fighter2.Speed, frenzy2Zero = 0, "speed"
-		case 2:
-			fighter2.Endurance, frenzy2Zero = 0, "endurance"
-		default:
-			fighter2.Technique, frenzy2Zero = 0, "technique"
-		}
-		switch rng.Intn(3) {
-		case 0:
-			frenzy2Mult = 2
-		case 1:
-			frenzy2Mult = 3
-		default:
-			frenzy2Mult = 4
-		}
-		fighter1Advantage = e.determineStatBasedAdvantage(fighter1, fighter2, rng)
-	}
-
-	// Calculate base damage for both fighters (simultaneous combat)
-	baseDamage1 := e.calculateDamage(fighter1, rng)
-	baseDamage2 := e.calculateDamage(fighter2, rng)
-	if frenzy2 {
-		baseDamage1 *= frenzy2Mult
+// RulesetForFight resolves the ruleset a fight runs under: the fight's own
+// choice first, then its tournament's, then the default. Fights that already
+// carry an algorithm version get exactly that version back.
+func (e *Engine) RulesetForFight(fight database.Fight) Ruleset {
+	name := fight.Ruleset
+	if fight.IsTagTeam() {
+		name = TagTeamRulesetName
 	}
-	if frenzy1 {
-		baseDamage2 *= frenzy1Mult
-	}
-
-	var damage1, damage2 int
-	if fighter1Advantage {
-		// Fighter1 wins this exchange - gets full damage, Fighter2 gets reduced damage
-		damage1 = 0
-		damage2 = baseDamage1 + (baseDamage1 / 2) // Winner gets 150% damage
-		// Fighter1 still takes some damage back but reduced
-		damage1 = baseDamage2 / 3 // Loser deals 33% damage back
-	} else {
-		// Fighter2 wins this exchange - gets full damage, Fighter1 gets reduced damage
-		damage2 = 0
-		damage1 = baseDamage2 + (baseDamage2 / 2) // Winner gets 150% damage
-		// Fighter2 still takes some damage back but reduced
-		damage2 = baseDamage1 / 3 // Loser deals 33% damage back
-	}
-
-	// Apply damage
-	state.Fighter1Health -= damage1
-	state.Fighter2Health -= damage2
-	state.LastDamage1 = damage1
-	state.LastDamage2 = damage2
-	state.TickNumber = tickNumber
-
-	// Apply aggregated clap healing after damage is applied for this tick
-	if e.broadcaster != nil {
-		delta1, delta2 := e.broadcaster.ConsumeClapHealth(fightID, fighter1.ID, fighter2.ID)
-		if delta1 > 0 {
-			healed := state.Fighter1Health + delta1
-			if healed > STARTING_HEALTH {
-				healed = STARTING_HEALTH
-			}
-			state.Fighter1Health = healed
-		}
-		if delta2 > 0 {
-			healed := state.Fighter2Health + delta2
-			if healed > STARTING_HEALTH {
-				healed = STARTING_HEALTH
-			}
-			state.Fighter2Health = healed
-		}
-	}
-
-	// Generate and broadcast live action if broadcaster is available
-	if e.broadcaster != nil {
-		action := GenerateLiveAction(fightID, tickNumber, fighter1, fighter2, damage1, damage2, state.Fighter1Health, state.Fighter2Health, state.CurrentRound)
-		if frenzy1 {
-			action.Frenzy1, action.Frenzy1Mult, action.Frenzy1Zero = true, frenzy1Mult, frenzy1Zero
-		}
-		if frenzy2 {
-			action.Frenzy2, action.Frenzy2Mult, action.Frenzy2Zero = true, frenzy2Mult, frenzy2Zero
-		}
-		e.broadcaster.BroadcastAction(fightID, action)
-
-		// Log the action to file
-		e.logFightAction(fightID, action.Action)
-		if action.Commentary != "" {
-			e.logFightAction(fightID, fmt.Sprintf("%s: \"%s\"", action.Announcer, action.Commentary))
+	if name == "" {
+		if tournament, err := e.repo.GetTournament(fight.TournamentID); err == nil {
+			name = tournament.Ruleset
 		}
 	}
-
-	// Comeback Critical: only if someone is losing by >= 3000 health
-	var critAttacker *database.Fighter
-	healthDiff := state.Fighter2Health - state.Fighter1Health // positive means fighter1 is behind
-	if healthDiff >= 3000 {
-		critAttacker = &fighter1
-	} else if healthDiff <= -3000 {
-		critAttacker = &fighter2
-	}
-
-	if critAttacker != nil && rng.Intn(CRIT_CHANCE) == 0 {
-		critDmg := e.calculateCritDamage(rng)
-		if critDmg > 0 {
-			if critAttacker == &fighter1 {
-				// Fighter1 crits Fighter2
-				state.Fighter2Health -= critDmg
-				state.LastDamage2 += critDmg
-				// Lifesteal: attacker recovers half the crit damage (capped)
-				if !fighter2.IsUndead {
-					heal := critDmg / 2
-					if heal > 0 {
-						healed := state.Fighter1Health + heal
-						if healed > STARTING_HEALTH {
-							healed = STARTING_HEALTH
-						}
-						state.Fighter1Health = healed
-					}
-				}
-				if e.broadcaster != nil {
-					critAction := LiveAction{
-						Type:       "critical",
-						Action:     fmt.Sprintf("COMEBACK CRIT! %s detonates %s for %s bonus damage!", fighter1.Name, fighter2.Name, formatNumber(critDmg)),
-						Damage:     critDmg,
-						Attacker:   fighter1.Name,
-						Victim:     fighter2.Name,
-						Commentary: "",
-						Announcer:  "\"Screaming\" Sally Bloodworth",
-						Health1:    state.Fighter1Health,
-						Health2:    state.Fighter2Health,
-						Round:      state.CurrentRound,
-						TickNumber: tickNumber,
-					}
-					e.broadcaster.BroadcastAction(fightID, critAction)
-					e.logFightAction(fightID, critAction.Action)
-				}
-				// Extra death chance due to crit damage on Fighter2
-				if e.checkDeath(rng) {
-					state.DeathOccurred = true
-					state.IsComplete = true
-					state.WinnerID = fighter1.ID
-					if e.broadcaster != nil {
-						deathAction := GenerateDeathAction(fightID, fighter1, fighter2, state.Fighter1Health, state.Fighter2Health, state.CurrentRound)
-						e.broadcaster.BroadcastAction(fightID, deathAction)
-						e.logFightAction(fightID, deathAction.Action)
-						if deathAction.Commentary != "" {
-							e.logFightAction(fightID, fmt.Sprintf("%s: \"%s\"", deathAction.Announcer, deathAction.Commentary))
-						}
-					}
-					return
-				}
-			} else {
-				// Fighter2 crits Fighter1
-				state.Fighter1Health -= critDmg
-				state.LastDamage1 += critDmg
-				// Lifesteal: attacker recovers half the crit damage (capped)
-				if !fighter1.IsUndead {
-					heal := critDmg / 2
-					if heal > 0 {
-						healed := state.Fighter2Health + heal
-						if healed > STARTING_HEALTH {
-							healed = STARTING_HEALTH
-						}
-						state.Fighter2Health = healed
-					}
-				}
-				if e.broadcaster != nil {
-					critAction := LiveAction{
-						Type:       "critical",
-						Action:     fmt.Sprintf("COMEBACK CRIT! %s detonates %s for %s bonus damage!", fighter2.Name, fighter1.Name, formatNumber(critDmg)),
-						Damage:     critDmg,
-						Attacker:   fighter2.Name,
-						Victim:     fighter1.Name,
-						Commentary: "",
-						Announcer:  "\"Screaming\" Sally Bloodworth",
-						Health1:    state.Fighter1Health,
-						Health2:    state.Fighter2Health,
-						Round:      state.CurrentRound,
-						TickNumber: tickNumber,
-					}
-					e.broadcaster.BroadcastAction(fightID, critAction)
-					e.logFightAction(fightID, critAction.Action)
-				}
-				// Extra death chance due to crit damage on Fighter1
-				if e.checkDeath(rng) {
-					state.DeathOccurred = true
-					state.IsComplete = true
-					state.WinnerID = fighter2.ID
-					if e.broadcaster != nil {
-						deathAction := GenerateDeathAction(fightID, fighter2, fighter1, state.Fighter1Health, state.Fighter2Health, state.CurrentRound)
-						e.broadcaster.BroadcastAction(fightID, deathAction)
-						e.logFightAction(fightID, deathAction.Action)
-						if deathAction.Commentary != "" {
-							e.logFightAction(fightID, fmt.Sprintf("%s: \"%s\"", deathAction.Announcer, deathAction.Commentary))
-						}
-					}
-					return
-				}
-			}
-		}
-	}
-
-	// Check for death (only if damage was dealt)
-	if damage1 > 0 && e.checkDeath(rng) {
-		state.DeathOccurred = true
-		state.IsComplete = true
-		state.WinnerID = fighter2.ID
-		log.Printf("%s died from damage!", fighter1.Name)
-
-		// Broadcast death action
-		if e.broadcaster != nil {
-			deathAction := GenerateDeathAction(fightID, fighter2, fighter1, state.Fighter1Health, state.Fighter2Health, state.CurrentRound)
-			e.broadcaster.BroadcastAction(fightID, deathAction)
-
-			// Log the death action
-			e.logFightAction(fightID, deathAction.Action)
-			if deathAction.Commentary != "" {
-				e.logFightAction(fightID, fmt.Sprintf("%s: \"%s\"", deathAction.Announcer, deathAction.Commentary))
-			}
-		}
-		return
-	}
-
-	if damage2 > 0 && e.checkDeath(rng) {
-		state.DeathOccurred = true
-		state.IsComplete = true
-		state.WinnerID = fighter1.ID
-		log.Printf("%s died from damage!", fighter2.Name)
-
-		// Broadcast death action
-		if e.broadcaster != nil {
-			deathAction := GenerateDeathAction(fightID, fighter1, fighter2, state.Fighter1Health, state.Fighter2Health, state.CurrentRound)
-			e.broadcaster.BroadcastAction(fightID, deathAction)
-
-			// Log the death action
-			e.logFightAction(fightID, deathAction.Action)
-			if deathAction.Commentary != "" {
-				e.logFightAction(fightID, fmt.Sprintf("%s: \"%s\"", deathAction.Announcer, deathAction.Commentary))
-			}
-		}
-		return
+	if name == "" {
+		name = DefaultRulesetName
 	}
-
-	// Check for KO
-	if state.Fighter1Health <= 0 {
-		state.IsComplete = true
-		state.WinnerID = fighter2.ID
-		log.Printf("%s won by KO!", fighter2.Name)
-
-		// Broadcast KO action
-		if e.broadcaster != nil {
-			koAction := GenerateDeathAction(fightID, fighter2, fighter1, state.Fighter1Health, state.Fighter2Health, state.CurrentRound)
-			e.broadcaster.BroadcastAction(fightID, koAction)
-
-			// Log the KO action
-			e.logFightAction(fightID, koAction.Action)
-			if koAction.Commentary != "" {
-				e.logFightAction(fightID, fmt.Sprintf("%s: \"%s\"", koAction.Announcer, koAction.Commentary))
-			}
-		}
-		return
-	}
-
-	if state.Fighter2Health <= 0 {
-		state.IsComplete = true
-		state.WinnerID = fighter1.ID
-		log.Printf("%s won by KO!", fighter1.Name)
-
-		// Broadcast KO action
-		if e.broadcaster != nil {
-			koAction := GenerateDeathAction(fightID, fighter1, fighter2, state.Fighter1Health, state.Fighter2Health, state.CurrentRound)
-			e.broadcaster.BroadcastAction(fightID, koAction)
-
-			// Log the KO action
-			e.logFightAction(fightID, koAction.Action)
-			if koAction.Commentary != "" {
-				e.logFightAction(fightID, fmt.Sprintf("%s: \"%s\"", koAction.Announcer, koAction.Commentary))
-			}
-		}
-		return
+	rules, err := LookupRuleset(name, fight.AlgoVersion)
+	if err != nil {
+		log.Printf("Fight %d: %v, falling back to current %s", fight.ID, err, DefaultRulesetName)
+		rules, _ = LookupRuleset(DefaultRulesetName, "")
 	}
+	return rules
 }
 
-// simulateTickQuiet runs a tick without broadcasting (for catch-up)
-func (e *Engine) simulateTickQuiet(fightID, tickNumber int, fighter1, fighter2 database.Fighter, state *FightState) {
-	seed := utils.FightTickSeed(fightID, tickNumber)
-	rng := utils.NewSeededRNG(seed)
-
-	// Determine advantage using a random stat and coinflip aggregation
-	fighter1Advantage := e.determineStatBasedAdvantage(fighter1, fighter2, rng)
-
-	// Calculate base damage for both fighters (simultaneous combat)
-	baseDamage1 := e.calculateDamage(fighter1, rng)
-	baseDamage2 := e.calculateDamage(fighter2, rng)
-
-	var damage1, damage2 int
-	if fighter1Advantage {
-		// Fighter1 wins this exchange - gets full damage, Fighter2 gets reduced damage
-		damage1 = 0
-		damage2 = baseDamage1 + (baseDamage1 / 2) // Winner gets 150% damage
-		// Fighter1 still takes some damage back but reduced
-		damage1 = baseDamage2 / 3 // Loser deals 33% damage back
-	} else {
-		// Fighter2 wins this exchange - gets full damage, Fighter1 gets reduced damage
-		damage2 = 0
-		damage1 = baseDamage2 + (baseDamage2 / 2) // Winner gets 150% damage
-		// Fighter2 still takes some damage back but reduced
-		damage2 = baseDamage1 / 3 // Loser deals 33% damage back
-	}
-
-	// Apply damage
-	state.Fighter1Health -= damage1
-	state.Fighter2Health -= damage2
-	state.LastDamage1 = damage1
-	state.LastDamage2 = damage2
-	state.TickNumber = tickNumber
-
-	// Check for death (only if damage was dealt)
-	if damage1 > 0 && e.checkDeath(rng) {
-		state.DeathOccurred = true
-		state.IsComplete = true
-		state.WinnerID = fighter2.ID
+// stampAlgorithm records the ruleset and algorithm version a fight is about to
+// run under, so replays use the same rules even after the game is retuned.
+// Fights that are already stamped are left alone.
+func (e *Engine) stampAlgorithm(fight *database.Fight) {
+	if fight.AlgoVersion != "" {
 		return
 	}
-
-	if damage2 > 0 && e.checkDeath(rng) {
-		state.DeathOccurred = true
-		state.IsComplete = true
-		state.WinnerID = fighter1.ID
+	rules := e.RulesetForFight(*fight)
+	if err := e.repo.StampFightAlgorithm(fight.ID, rules.Name(), rules.Version()); err != nil {
+		log.Printf("Failed to stamp algorithm version on fight %d: %v", fight.ID, err)
 		return
 	}
-
-	// Comeback Critical (quiet): only if someone is losing by >= 3000 health
-	var critAttacker *database.Fighter
-	healthDiff := state.Fighter2Health - state.Fighter1Health // positive means fighter1 is behind
-	if healthDiff >= 3000 {
-		critAttacker = &fighter1
-	} else if healthDiff <= -3000 {
-		critAttacker = &fighter2
-	}
-
-	if critAttacker != nil && rng.Intn(CRIT_CHANCE) == 0 {
-		critDmg := e.calculateCritDamage(rng)
-		if critDmg > 0 {
-			if critAttacker == &fighter1 {
-				state.Fighter2Health -= critDmg
-				state.LastDamage2 += critDmg
-				// Lifesteal: attacker recovers half the crit damage (capped)
-				heal := critDmg / 2
-				if heal > 0 {
-					healed := state.Fighter1Health + heal
-					if healed > STARTING_HEALTH {
-						healed = STARTING_HEALTH
-					}
-					state.Fighter1Health = healed
-				}
-				if e.checkDeath(rng) {
-					state.DeathOccurred = true
-					state.IsComplete = true
-					state.WinnerID = fighter1.ID
-					return
-				}
-			} else {
-				state.Fighter1Health -= critDmg
-				state.LastDamage1 += critDmg
-				// Lifesteal: attacker recovers half the crit damage (capped)
-				heal := critDmg / 2
-				if heal > 0 {
-					healed := state.Fighter2Health + heal
-					if healed > STARTING_HEALTH {
-						healed = STARTING_HEALTH
-					}
-					state.Fighter2Health = healed
-				}
-				if e.checkDeath(rng) {
-					state.DeathOccurred = true
-					state.IsComplete = true
-					state.WinnerID = fighter2.ID
-					return
-				}
-			}
-		}
-	}
-
-	// Check for KO
-	if state.Fighter1Health <= 0 {
-		state.IsComplete = true
-		state.WinnerID = fighter2.ID
-		return
-	}
-
-	if state.Fighter2Health <= 0 {
-		state.IsComplete = true
-		state.WinnerID = fighter1.ID
-		return
-	}
-}
-
-// calculateDamage calculates damage dealt by winning fighter
-func (e *Engine) calculateDamage(winner database.Fighter, rng *rand.Rand) int {
-	// Base damage with some randomness
-	baseDamage := MIN_DAMAGE + rng.Intn(MAX_DAMAGE-MIN_DAMAGE)
-
-	// Modify by strength (higher strength = more damage)
-	strengthMultiplier := 1.0 + (float64(winner.Strength)/100.0)*0.5
-
-	finalDamage := int(float64(baseDamage) * strengthMultiplier)
-
-	return int(math.Max(float64(MIN_DAMAGE), float64(finalDamage)))
+	fight.Ruleset, fight.AlgoVersion = rules.Name(), rules.Version()
 }
 
-// calculateCritDamage rolls 5d20 and maps number of success dice to bonus damage.
-// We treat a die as a success if it rolls >= 10 (50% success) to get a decent spread.
-// 1 success → 1000, 2 → 2000, 3 → 4000, 4 → 8000, 5 → 10000. 0 → 0.
-func (e *Engine) calculateCritDamage(rng *rand.Rand) int {
-	successes := 0
-	for i := 0; i < 5; i++ {
-		roll := rng.Intn(20) + 1 // 1..20
-		if roll == 20 {          // natural 20 counts as a success
-			successes++
+// playTick advances state by one tick under rules and reports whether a new round began
+func (e *Engine) playTick(fightID int, rules Ruleset, setup *fightSetup, state *FightState, clapHeal1, clapHeal2 int, emit func(LiveAction)) bool {
+	tick := state.TickNumber + 1
+	if emit != nil {
+		// Round and death announcements don't carry a tick of their own, and
+		// tag-team actions carry every fighter's health bar
+		sink := emit
+		emit = func(action LiveAction) {
+			if action.TickNumber == 0 {
+				action.TickNumber = tick
+			}
+			if state.Tag.Enabled() {
+				action.Tag = state.Tag.Bars(state)
+			}
+			sink(action)
 		}
 	}
-	switch successes {
-	case 1:
-		return 5000
-	case 2:
-		return 10000
-	case 3:
-		return 15000
-	case 4:
-		return 20000
-	case 5:
-		return 100000
-	default:
-		return 0
-	}
-}
-
-// checkDeath determines if death occurs this tick
-func (e *Engine) checkDeath(rng *rand.Rand) bool {
-	return rng.Intn(DEATH_CHANCE) == 0
+	in := setup.lineup().tickInput(fightID, tick, setup.tickSeed(fightID, tick))
+	in.ClapHeal1, in.ClapHeal2 = clapHeal1, clapHeal2
+	rules.ResolveTick(in, state, emit)
+	state.TickNumber = tick
+	return rules.AdvanceRound(state, emit)
 }
 
-// determineStatBasedAdvantage picks a random combat stat and gives advantage to the fighter
-// with more "heads" from coin flips equal to that stat value. Ties break randomly.
-func (e *Engine) determineStatBasedAdvantage(f1, f2 database.Fighter, rng *rand.Rand) bool {
-	// Choose a stat: 0=strength, 1=speed, 2=endurance, 3=technique
-	statIdx := rng.Intn(4)
-
-	var v1, v2 int
-	switch statIdx {
-	case 0:
-		v1, v2 = f1.Strength, f2.Strength
-	case 1:
-		v1, v2 = f1.Speed, f2.Speed
-	case 2:
-		v1, v2 = f1.Endurance, f2.Endurance
-	default:
-		v1, v2 = f1.Technique, f2.Technique
-	}
-
-	// Bound to non-negative just in case
-	if v1 < 0 {
-		v1 = 0
-	}
-	if v2 < 0 {
-		v2 = 0
-	}
-
-	heads1 := 0
-	heads2 := 0
-
-	for i := 0; i < v1; i++ {
-		if rng.Intn(2) == 0 {
-			heads1++
+// liveEmitter returns the sink for actions produced during a live fight:
+// every action is recorded as an event, broadcast to viewers and written to
+// the fight log
+func (e *Engine) liveEmitter(fightID int, rec *eventRecorder) func(LiveAction) {
+	return func(action LiveAction) {
+		rec.record(action)
+		if e.broadcaster != nil {
+			e.broadcaster.BroadcastAction(fightID, action)
 		}
-	}
-	for i := 0; i < v2; i++ {
-		if rng.Intn(2) == 0 {
-			heads2++
+		e.logFightAction(fightID, action.Action)
+		if action.Commentary != "" {
+			e.logFightAction(fightID, fmt.Sprintf("%s: \"%s\"", action.Announcer, action.Commentary))
 		}
 	}
-
-	if heads1 == heads2 {
-		// random tie-breaker
-		return rng.Intn(2) == 0
-	}
-	return heads1 > heads2
 }
 
 // calculateFighterHealthForDate calculates starting health (base health only, no effect modifications)
@@ -969,8 +540,9 @@ func (e *Engine) calculateFighterHealthForDate(fighterID int, effectDate time.Ti
 	return baseHealth
 }
 
-// applyStatEffectsToFighter applies stat-based effects to a fighter's stats
-func (e *Engine) applyStatEffectsToFighter(fighter database.Fighter, effectDate time.Time) database.Fighter {
+// applyStatEffectsToFighter applies stat-based effects to a fighter's stats and
+// returns the modified fighter along with the effects that were applied
+func (e *Engine) applyStatEffectsToFighter(fighter database.Fighter, effectDate time.Time) (database.Fighter, []database.AppliedEffect) {
 	modifiedFighter := fighter
 
 	// Get day bounds for the effect date
@@ -981,7 +553,7 @@ func (e *Engine) applyStatEffectsToFighter(fighter database.Fighter, effectDate
 	effects, err := e.repo.GetAppliedEffectsForDate("fighter", fighter.ID, startDate, endDate)
 	if err != nil {
 		log.Printf("Error getting applied effects for fighter %d on date %s: %v", fighter.ID, effectDate.Format("2006-01-02"), err)
-		return modifiedFighter
+		return modifiedFighter, nil
 	}
 
 	// Apply stat modifications
@@ -1006,6 +578,9 @@ func (e *Engine) applyStatEffectsToFighter(fighter database.Fighter, effectDate
 		}
 	}
 
+	// Injuries and fatigue from recent fights wear the fighter down
+	applyCondition(&modifiedFighter, e.fighterCondition(fighter.ID, effectDate))
+
 	// Ensure stats don't go below minimum values
 	if modifiedFighter.Strength < 1 {
 		modifiedFighter.Strength = 1
@@ -1024,7 +599,7 @@ func (e *Engine) applyStatEffectsToFighter(fighter database.Fighter, effectDate
 		fighter.Name, fighter.Strength, modifiedFighter.Strength, fighter.Speed, modifiedFighter.Speed,
 		fighter.Endurance, modifiedFighter.Endurance, fighter.Technique, modifiedFighter.Technique)
 
-	return modifiedFighter
+	return modifiedFighter, effects
 }
 
 // CompleteFight finishes a fight and persists results to database
@@ -1074,7 +649,31 @@ func (e *Engine) CompleteFight(fight database.Fight, state *FightState) error {
 	if err != nil {
 		log.Printf("Failed to process bets for fight %d: %v", fight.ID, err)
 		// Continue with fight completion even if bet processing fails
-	} else if len(affectedUserIDs) > 0 {
+	}
+
+	// Settle the prop markets on how the fight ended
+	if err := e.repo.ProcessPropBetsForFight(fight.ID, PropOutcome(*state)); err != nil {
+		log.Printf("Failed to process prop bets for fight %d: %v", fight.ID, err)
+	}
+
+	// Settle this fight's parlay legs; a draw voids them out of their slips
+	if err := e.repo.ProcessSlipLegsForFight(fight.ID, winnerIDPtr); err != nil {
+		log.Printf("Failed to process parlay legs for fight %d: %v", fight.ID, err)
+	}
+
+	// Pay out challenges between users from escrow; a draw hands the stakes back
+	challenges, err := e.repo.ProcessChallengesForFight(fight.ID, winnerIDPtr)
+	if err != nil {
+		log.Printf("Failed to process challenges for fight %d: %v", fight.ID, err)
+	} else if len(challenges) > 0 {
+		go func() {
+			if err := e.discordNotifier.NotifyChallengeResults(fight, challenges); err != nil {
+				log.Printf("Failed to announce challenge results for fight %d: %v", fight.ID, err)
+			}
+		}()
+	}
+
+	if len(affectedUserIDs) > 0 {
 		// Update Discord roles for users whose credits changed
 		go e.UpdateUserRolesAfterCreditsChange(affectedUserIDs)
 	}
@@ -1087,7 +686,7 @@ func (e *Engine) CompleteFight(fight database.Fight, state *FightState) error {
 			// Continue with fight completion even if MVP processing fails
 		}
 
-		err = e.applyLegacyInfusionIfSaturdayChampion(fight, state, time.Now())
+		err = e.applyLegacyInfusionIfSaturdayChampion(fight, state, e.clock().Now())
 		if err != nil {
 			log.Printf("Failed to apply legacy infusion for fight %d: %v", fight.ID, err)
 		}
@@ -1098,6 +697,7 @@ func (e *Engine) CompleteFight(fight database.Fight, state *FightState) error {
 	if err != nil {
 		return fmt.Errorf("failed to update fight: %w", err)
 	}
+	e.clearCheckpoint(fight.ID)
 
 	// Handle death if it occurred
 	if state.DeathOccurred {
@@ -1107,8 +707,13 @@ func (e *Engine) CompleteFight(fight database.Fight, state *FightState) error {
 		} else {
 			deadFighterID = fight.Fighter1ID
 		}
+		killerID := state.WinnerID
+		// In tag-team fights whoever was in the ring did the killing and the dying
+		if state.Tag.DeadFighterID != 0 {
+			deadFighterID, killerID = state.Tag.DeadFighterID, state.Tag.KillerID
+		}
 
-		if err = e.repo.RecordFightKill(fight.ID, state.WinnerID, deadFighterID, state.CurrentRound, state.TickNumber); err != nil {
+		if err = e.repo.RecordFightKill(fight.ID, killerID, deadFighterID, state.CurrentRound, state.TickNumber); err != nil {
 			return fmt.Errorf("failed to record kill for fight %d: %w", fight.ID, err)
 		}
 
@@ -1121,19 +726,26 @@ func (e *Engine) CompleteFight(fight database.Fight, state *FightState) error {
 	}
 
 	// Update fighter records
+	result := "draw"
 	switch state.WinnerID {
 	case fight.Fighter1ID:
-		err = e.repo.UpdateFighterRecords(fight.Fighter1ID, fight.Fighter2ID, "fighter1_wins")
+		result = "fighter1_wins"
 	case fight.Fighter2ID:
-		err = e.repo.UpdateFighterRecords(fight.Fighter1ID, fight.Fighter2ID, "fighter2_wins")
-	default:
-		err = e.repo.UpdateFighterRecords(fight.Fighter1ID, fight.Fighter2ID, "draw")
+		result = "fighter2_wins"
+	}
+	err = e.repo.UpdateFighterRecords(fight.Fighter1ID, fight.Fighter2ID, result)
+	if err == nil && fight.IsTagTeam() {
+		err = e.repo.UpdateFighterRecords(fight.Partner1ID, fight.Partner2ID, result)
 	}
 
 	if err != nil {
 		return fmt.Errorf("failed to update fighter records: %w", err)
 	}
 
+	// Survivors carry the fight into the days ahead, and may walk out changed
+	e.recordInjuries(fight, state)
+	e.rollMutations(fight, state)
+
 	// Get fighter information for Discord notification
 	fighter1, err := e.repo.GetFighter(fight.Fighter1ID)
 	if err != nil {
@@ -1209,13 +821,9 @@ func (e *Engine) applyLegacyInfusionIfSaturdayChampion(fight database.Fight, sta
 		return nil
 	}
 
-	centralTime, err := time.LoadLocation("America/Chicago")
-	if err != nil {
-		return err
-	}
-
-	nowC := now.In(centralTime)
-	scheduledC := fight.ScheduledTime.In(centralTime)
+	league := e.clock().Location()
+	nowC := now.In(league)
+	scheduledC := fight.ScheduledTime.In(league)
 
 	if scheduledC.Weekday() != time.Saturday {
 		return nil
@@ -1324,6 +932,8 @@ func (e *Engine) processSingleActiveFight(fight database.Fight, now time.Time) e
 		return fmt.Errorf("failed to get fighter2: %w", err)
 	}
 
+	e.stampAlgorithm(&fight)
+
 	// Check if fight should be over (30 minutes elapsed)
 	fightEndTime := fight.ScheduledTime.Add(30 * time.Minute)
 	if now.After(fightEndTime) {
@@ -1366,10 +976,15 @@ func (e *Engine) UpdateUserRolesAfterCreditsChange(userIDs []int) {
 		if err != nil {
 			log.Printf("Failed to update Discord role for user %s: %v", user.Username, err)
 		}
-	}
-}
 
-// LogAction is a public method to log fight actions (used by websocket for clap summaries)
-func (e *Engine) LogAction(fightID int, text string) {
-	e.logFightAction(fightID, text)
+		// Defaults and paid-off loans show up here too, so keep the Debtor role in step
+		debtor, err := e.repo.HasDefaultedLoan(userID)
+		if err != nil {
+			log.Printf("Failed to check loans for user %s: %v", user.Username, err)
+			continue
+		}
+		if err := e.roleManager.SyncDebtorRole(user, debtor); err != nil {
+			log.Printf("Failed to sync Debtor role for user %s: %v", user.Username, err)
+		}
+	}
 }
@@ -0,0 +1,109 @@
+package fight
+
+import (
+	"encoding/json"
+	"log"
+
+	"spoodblort/database"
+)
+
+// eventRecorder turns the actions a fight emits into structured fight_events
+// rows. Rows are buffered until flushEvents writes them, so a quiet catch-up
+// costs one transaction instead of one per tick.
+type eventRecorder struct {
+	fightID  int
+	fighter1 string
+	fighter2 string
+	tick     int
+	seq      int
+	pending  []database.FightEvent
+}
+
+func newEventRecorder(fightID int, setup *fightSetup) *eventRecorder {
+	return &eventRecorder{
+		fightID:  fightID,
+		fighter1: setup.fighter1.Name,
+		fighter2: setup.fighter2.Name,
+	}
+}
+
+// record buffers an emitted action, plus a frenzy row for each lane that frenzied
+func (r *eventRecorder) record(action LiveAction) {
+	payload, _ := json.Marshal(action)
+	r.add(database.FightEvent{
+		Tick:        action.TickNumber,
+		Round:       action.Round,
+		EventType:   fightEventType(action),
+		Attacker:    action.Attacker,
+		Victim:      action.Victim,
+		Damage:      action.Damage,
+		Health1:     action.Health1,
+		Health2:     action.Health2,
+		PayloadJSON: string(payload),
+	})
+
+	if action.Frenzy1 {
+		r.addFrenzy(action, 1, r.fighter1, action.Frenzy1Mult, action.Frenzy1Zero)
+	}
+	if action.Frenzy2 {
+		r.addFrenzy(action, 2, r.fighter2, action.Frenzy2Mult, action.Frenzy2Zero)
+	}
+}
+
+func (r *eventRecorder) addFrenzy(action LiveAction, lane int, name string, mult int, zeroed string) {
+	payload, _ := json.Marshal(map[string]interface{}{
+		"lane":        lane,
+		"multiplier":  mult,
+		"zeroed_stat": zeroed,
+	})
+	r.add(database.FightEvent{
+		Tick:        action.TickNumber,
+		Round:       action.Round,
+		EventType:   database.FightEventFrenzy,
+		Attacker:    name,
+		Health1:     action.Health1,
+		Health2:     action.Health2,
+		PayloadJSON: string(payload),
+	})
+}
+
+// add numbers the event within its tick and queues it
+func (r *eventRecorder) add(ev database.FightEvent) {
+	if ev.Tick != r.tick {
+		r.tick, r.seq = ev.Tick, 0
+	}
+	r.seq++
+	ev.FightID = r.fightID
+	ev.Seq = r.seq
+	r.pending = append(r.pending, ev)
+}
+
+// fightEventType maps a LiveAction type onto the stored event types. Low-health
+// callouts are ordinary exchanges as far as history is concerned.
+func fightEventType(action LiveAction) string {
+	switch action.Type {
+	case "critical":
+		return database.FightEventCritical
+	case "round":
+		return database.FightEventRound
+	case "clap_summary":
+		return database.FightEventClapSummary
+	case "death":
+		return database.FightEventDeath
+	case "special":
+		return database.FightEventSpecial
+	default:
+		return database.FightEventDamage
+	}
+}
+
+// flushEvents writes any buffered events for the recorder's fight
+func (e *Engine) flushEvents(rec *eventRecorder) {
+	if len(rec.pending) == 0 {
+		return
+	}
+	if err := e.repo.InsertFightEvents(rec.pending); err != nil {
+		log.Printf("Failed to record %d events for fight %d: %v", len(rec.pending), rec.fightID, err)
+	}
+	rec.pending = rec.pending[:0]
+}
@@ -2,6 +2,7 @@ package fight
 
 import (
 	"fmt"
+	"log"
 	"sort"
 	"time"
 
@@ -21,8 +22,7 @@ func (g *Generator) SelectDailyFighters(fighters []database.Fighter, date time.T
 	seed := utils.DailyFighterSeed(date)
 	rng := utils.NewSeededRNG(seed)
 
-	available := make([]database.Fighter, len(fighters))
-	copy(available, fighters)
+	available := g.benchInjured(fighters, date)
 
 	var prioritized []database.Fighter
 	prioritizedIDs := make(map[int]struct{})
@@ -85,6 +85,34 @@ func (g *Generator) SelectDailyFighters(fighters []database.Fighter, date time.T
 	return selected
 }
 
+// benchInjured drops fighters too badly hurt to fight on date. Nobody is
+// benched if it would leave fewer than two fighters to draw from.
+func (g *Generator) benchInjured(fighters []database.Fighter, date time.Time) []database.Fighter {
+	available := make([]database.Fighter, 0, len(fighters))
+	injuries, err := g.repo.GetInjuriesBetween(date.Add(-injuryLookback), date)
+	if err != nil {
+		log.Printf("Failed to load injuries, benching nobody: %v", err)
+		return append(available, fighters...)
+	}
+
+	byFighter := make(map[int][]database.FighterInjury)
+	for _, inj := range injuries {
+		byFighter[inj.FighterID] = append(byFighter[inj.FighterID], inj)
+	}
+	for _, f := range fighters {
+		if c := conditionAt(byFighter[f.ID], date); c.Injury >= InjuryBenchSeverity {
+			log.Printf("🩹 %s is benched today (injury %d)", f.Name, c.Injury)
+			continue
+		}
+		available = append(available, f)
+	}
+
+	if len(available) < 2 {
+		return append(available[:0], fighters...)
+	}
+	return available
+}
+
 func (g *Generator) GenerateFightSchedule(tournament *database.Tournament, fighters []database.Fighter, date time.Time) ([]database.Fight, error) {
 	if len(fighters) < 2 {
 		return nil, fmt.Errorf("need at least 2 fighters to create fights")
@@ -142,6 +170,123 @@ func (g *Generator) GenerateFightSchedule(tournament *database.Tournament, fight
 	return fights, nil
 }
 
+// ArrangeGrudgeMatch rebooks a singles card so the hottest rivalry on it
+// fights each other. The first rivalry scoring at least minScore whose pair
+// last met after since and are both on the card is used: their two fights are
+// reshuffled so the rivals meet and their opponents face each other instead,
+// keeping both time slots. Returns the card unchanged if no rivalry qualifies.
+func (g *Generator) ArrangeGrudgeMatch(fights []database.Fight, minScore int, since time.Time) []database.Fight {
+	rivalries, err := g.repo.GetHeadToHeadRecords()
+	if err != nil {
+		log.Printf("Failed to load rivalries, no grudge match today: %v", err)
+		return fights
+	}
+
+	slot := make(map[int]int) // fighter ID -> index of their fight on the card
+	for i, f := range fights {
+		if f.IsTagTeam() {
+			continue
+		}
+		slot[f.Fighter1ID] = i
+		slot[f.Fighter2ID] = i
+	}
+
+	for _, h := range rivalries {
+		if h.Score < minScore {
+			break
+		}
+		if h.LastMet.Before(since) {
+			continue
+		}
+		i, okA := slot[h.FighterA]
+		j, okB := slot[h.FighterB]
+		if !okA || !okB {
+			continue
+		}
+
+		if i != j {
+			opponentA := opponentOn(fights[i], h.FighterA)
+			opponentB := opponentOn(fights[j], h.FighterB)
+			fights[i].Fighter1ID, fights[i].Fighter1Name = h.FighterA, h.NameA
+			fights[i].Fighter2ID, fights[i].Fighter2Name = h.FighterB, h.NameB
+			fights[j].Fighter1ID, fights[j].Fighter1Name = opponentA.id, opponentA.name
+			fights[j].Fighter2ID, fights[j].Fighter2Name = opponentB.id, opponentB.name
+		}
+		fights[i].Grudge = true
+		log.Printf("🔥 Grudge match booked: %s vs %s (rivalry %d)", h.NameA, h.NameB, h.Score)
+		return fights
+	}
+	return fights
+}
+
+type cardFighter struct {
+	id   int
+	name string
+}
+
+// opponentOn returns whoever fighterID faces in a singles fight
+func opponentOn(f database.Fight, fighterID int) cardFighter {
+	if f.Fighter1ID == fighterID {
+		return cardFighter{f.Fighter2ID, f.Fighter2Name}
+	}
+	return cardFighter{f.Fighter1ID, f.Fighter1Name}
+}
+
+// GenerateTagTeamSchedule pairs the day's fighters into two-a-side fights.
+// Fighters are ranked by combat stats and taken four at a time, with the
+// strongest and weakest of each four teamed against the middle two so the
+// sides come out roughly even.
+func (g *Generator) GenerateTagTeamSchedule(tournament *database.Tournament, fighters []database.Fighter, date time.Time, healthMode string) ([]database.Fight, error) {
+	if len(fighters) < 4 {
+		return nil, fmt.Errorf("need at least 4 fighters to create tag-team fights")
+	}
+
+	fighters = fighters[:len(fighters)-len(fighters)%4]
+
+	sort.Slice(fighters, func(i, j int) bool {
+		ti := fighters[i].Strength + fighters[i].Speed + fighters[i].Endurance + fighters[i].Technique
+		tj := fighters[j].Strength + fighters[j].Speed + fighters[j].Endurance + fighters[j].Technique
+		if ti == tj {
+			return fighters[i].ID < fighters[j].ID
+		}
+		return ti < tj
+	})
+
+	var fights []database.Fight
+
+	flipRNG := utils.NewSeededRNG(utils.DailyFighterSeed(date) ^ int64(tournament.ID))
+
+	startTime := time.Date(date.Year(), date.Month(), date.Day(), 12, 0, 0, 0, date.Location())
+
+	for i := 0; i+3 < len(fighters); i += 4 {
+		fightTime := startTime.Add(time.Duration(i/4) * 30 * time.Minute)
+
+		side1 := [2]database.Fighter{fighters[i], fighters[i+3]}
+		side2 := [2]database.Fighter{fighters[i+1], fighters[i+2]}
+		if flipRNG.Intn(2) == 1 {
+			side1, side2 = side2, side1
+		}
+
+		fights = append(fights, database.Fight{
+			TournamentID:  tournament.ID,
+			Fighter1ID:    side1[0].ID,
+			Fighter2ID:    side2[0].ID,
+			Fighter1Name:  database.TagTeamName(side1[0].Name, side1[1].Name),
+			Fighter2Name:  database.TagTeamName(side2[0].Name, side2[1].Name),
+			Partner1ID:    side1[1].ID,
+			Partner2ID:    side2[1].ID,
+			Partner1Name:  side1[1].Name,
+			Partner2Name:  side2[1].Name,
+			Format:        database.FightFormatTagTeam,
+			TagHealth:     healthMode,
+			ScheduledTime: fightTime,
+			Status:        "scheduled",
+		})
+	}
+
+	return fights, nil
+}
+
 func (g *Generator) CreateFights(fights []database.Fight) error {
 	for _, fight := range fights {
 		err := g.repo.InsertFight(fight)
@@ -0,0 +1,128 @@
+package fight
+
+import (
+	"log"
+	"time"
+
+	"spoodblort/database"
+)
+
+// Injury and fatigue tuning. Both are scored 0-100 when a fight ends and wear
+// off by a fixed amount per day.
+const (
+	InjuryDamageWeight    = 60 // severity from losing all of your health
+	InjuryPerCritTaken    = 4
+	FatigueFullFight      = 20 // fatigue from going the full distance
+	FatiguePerFrenzy      = 10
+	InjuryRecoveryPerDay  = 15
+	FatigueRecoveryPerDay = 35
+	InjuryMaxPenaltyPct   = 60 // most of a stat injuries and fatigue can take away
+	InjuryBenchSeverity   = 60 // fighters hurt this badly sit out the daily draw
+)
+
+// injuryLookback covers the longest an injury can take to heal
+const injuryLookback = (100/InjuryRecoveryPerDay + 1) * 24 * time.Hour
+
+// condition is how hurt and tired a fighter is at a moment in time
+type condition struct {
+	Injury  int
+	Fatigue int
+}
+
+// conditionAt sums what is left of each injury at t
+func conditionAt(injuries []database.FighterInjury, t time.Time) condition {
+	var c condition
+	for _, inj := range injuries {
+		days := t.Sub(inj.CreatedAt).Hours() / 24
+		if days < 0 {
+			continue
+		}
+		c.Injury += max(0, inj.Severity-int(days*InjuryRecoveryPerDay))
+		c.Fatigue += max(0, inj.Fatigue-int(days*FatigueRecoveryPerDay))
+	}
+	c.Injury = min(c.Injury, 100)
+	c.Fatigue = min(c.Fatigue, 100)
+	return c
+}
+
+// penalties returns the percent taken off all combat stats by injury, and off
+// speed and endurance by fatigue
+func (c condition) penalties() (all, legs int) {
+	all = min(c.Injury/2, InjuryMaxPenaltyPct)
+	legs = min(all+c.Fatigue/4, InjuryMaxPenaltyPct) - all
+	return all, legs
+}
+
+// fighterCondition loads a fighter's injuries and returns their condition at t
+func (e *Engine) fighterCondition(fighterID int, t time.Time) condition {
+	injuries, err := e.repo.GetFighterInjuries(fighterID, t.Add(-injuryLookback), t)
+	if err != nil {
+		log.Printf("Error getting injuries for fighter %d: %v", fighterID, err)
+		return condition{}
+	}
+	return conditionAt(injuries, t)
+}
+
+// applyCondition weakens a fighter's stats by their current injuries and fatigue
+func applyCondition(fighter *database.Fighter, c condition) {
+	all, legs := c.penalties()
+	if all == 0 && legs == 0 {
+		return
+	}
+	cut := func(stat, pct int) int { return stat - stat*pct/100 }
+	fighter.Strength = cut(fighter.Strength, all)
+	fighter.Technique = cut(fighter.Technique, all)
+	fighter.Speed = cut(fighter.Speed, all+legs)
+	fighter.Endurance = cut(fighter.Endurance, all+legs)
+}
+
+// recordInjuries scores the damage and fatigue each surviving fighter carries
+// out of a completed fight: health lost and crits taken hurt, long fights and
+// frenzies tire
+func (e *Engine) recordInjuries(fight database.Fight, state *FightState) {
+	lanes, err := e.finalLanes(fight, state)
+	if err != nil {
+		log.Printf("Fight %d: failed to load fighters for injuries: %v", fight.ID, err)
+		return
+	}
+
+	for _, lane := range lanes {
+		fighter := lane.fighter
+		if fighter.IsDead {
+			continue
+		}
+		crits, err := e.repo.CountFightEventsFor(fight.ID, database.FightEventCritical, "victim", fighter.Name)
+		if err != nil {
+			log.Printf("Fight %d: failed to count crits on %s: %v", fight.ID, fighter.Name, err)
+		}
+		frenzies, err := e.repo.CountFightEventsFor(fight.ID, database.FightEventFrenzy, "attacker", fighter.Name)
+		if err != nil {
+			log.Printf("Fight %d: failed to count frenzies for %s: %v", fight.ID, fighter.Name, err)
+		}
+
+		injury := database.FighterInjury{
+			FighterID:   fighter.ID,
+			FightID:     fight.ID,
+			FinalHealth: lane.endHealth,
+			CritsTaken:  crits,
+			Frenzies:    frenzies,
+			CreatedAt:   e.clock().Now(),
+		}
+		if lane.startHealth > 0 {
+			lost := max(0, lane.startHealth-max(0, lane.endHealth))
+			injury.Severity = lost * InjuryDamageWeight / lane.startHealth
+		}
+		injury.Severity = min(100, injury.Severity+crits*InjuryPerCritTaken)
+		injury.Fatigue = min(100, state.TickNumber*FatigueFullFight/MAX_FIGHT_TICKS+frenzies*FatiguePerFrenzy)
+		if injury.Severity == 0 && injury.Fatigue == 0 {
+			continue
+		}
+
+		if err := e.repo.RecordFighterInjury(injury); err != nil {
+			log.Printf("Fight %d: failed to record injuries for %s: %v", fight.ID, fighter.Name, err)
+			continue
+		}
+		log.Printf("🩹 %s leaves fight %d with injury %d, fatigue %d (%d crits taken, %d frenzies)",
+			fighter.Name, fight.ID, injury.Severity, injury.Fatigue, crits, frenzies)
+	}
+}
@@ -0,0 +1,178 @@
+package fight
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// In-play betting tuning
+const (
+	InPlayCutoffTicks  = 20   // betting closes for the final minute of a fight
+	InPlaySimulations  = 500  // simulations behind each in-play price
+	InPlayMarginPct    = 5    // house margin shaved off the fair price
+	InPlayMinOdds      = 1.01 // never offer less than this
+	InPlayMaxOdds      = 50.0 // or more than this
+	inPlayQuoteRetries = 3    // re-quotes allowed when a tick lands mid-bet
+)
+
+// ErrInPlayClosed is returned when a fight isn't taking in-play bets
+var ErrInPlayClosed = errors.New("in-play betting is closed for this fight")
+
+// InPlayQuote is the price of each side of a live fight at one tick
+type InPlayQuote struct {
+	FightID      int     `json:"fight_id"`
+	Tick         int     `json:"tick"`
+	Round        int     `json:"round"`
+	Health1      int     `json:"health1"`
+	Health2      int     `json:"health2"`
+	Fighter1ID   int     `json:"fighter1_id"`
+	Fighter2ID   int     `json:"fighter2_id"`
+	Fighter1Win  float64 `json:"fighter1_win"`
+	Fighter2Win  float64 `json:"fighter2_win"`
+	Fighter1Odds float64 `json:"fighter1_odds"` // decimal odds, 0 when the side can't be backed
+	Fighter2Odds float64 `json:"fighter2_odds"`
+	ClosesAtTick int     `json:"closes_at_tick"`
+}
+
+// OddsFor returns the decimal odds offered on fighterID
+func (q InPlayQuote) OddsFor(fighterID int) float64 {
+	if fighterID == q.Fighter1ID {
+		return q.Fighter1Odds
+	}
+	return q.Fighter2Odds
+}
+
+// liveFight is the published view of a fight the engine is running live
+type liveFight struct {
+	rules  Ruleset
+	lineup Lineup
+	state  FightState
+	quote  *InPlayQuote // cached price for state.TickNumber
+}
+
+// liveRegistry tracks the fights this engine is running live, so bets can be
+// priced from and locked to the tick the fight is actually on
+type liveRegistry struct {
+	mu     sync.Mutex
+	fights map[int]*liveFight
+}
+
+// publishLiveState records the state a live fight reached after a tick
+func (e *Engine) publishLiveState(fightID int, rules Ruleset, setup *fightSetup, state *FightState) {
+	e.live.mu.Lock()
+	defer e.live.mu.Unlock()
+	lf, ok := e.live.fights[fightID]
+	if !ok {
+		lf = &liveFight{rules: rules, lineup: setup.lineup()}
+		e.live.fights[fightID] = lf
+	}
+	lf.state = *state
+	lf.quote = nil
+}
+
+// retireLiveState forgets a fight once its live simulation ends
+func (e *Engine) retireLiveState(fightID int) {
+	e.live.mu.Lock()
+	defer e.live.mu.Unlock()
+	delete(e.live.fights, fightID)
+}
+
+// inPlayOpen reports whether a live fight at state can still take bets
+func inPlayOpen(state FightState) bool {
+	return !state.IsComplete && !state.DeathOccurred && state.TickNumber < MAX_FIGHT_TICKS-InPlayCutoffTicks
+}
+
+// QuoteInPlay prices both sides of a live fight from its current state
+func (e *Engine) QuoteInPlay(fightID int) (*InPlayQuote, error) {
+	e.live.mu.Lock()
+	lf, ok := e.live.fights[fightID]
+	if !ok || !inPlayOpen(lf.state) {
+		e.live.mu.Unlock()
+		return nil, ErrInPlayClosed
+	}
+	if lf.quote != nil {
+		quote := *lf.quote
+		e.live.mu.Unlock()
+		return &quote, nil
+	}
+	rules, lineup, state := lf.rules, lf.lineup, lf.state
+	e.live.mu.Unlock()
+
+	// Price outside the lock so the fight keeps ticking meanwhile
+	estimate := EstimateOdds(fightID, rules, lineup, state, InPlaySimulations)
+	quote := &InPlayQuote{
+		FightID:      fightID,
+		Tick:         state.TickNumber,
+		Round:        state.CurrentRound,
+		Health1:      state.Fighter1Health,
+		Health2:      state.Fighter2Health,
+		Fighter1ID:   state.SimFighter1ID,
+		Fighter2ID:   state.SimFighter2ID,
+		Fighter1Win:  estimate.Fighter1Win,
+		Fighter2Win:  estimate.Fighter2Win,
+		Fighter1Odds: offeredOdds(estimate.Fighter1Win),
+		Fighter2Odds: offeredOdds(estimate.Fighter2Win),
+		ClosesAtTick: MAX_FIGHT_TICKS - InPlayCutoffTicks,
+	}
+
+	e.live.mu.Lock()
+	if lf, ok := e.live.fights[fightID]; ok && lf.state.TickNumber == quote.Tick {
+		lf.quote = quote
+	}
+	e.live.mu.Unlock()
+
+	copied := *quote
+	return &copied, nil
+}
+
+// PlaceInPlayBet accepts a bet on a live fight at the price for the tick the
+// fight is on when the bet lands. Bets are refused in the final ticks, once a
+// fighter is dead, or on a side the simulations give no chance. The fight's
+// secret tick salt keeps the remaining ticks from being played out ahead of
+// time by anyone holding the public stats.
+func (e *Engine) PlaceInPlayBet(userID, fightID, fighterID, amount int) (*InPlayQuote, int, error) {
+	for attempt := 0; attempt < inPlayQuoteRetries; attempt++ {
+		quote, err := e.QuoteInPlay(fightID)
+		if err != nil {
+			return nil, 0, err
+		}
+		if fighterID != quote.Fighter1ID && fighterID != quote.Fighter2ID {
+			return nil, 0, fmt.Errorf("fighter %d is not in fight %d", fighterID, fightID)
+		}
+		odds := quote.OddsFor(fighterID)
+		if odds == 0 {
+			return nil, 0, fmt.Errorf("no price available on that fighter")
+		}
+
+		// Hold the live state still while the bet is written so it locks to this tick
+		e.live.mu.Lock()
+		lf, ok := e.live.fights[fightID]
+		if !ok || !inPlayOpen(lf.state) {
+			e.live.mu.Unlock()
+			return nil, 0, ErrInPlayClosed
+		}
+		if lf.state.TickNumber != quote.Tick {
+			e.live.mu.Unlock()
+			continue // the fight moved on while we were pricing; re-quote
+		}
+		betID, err := e.repo.CreateInPlayBet(userID, fightID, fighterID, amount, odds, quote.Tick)
+		e.live.mu.Unlock()
+		if err != nil {
+			return nil, 0, err
+		}
+		return quote, betID, nil
+	}
+	return nil, 0, fmt.Errorf("the fight is moving too fast to price, try again")
+}
+
+// offeredOdds turns a win probability into decimal odds less the house margin
+func offeredOdds(p float64) float64 {
+	if p <= 0 {
+		return 0
+	}
+	odds := (1 / p) * float64(100-InPlayMarginPct) / 100
+	odds = math.Floor(odds*100) / 100
+	return math.Max(InPlayMinOdds, math.Min(InPlayMaxOdds, odds))
+}
@@ -0,0 +1,270 @@
+package fight
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"spoodblort/database"
+	"spoodblort/utils"
+)
+
+// Snapshot lanes for tag-team partners; captains keep lanes 1 and 2
+const (
+	partner1Lane = 3
+	partner2Lane = 4
+)
+
+// fighterSnapshot is what gets recorded for each lane at the opening bell
+type fighterSnapshot struct {
+	Fighter database.Fighter         `json:"fighter"`
+	Effects []database.AppliedEffect `json:"effects"`
+}
+
+// fightSetup is everything outside the tick seed that decides how a fight plays
+// out: who walked in with which stats, and what the crowd did along the way
+type fightSetup struct {
+	fighter1, fighter2 database.Fighter
+	health1, health2   int
+	clapHeals          map[int][2]int // tick -> heal delivered to lanes 1 and 2
+	recorded           bool           // opening snapshot came from fight_inputs
+	tickSalt           int64          // secret added to every tick seed; 0 for fights that predate it
+
+	// Tag-team partners; zero values in singles
+	partner1, partner2             database.Fighter
+	partnerHealth1, partnerHealth2 int
+	tagShared                      bool // the pairs share one health pool
+
+	booth *BoothContext // commentary context, set when the fight is being announced
+}
+
+// loadFightSetup returns the opening fighters and recorded crowd inputs for a
+// fight. A recorded opening snapshot is used verbatim; otherwise the effects
+// applied on effectDate are read and, when record is set, persisted so every
+// later simulation of this fight starts from exactly the same place. Tag-team
+// partners are loaded alongside the captains and snapshotted on lanes 3 and 4.
+func (e *Engine) loadFightSetup(fight database.Fight, fighter1, fighter2 database.Fighter, effectDate time.Time, record bool) *fightSetup {
+	setup := &fightSetup{
+		clapHeals: make(map[int][2]int),
+		tagShared: fight.IsTagTeam() && fight.TagHealth == database.TagHealthShared,
+	}
+	lanes := 2
+	if fight.IsTagTeam() {
+		lanes = 4
+	}
+
+	inputs, err := e.repo.GetFightInputs(fight.ID)
+	if err != nil {
+		log.Printf("Failed to load recorded inputs for fight %d: %v", fight.ID, err)
+	}
+
+	snapshots := 0
+	for _, in := range inputs {
+		if in.InputType == database.FightInputTickSalt {
+			setup.tickSalt = int64(in.Value)
+			continue
+		}
+		if in.Lane < 1 || in.Lane > lanes {
+			continue
+		}
+		switch in.InputType {
+		case database.FightInputFighterSnapshot:
+			var snap fighterSnapshot
+			if err := json.Unmarshal([]byte(in.PayloadJSON), &snap); err != nil {
+				log.Printf("Fight %d: unreadable snapshot for lane %d: %v", fight.ID, in.Lane, err)
+				continue
+			}
+			switch in.Lane {
+			case 1:
+				setup.fighter1, setup.health1 = snap.Fighter, in.Value
+			case 2:
+				setup.fighter2, setup.health2 = snap.Fighter, in.Value
+			case partner1Lane:
+				setup.partner1, setup.partnerHealth1 = snap.Fighter, in.Value
+			case partner2Lane:
+				setup.partner2, setup.partnerHealth2 = snap.Fighter, in.Value
+			}
+			snapshots++
+		case database.FightInputClapHeal:
+			if in.Lane > 2 {
+				continue
+			}
+			heals := setup.clapHeals[in.Tick]
+			heals[in.Lane-1] += in.Value
+			setup.clapHeals[in.Tick] = heals
+		}
+	}
+
+	if snapshots == lanes {
+		setup.recorded = true
+		return setup
+	}
+
+	var effects1, effects2 []database.AppliedEffect
+	setup.fighter1, effects1 = e.applyStatEffectsToFighter(fighter1, effectDate)
+	setup.fighter2, effects2 = e.applyStatEffectsToFighter(fighter2, effectDate)
+	setup.health1 = e.calculateFighterHealthForDate(fighter1.ID, effectDate)
+	setup.health2 = e.calculateFighterHealthForDate(fighter2.ID, effectDate)
+
+	if record {
+		e.recordSnapshot(fight.ID, 1, setup.fighter1, setup.health1, effects1)
+		e.recordSnapshot(fight.ID, 2, setup.fighter2, setup.health2, effects2)
+		setup.tickSalt = e.recordTickSalt(fight.ID)
+		setup.recorded = true
+	}
+
+	if fight.IsTagTeam() {
+		if err := e.loadPartners(setup, fight, effectDate, record); err != nil {
+			log.Printf("Fight %d: failed to load tag-team partners: %v", fight.ID, err)
+			setup.recorded = false
+		}
+	}
+	return setup
+}
+
+// loadPartners applies the day's effects to a tag-team fight's partners
+func (e *Engine) loadPartners(setup *fightSetup, fight database.Fight, effectDate time.Time, record bool) error {
+	partner1, err := e.repo.GetFighter(fight.Partner1ID)
+	if err != nil {
+		return err
+	}
+	partner2, err := e.repo.GetFighter(fight.Partner2ID)
+	if err != nil {
+		return err
+	}
+
+	var effects1, effects2 []database.AppliedEffect
+	setup.partner1, effects1 = e.applyStatEffectsToFighter(*partner1, effectDate)
+	setup.partner2, effects2 = e.applyStatEffectsToFighter(*partner2, effectDate)
+	setup.partnerHealth1 = e.calculateFighterHealthForDate(partner1.ID, effectDate)
+	setup.partnerHealth2 = e.calculateFighterHealthForDate(partner2.ID, effectDate)
+
+	if record {
+		e.recordSnapshot(fight.ID, partner1Lane, setup.partner1, setup.partnerHealth1, effects1)
+		e.recordSnapshot(fight.ID, partner2Lane, setup.partner2, setup.partnerHealth2, effects2)
+	}
+	return nil
+}
+
+// lineup returns the setup's fighters as the rulesets see them
+func (s *fightSetup) lineup() Lineup {
+	return Lineup{Fighter1: s.fighter1, Fighter2: s.fighter2, Partner1: s.partner1, Partner2: s.partner2, Booth: s.booth}
+}
+
+// recordSnapshot persists a lane's opening fighter and the effects that shaped it
+func (e *Engine) recordSnapshot(fightID, lane int, fighter database.Fighter, health int, effects []database.AppliedEffect) {
+	payload, err := json.Marshal(fighterSnapshot{Fighter: fighter, Effects: effects})
+	if err != nil {
+		log.Printf("Fight %d: failed to encode lane %d snapshot: %v", fightID, lane, err)
+		return
+	}
+	if err := e.repo.RecordFighterSnapshot(fightID, lane, fighter.ID, health, string(payload)); err != nil {
+		log.Printf("Fight %d: failed to record lane %d snapshot: %v", fightID, lane, err)
+	}
+}
+
+// recordTickSalt draws and persists the secret tick salt for a fight at its
+// opening bell. A fight that can't record one runs unsalted rather than with a
+// salt nobody could replay.
+func (e *Engine) recordTickSalt(fightID int) int64 {
+	salt, err := utils.NewTickSalt()
+	if err != nil {
+		log.Printf("Fight %d: failed to draw tick salt: %v", fightID, err)
+		return 0
+	}
+	stored, err := e.repo.RecordTickSalt(fightID, salt)
+	if err != nil {
+		log.Printf("Fight %d: failed to record tick salt: %v", fightID, err)
+		return 0
+	}
+	return stored
+}
+
+// tickSeed is the seed a tick of this fight is resolved with
+func (s *fightSetup) tickSeed(fightID, tick int) int64 {
+	return utils.FightTickSeed(fightID, tick) + s.tickSalt
+}
+
+// recordClapHeal persists crowd healing delivered on a tick
+func (e *Engine) recordClapHeal(fightID, tick int, setup *fightSetup, heal1, heal2 int) {
+	if heal1 > 0 {
+		if err := e.repo.RecordClapHeal(fightID, tick, 1, setup.fighter1.ID, heal1); err != nil {
+			log.Printf("Fight %d: failed to record clap heal at tick %d: %v", fightID, tick, err)
+		}
+	}
+	if heal2 > 0 {
+		if err := e.repo.RecordClapHeal(fightID, tick, 2, setup.fighter2.ID, heal2); err != nil {
+			log.Printf("Fight %d: failed to record clap heal at tick %d: %v", fightID, tick, err)
+		}
+	}
+	setup.clapHeals[tick] = [2]int{heal1, heal2}
+}
+
+// clapHeal returns the recorded crowd healing for each lane on a tick
+func (s *fightSetup) clapHeal(tick int) (int, int) {
+	heals := s.clapHeals[tick]
+	return heals[0], heals[1]
+}
+
+// openingState builds the tick-zero state for the setup's fighters
+func (s *fightSetup) openingState() *FightState {
+	state := &FightState{
+		Fighter1Health: s.health1,
+		Fighter2Health: s.health2,
+		TickNumber:     0,
+		CurrentRound:   1,
+		SimFighter1ID:  s.fighter1.ID,
+		SimFighter2ID:  s.fighter2.ID,
+	}
+	if s.partner1.ID != 0 && s.partner2.ID != 0 {
+		state.Tag = openingTag(s)
+		if s.tagShared {
+			state.Fighter1Health += s.partnerHealth1
+			state.Fighter2Health += s.partnerHealth2
+		}
+	}
+	return state
+}
+
+// finalLane is how one fighter came out of a completed fight
+type finalLane struct {
+	fighter     *database.Fighter
+	startHealth int
+	endHealth   int
+}
+
+// finalLanes reloads every fighter of a completed fight alongside the health
+// they opened with and the health they finished on
+func (e *Engine) finalLanes(fight database.Fight, state *FightState) ([]finalLane, error) {
+	fighter1, err := e.repo.GetFighter(state.SimFighter1ID)
+	if err != nil {
+		return nil, err
+	}
+	fighter2, err := e.repo.GetFighter(state.SimFighter2ID)
+	if err != nil {
+		return nil, err
+	}
+
+	setup := e.loadFightSetup(fight, *fighter1, *fighter2, fight.ScheduledTime.In(e.clock().Location()), false)
+	if !state.Tag.Enabled() {
+		return []finalLane{
+			{fighter1, setup.health1, state.Fighter1Health},
+			{fighter2, setup.health2, state.Fighter2Health},
+		}, nil
+	}
+
+	partner1, err := e.repo.GetFighter(fight.Partner1ID)
+	if err != nil {
+		return nil, err
+	}
+	partner2, err := e.repo.GetFighter(fight.Partner2ID)
+	if err != nil {
+		return nil, err
+	}
+	return []finalLane{
+		{fighter1, setup.health1, state.Tag.healthOf(state, 0, 0)},
+		{fighter2, setup.health2, state.Tag.healthOf(state, 1, 0)},
+		{partner1, setup.partnerHealth1, state.Tag.healthOf(state, 0, 1)},
+		{partner2, setup.partnerHealth2, state.Tag.healthOf(state, 1, 1)},
+	}, nil
+}
@@ -0,0 +1,121 @@
+package fight
+
+import (
+	"math/rand"
+
+	"spoodblort/database"
+	"spoodblort/utils"
+)
+
+// LegacyVersion is stamped on fights that ran before algorithm versions existed
+const LegacyVersion = database.LegacyAlgoVersion
+
+// LegacyRuleset reproduces the engine as it stood before versioning. Back then
+// a fight's opening ticks were simulated by a separate quiet catch-up path
+// whenever the server started or restarted mid-fight, and that path disagreed
+// with the live one: no undead frenzy, death rolled before the comeback crit,
+// lifesteal even off undead victims and no clap healing. Ticks up to
+// catchUpThrough follow the quiet path; the rest play as v1.
+//
+// The catch-up boundary was never recorded, so verification searches for it.
+type LegacyRuleset struct {
+	StandardRuleset
+	catchUpThrough int
+}
+
+// NewLegacyRuleset builds the legacy ruleset with no catch-up ticks
+func NewLegacyRuleset() LegacyRuleset {
+	return LegacyRuleset{StandardRuleset: NewStandardRuleset(LegacyVersion, StandardTuningV1)}
+}
+
+// WithCatchUp returns a copy that plays ticks 1..ticks through the quiet catch-up path
+func (r LegacyRuleset) WithCatchUp(ticks int) LegacyRuleset {
+	r.catchUpThrough = ticks
+	return r
+}
+
+// CatchUpThrough is the last tick played through the quiet catch-up path
+func (r LegacyRuleset) CatchUpThrough() int { return r.catchUpThrough }
+
+// ResolveTick runs one combat tick through whichever path the old engine used for it
+func (r LegacyRuleset) ResolveTick(in TickInput, state *FightState, emit func(LiveAction)) {
+	if in.Tick <= r.catchUpThrough {
+		r.resolveCatchUpTick(in, state)
+		return
+	}
+	r.StandardRuleset.ResolveTick(in, state, emit)
+}
+
+// resolveCatchUpTick mirrors the old quiet catch-up tick, RNG draw for RNG draw
+func (r LegacyRuleset) resolveCatchUpTick(in TickInput, state *FightState) {
+	rng := utils.NewSeededRNG(in.Seed)
+	fighter1, fighter2 := in.Fighter1, in.Fighter2
+
+	fighter1Advantage := r.determineStatBasedAdvantage(fighter1, fighter2, rng)
+
+	baseDamage1 := r.calculateDamage(fighter1, rng)
+	baseDamage2 := r.calculateDamage(fighter2, rng)
+
+	var damage1, damage2 int
+	if fighter1Advantage {
+		damage2 = baseDamage1 + (baseDamage1 / 2)
+		damage1 = baseDamage2 / 3
+	} else {
+		damage1 = baseDamage2 + (baseDamage2 / 2)
+		damage2 = baseDamage1 / 3
+	}
+
+	state.Fighter1Health -= damage1
+	state.Fighter2Health -= damage2
+	state.LastDamage1 = damage1
+	state.LastDamage2 = damage2
+	state.TotalDamage += damage1 + damage2
+	state.TickNumber = in.Tick
+
+	// Death was rolled before the comeback crit on this path
+	if damage1 > 0 && r.checkDeath(rng) {
+		r.finish(in, state, fighter2, fighter1, true, nil)
+		return
+	}
+	if damage2 > 0 && r.checkDeath(rng) {
+		r.finish(in, state, fighter1, fighter2, true, nil)
+		return
+	}
+
+	healthDiff := state.Fighter2Health - state.Fighter1Health // positive means fighter1 is behind
+	if (healthDiff >= 3000 || healthDiff <= -3000) && rng.Intn(r.tuning.CritChance) == 0 {
+		if critDmg := r.calculateCritDamage(rng); critDmg > 0 {
+			if healthDiff > 0 {
+				if r.landCatchUpCrit(in, state, fighter1, fighter2, &state.Fighter1Health, &state.Fighter2Health, &state.LastDamage2, critDmg, rng) {
+					return
+				}
+			} else if r.landCatchUpCrit(in, state, fighter2, fighter1, &state.Fighter2Health, &state.Fighter1Health, &state.LastDamage1, critDmg, rng) {
+				return
+			}
+		}
+	}
+
+	if state.Fighter1Health <= 0 {
+		r.finish(in, state, fighter2, fighter1, false, nil)
+		return
+	}
+	if state.Fighter2Health <= 0 {
+		r.finish(in, state, fighter1, fighter2, false, nil)
+	}
+}
+
+// landCatchUpCrit is landCrit as the catch-up path had it: lifesteal works on anyone
+func (r LegacyRuleset) landCatchUpCrit(in TickInput, state *FightState, attacker, victim database.Fighter, attackerHealth, victimHealth, victimLastDamage *int, critDmg int, rng *rand.Rand) bool {
+	*victimHealth -= critDmg
+	*victimLastDamage += critDmg
+	state.TotalDamage += critDmg
+	state.Crits++
+	if heal := critDmg / 2; heal > 0 {
+		*attackerHealth = capHealth(*attackerHealth + heal)
+	}
+	if r.checkDeath(rng) {
+		r.finish(in, state, attacker, victim, true, nil)
+		return true
+	}
+	return false
+}
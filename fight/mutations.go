@@ -0,0 +1,122 @@
+package fight
+
+import (
+	"fmt"
+	"log"
+
+	"spoodblort/database"
+	"spoodblort/utils"
+)
+
+// Post-fight mutation odds, in percent
+const (
+	MutationChance           = 5  // any fighter walking out of a fight
+	MutationChanceHeavyHit   = 15 // took more than 80% of their health in damage
+	MutationChanceReanimated = 25 // already came back from the dead once
+)
+
+// mutation is a catalog entry: what happens to a fighter and how it reads
+type mutation struct {
+	Name        string
+	Description string
+	Changes     database.MutationStatChanges
+}
+
+// mutationCategory groups catalog entries and sets how often the group is rolled
+type mutationCategory struct {
+	Name      string
+	Weight    int
+	Mutations []mutation
+}
+
+// mutationCatalog is every mutation a fighter can come out of the ring with
+var mutationCatalog = []mutationCategory{
+	{Name: "physical", Weight: 40, Mutations: []mutation{
+		{"Grew Third Arm", "A third arm has arrived, fully committed to violence.", database.MutationStatChanges{Strength: 15, Fingers: 5}},
+		{"Sprouted Tail", "A tail. Nobody asked questions.", database.MutationStatChanges{Speed: 10, Technique: 5}},
+		{"Additional Legs", "Can no longer fit in normal clothing.", database.MutationStatChanges{Speed: 20, Technique: -10, Toes: 10}},
+		{"Developed Echolocation", "Sees in sound waves now.", database.MutationStatChanges{Technique: 25, Speed: -5}},
+		{"Gigantification", "Requires industrial-sized clothing.", database.MutationStatChanges{Strength: 30, Speed: -15, MolecularDensity: 0.5}},
+		{"Became Partially Hollow", "Echoes when walking.", database.MutationStatChanges{Endurance: -10, Speed: 20, MolecularDensity: -0.3}},
+	}},
+	{Name: "biological", Weight: 35, Mutations: []mutation{
+		{"Blood Became Pure Mathematics", "Bleeds proofs.", database.MutationStatChanges{Technique: 10, BloodType: "Pure Mathematics"}},
+		{"Blood Became Liquid Starlight", "Glows faintly after dark.", database.MutationStatChanges{Speed: 10, BloodType: "Liquid Starlight"}},
+		{"Blood Became Sentient", "The blood is named Bob and has opinions.", database.MutationStatChanges{Endurance: 15, BloodType: "Bob (my blood)"}},
+		{"Circulatory Efficiency", "Everything pumps exactly as intended. Suspicious.", database.MutationStatChanges{Endurance: 25, BloodType: "Optimized"}},
+		{"Photosynthetic Skin", "Slightly green. Powered by fluorescent lights.", database.MutationStatChanges{Endurance: 10}},
+		{"Temporal Displacement", "Ages backwards during fights.", database.MutationStatChanges{Speed: 5, Ancestors: -2}},
+		{"Accelerated Healing", "Regenerates between rounds.", database.MutationStatChanges{Endurance: 30, Strength: -5}},
+	}},
+	{Name: "mental", Weight: 20, Mutations: []mutation{
+		{"Existential Enlightenment", "Dread has left the building.", database.MutationStatChanges{ExistentialDread: -40, Technique: 5}},
+		{"Developed Precognition", "Can see three seconds into the future.", database.MutationStatChanges{Technique: 20, ExistentialDread: 10}},
+		{"Sees in Additional Dimensions", "Molecules now arrive from four directions at once.", database.MutationStatChanges{MolecularDensity: 1.0, Technique: 10}},
+		{"Became Philosophically Dense", "All attacks now deal existential damage.", database.MutationStatChanges{Strength: 10, ExistentialDread: 25}},
+	}},
+	{Name: "chaos", Weight: 5, Mutations: []mutation{
+		{"Became Department Asset", "Now under direct Commissioner oversight.", database.MutationStatChanges{Strength: 10, Speed: 10, Endurance: 10, Technique: 10}},
+		{"Filed Paperwork", "Bureaucratically immune to some amount of harm.", database.MutationStatChanges{Endurance: 20, ExistentialDread: 30}},
+		{"Duplicated Across Timelines", "Sometimes fights as two separate entities.", database.MutationStatChanges{Strength: 20, Speed: 20, Ancestors: 40, Horoscope: "Both"}},
+	}},
+}
+
+// mutationChance is a fighter's percent chance of mutating after a fight
+func mutationChance(fighter database.Fighter, startHealth, endHealth int) int {
+	chance := MutationChance
+	if startHealth > 0 && endHealth*5 < startHealth {
+		chance = MutationChanceHeavyHit
+	}
+	if fighter.IsUndead {
+		chance = MutationChanceReanimated
+	}
+	return chance
+}
+
+// rollMutations gives each surviving fighter a chance to mutate after a
+// completed fight. Rolls are seeded from the fight, so a fight always mutates
+// the same fighters the same way.
+func (e *Engine) rollMutations(fight database.Fight, state *FightState) {
+	lanes, err := e.finalLanes(fight, state)
+	if err != nil {
+		log.Printf("Fight %d: failed to load fighters for mutation rolls: %v", fight.ID, err)
+		return
+	}
+
+	for _, lane := range lanes {
+		fighter := lane.fighter
+		if fighter.IsDead {
+			continue
+		}
+
+		rng := utils.NewSeededRNG(utils.MutationSeed(fight.ID, fighter.ID))
+		if rng.Intn(100) >= mutationChance(*fighter, lane.startHealth, lane.endHealth) {
+			continue
+		}
+		category, m := pickMutation(rng.Intn)
+
+		if _, err := e.repo.MutateFighter(fighter.ID, fight.ID, category, m.Name, m.Description, database.MutationTriggerPostFight, m.Changes); err != nil {
+			log.Printf("Fight %d: failed to mutate %s: %v", fight.ID, fighter.Name, err)
+			continue
+		}
+		log.Printf("🧬 %s mutated after fight %d: %s (%s)", fighter.Name, fight.ID, m.Name, m.Changes.Summary())
+		e.logFightAction(fight.ID, fmt.Sprintf("🧬 %s has MUTATED: %s!", fighter.Name, m.Name))
+	}
+}
+
+// pickMutation draws a category by weight, then a mutation from it
+func pickMutation(intn func(int) int) (string, mutation) {
+	total := 0
+	for _, c := range mutationCatalog {
+		total += c.Weight
+	}
+	roll := intn(total)
+	for _, c := range mutationCatalog {
+		if roll < c.Weight {
+			return c.Name, c.Mutations[intn(len(c.Mutations))]
+		}
+		roll -= c.Weight
+	}
+	c := mutationCatalog[0]
+	return c.Name, c.Mutations[intn(len(c.Mutations))]
+}
@@ -0,0 +1,211 @@
+package fight
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"sync"
+	"time"
+
+	"spoodblort/database"
+	"spoodblort/utils"
+)
+
+// OddsSimulations is how many seeded fights are run to price a matchup
+const OddsSimulations = 2000
+
+// oddsSeedStride moves each pricing run's seeds well clear of the range
+// FightTickSeed hands out to real fights, so the odds never peek at the
+// exact fight that is going to be played
+const oddsSeedStride = int64(1) << 40
+
+// OddsEstimate is the outcome distribution of a batch of simulated fights
+type OddsEstimate struct {
+	Fighter1Win float64
+	Fighter2Win float64
+	Draw        float64
+	Death       float64
+	Simulations int
+}
+
+// EstimateOdds plays sims seeded copies of a fight forward from start under
+// rules and counts how they end. Every run is deterministic, so the same
+// lineup and starting state always produce the same price.
+func EstimateOdds(fightID int, rules Ruleset, lineup Lineup, start FightState, sims int) OddsEstimate {
+	return oddsFromEndings(start, simulateEndings(fightID, rules, lineup, start, sims))
+}
+
+// simulateEndings plays sims seeded copies of a fight forward from start and
+// returns how each one ended, in run order
+func simulateEndings(fightID int, rules Ruleset, lineup Lineup, start FightState, sims int) []FightState {
+	if sims <= 0 {
+		return nil
+	}
+	workers := runtime.NumCPU()
+	if workers > sims {
+		workers = sims
+	}
+	endings := make([]FightState, sims)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for run := w; run < sims; run += workers {
+				endings[run], _ = playOut(fightID, rules, lineup, start, int64(run+1)*oddsSeedStride)
+			}
+		}(w)
+	}
+	wg.Wait()
+	return endings
+}
+
+// oddsFromEndings counts the winners and deaths among simulated endings
+func oddsFromEndings(start FightState, endings []FightState) OddsEstimate {
+	if len(endings) == 0 {
+		return OddsEstimate{}
+	}
+	var win1, win2, draw, death int
+	for _, state := range endings {
+		switch state.WinnerID {
+		case start.SimFighter1ID:
+			win1++
+		case start.SimFighter2ID:
+			win2++
+		default:
+			draw++
+		}
+		if state.DeathOccurred {
+			death++
+		}
+	}
+	n := float64(len(endings))
+	return OddsEstimate{
+		Fighter1Win: float64(win1) / n,
+		Fighter2Win: float64(win2) / n,
+		Draw:        float64(draw) / n,
+		Death:       float64(death) / n,
+		Simulations: len(endings),
+	}
+}
+
+// playOut plays a fight forward from start to the end without emitting any
+// actions, with every tick seed shifted by salt. Reports whether the fight
+// went the distance and was handed to the judges.
+func playOut(fightID int, rules Ruleset, lineup Lineup, start FightState, salt int64) (FightState, bool) {
+	state := start
+	for state.TickNumber < MAX_FIGHT_TICKS && !state.IsComplete {
+		tick := state.TickNumber + 1
+		rules.ResolveTick(lineup.tickInput(fightID, tick, utils.FightTickSeed(fightID, tick)+salt), &state, nil)
+		state.TickNumber = tick
+		rules.AdvanceRound(&state, nil)
+	}
+	if state.IsComplete {
+		return state, false
+	}
+	rules.Decide(&state)
+	return state, true
+}
+
+// PriceFight prices a scheduled fight from today's blessings and curses, the
+// same stats the fight will open with, and stores the result
+func (e *Engine) PriceFight(fightID int) (*database.FightOdds, error) {
+	fight, err := e.repo.GetFight(fightID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fight %d: %w", fightID, err)
+	}
+	if fight.Status != "scheduled" {
+		return nil, fmt.Errorf("fight %d is %s, only scheduled fights are priced", fightID, fight.Status)
+	}
+	fighter1, err := e.repo.GetFighter(fight.Fighter1ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fighter1: %w", err)
+	}
+	fighter2, err := e.repo.GetFighter(fight.Fighter2ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fighter2: %w", err)
+	}
+
+	setup := e.loadFightSetup(*fight, *fighter1, *fighter2, e.clock().Now(), false)
+
+	rules := e.RulesetForFight(*fight)
+	opening := *setup.openingState()
+	endings := simulateEndings(fight.ID, rules, setup.lineup(), opening, OddsSimulations)
+	estimate := oddsFromEndings(opening, endings)
+
+	odds := database.FightOdds{
+		FightID:     fight.ID,
+		Fighter1Win: estimate.Fighter1Win,
+		Fighter2Win: estimate.Fighter2Win,
+		Draw:        estimate.Draw,
+		Death:       estimate.Death,
+		Simulations: estimate.Simulations,
+		Ruleset:     rules.Name(),
+		AlgoVersion: rules.Version(),
+	}
+	if err := e.repo.SaveFightOdds(odds); err != nil {
+		return nil, fmt.Errorf("failed to save odds for fight %d: %w", fight.ID, err)
+	}
+
+	// The same simulations price the prop markets
+	if err := e.repo.SaveFightPropOdds(fight.ID, PriceProps(endings)); err != nil {
+		log.Printf("Failed to save prop odds for fight %d: %v", fight.ID, err)
+	}
+
+	log.Printf("Priced fight %d: %s %.1f%% / %s %.1f%% / draw %.1f%% (death %.1f%%)",
+		fight.ID, fight.Fighter1Name, odds.Fighter1Win*100, fight.Fighter2Name, odds.Fighter2Win*100, odds.Draw*100, odds.Death*100)
+	return e.repo.GetFightOdds(fight.ID)
+}
+
+// RepriceFight re-prices a fight in the background. Requests that arrive while
+// a run for the same fight is in flight fold into a single follow-up run, so a
+// burst of blessings costs at most two pricing runs.
+func (e *Engine) RepriceFight(fightID int) {
+	e.pricingMutex.Lock()
+	if _, running := e.pricing[fightID]; running {
+		e.pricing[fightID] = true
+		e.pricingMutex.Unlock()
+		return
+	}
+	e.pricing[fightID] = false
+	e.pricingMutex.Unlock()
+
+	go func() {
+		for {
+			if _, err := e.PriceFight(fightID); err != nil {
+				log.Printf("Failed to price fight %d: %v", fightID, err)
+			}
+
+			e.pricingMutex.Lock()
+			if !e.pricing[fightID] {
+				delete(e.pricing, fightID)
+				e.pricingMutex.Unlock()
+				return
+			}
+			e.pricing[fightID] = false
+			e.pricingMutex.Unlock()
+		}
+	}()
+}
+
+// PriceUpcomingFights prices any of today's scheduled fights that have no odds
+// yet. Only one sweep runs at a time; overlapping calls return immediately.
+func (e *Engine) PriceUpcomingFights(now time.Time) {
+	if !e.pricingSweep.CompareAndSwap(false, true) {
+		return
+	}
+	defer e.pricingSweep.Store(false)
+
+	today, tomorrow := utils.GetDayBounds(now)
+	fights, err := e.repo.GetUnpricedScheduledFights(today, tomorrow)
+	if err != nil {
+		log.Printf("Failed to get unpriced fights: %v", err)
+		return
+	}
+	for _, fight := range fights {
+		if _, err := e.PriceFight(fight.ID); err != nil {
+			log.Printf("Failed to price fight %d: %v", fight.ID, err)
+		}
+	}
+}
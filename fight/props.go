@@ -0,0 +1,128 @@
+package fight
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"spoodblort/database"
+)
+
+// Prop market tuning. Props are priced off the same simulations as the fight
+// itself but are noisier at the edges, so they carry a wider margin.
+const (
+	PropMarginPct = 8
+	PropMinOdds   = 1.01  // selections that would pay less than this aren't offered
+	PropMaxOdds   = 100.0 // longshots are capped here
+)
+
+// PropOutcome reduces a finished fight to what the prop markets settle on
+func PropOutcome(state FightState) database.PropOutcome {
+	outcome := database.PropOutcome{
+		TotalDamage: state.TotalDamage,
+		Death:       state.DeathOccurred,
+		Frenzy:      state.Frenzies > 0,
+	}
+	switch {
+	case state.DeathOccurred:
+		outcome.Method = database.PropMethodDeath
+	case state.WinnerID == 0:
+		outcome.Method = database.PropMethodDraw
+	case state.Fighter1Health <= 0 || state.Fighter2Health <= 0:
+		outcome.Method = database.PropMethodKO
+	default:
+		outcome.Method = database.PropMethodDecision
+	}
+	if outcome.Method == database.PropMethodDeath || outcome.Method == database.PropMethodKO {
+		// Rounds roll over after the deciding tick, so count from the tick itself
+		outcome.Round = (state.TickNumber-1)/TICKS_PER_ROUND + 1
+	}
+	return outcome
+}
+
+// PriceProps prices every prop selection from a batch of simulated endings.
+// The damage line sits in the middle of the thousand the median total falls
+// in. A total landing exactly on it pushes and refunds both sides, so over and
+// under are priced on the endings that didn't.
+func PriceProps(endings []FightState) []database.FightPropOdds {
+	if len(endings) == 0 {
+		return nil
+	}
+	outcomes := make([]database.PropOutcome, len(endings))
+	damages := make([]int, len(endings))
+	for i, state := range endings {
+		outcomes[i] = PropOutcome(state)
+		damages[i] = outcomes[i].TotalDamage
+	}
+	sort.Ints(damages)
+	line := damages[len(damages)/2]/1000*1000 + 500
+
+	var prices []database.FightPropOdds
+	for _, market := range database.PropMarkets {
+		marketLine := 0
+		if market == database.PropMarketDamage {
+			marketLine = line
+		}
+		settled := 0
+		for _, o := range outcomes {
+			if !o.Pushes(market, marketLine) {
+				settled++
+			}
+		}
+		for _, selection := range database.PropSelections(market) {
+			hits := 0
+			for _, o := range outcomes {
+				if o.Wins(market, selection, marketLine) {
+					hits++
+				}
+			}
+			p := 0.0
+			if settled > 0 {
+				p = float64(hits) / float64(settled)
+			}
+			prices = append(prices, database.FightPropOdds{
+				Market:      market,
+				Selection:   selection,
+				Line:        marketLine,
+				Probability: p,
+				Odds:        propOdds(p),
+			})
+		}
+	}
+	return prices
+}
+
+// propOdds turns a probability into offered decimal odds, or zero when the
+// selection is too likely (or never came up) to be worth offering
+func propOdds(p float64) float64 {
+	if p <= 0 {
+		return 0
+	}
+	odds := math.Floor((1/p)*float64(100-PropMarginPct)) / 100
+	if odds < PropMinOdds {
+		return 0
+	}
+	return math.Min(odds, PropMaxOdds)
+}
+
+// PlacePropBet backs a prop selection on a scheduled fight at its current price
+func (e *Engine) PlacePropBet(userID, fightID int, market, selection string, amount int) (database.FightPropOdds, int, error) {
+	prices, err := e.repo.GetFightPropOdds(fightID)
+	if err != nil {
+		return database.FightPropOdds{}, 0, err
+	}
+	for _, price := range prices {
+		if price.Market != market || price.Selection != selection {
+			continue
+		}
+		if price.Odds == 0 {
+			return price, 0, fmt.Errorf("that selection isn't being offered")
+		}
+		betID, err := e.repo.CreatePropBet(userID, fightID, market, selection, price.Line, amount, price.Odds)
+		return price, betID, err
+	}
+	if len(prices) == 0 {
+		return database.FightPropOdds{}, 0, fmt.Errorf("props for fight %d haven't been priced yet", fightID)
+	}
+	return database.FightPropOdds{}, 0, fmt.Errorf("unknown prop %s %s", market, selection)
+}
@@ -0,0 +1,540 @@
+package fight
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"spoodblort/database"
+	"spoodblort/utils"
+)
+
+// Battle royale tuning
+const (
+	RoyaleOddsSimulations = 400             // simulations behind a royale's prices
+	RoyaleQuoteTTL        = 5 * time.Minute // how long a royale price is reused
+	royaleVultureChance   = 3               // 1 in 3 fighters go for the weakest target
+	royalePairStride      = int64(1) << 20  // keeps each duel's seed apart within a tick
+)
+
+// RoyaleLane is one fighter's standing in a battle royale
+type RoyaleLane struct {
+	FighterID int    `json:"fighter_id"`
+	Name      string `json:"name"`
+	Health    int    `json:"health"`
+	Placement int    `json:"placement,omitempty"` // 0 while still standing
+}
+
+// RoyaleState is a battle royale between ticks
+type RoyaleState struct {
+	TickNumber   int
+	CurrentRound int
+	Lanes        []RoyaleLane
+	Remaining    int
+	IsComplete   bool
+	Finishers    []RoyaleLane // still standing at the end, from first place down
+}
+
+// RoyaleElimination is one fighter going out of a royale
+type RoyaleElimination struct {
+	FighterID int
+	KillerID  int // 0 when nobody in particular put them out
+	Placement int
+	Round     int
+	Tick      int
+	Health    int
+	Died      bool
+}
+
+// RoyaleAction is a LiveAction from a royale carrying everyone's standing
+type RoyaleAction struct {
+	LiveAction
+	Standings []RoyaleLane `json:"standings"`
+}
+
+// RoyaleBroadcaster is implemented by broadcasters that carry battle royales
+type RoyaleBroadcaster interface {
+	BroadcastRoyaleAction(royaleID int, action RoyaleAction)
+}
+
+// royaleSetup is the fighters a royale opens with, in slot order
+type royaleSetup struct {
+	royale   database.BattleRoyale
+	fighters []database.Fighter
+	opening  RoyaleState
+}
+
+// tickSeed is the seed a tick of the royale is resolved with
+func (s *royaleSetup) tickSeed(tick int) int64 {
+	return utils.RoyaleTickSeed(s.royale.ID, tick) + s.royale.TickSalt
+}
+
+// RulesetForRoyale returns the ruleset a royale's duels are fought under
+func (e *Engine) RulesetForRoyale(royale database.BattleRoyale) Ruleset {
+	name := royale.Ruleset
+	if name == "" {
+		name = DefaultRulesetName
+	}
+	rules, err := LookupRuleset(name, royale.AlgoVersion)
+	if err != nil {
+		log.Printf("Battle royale %d: %v, falling back to current %s", royale.ID, err, DefaultRulesetName)
+		rules, _ = LookupRuleset(DefaultRulesetName, "")
+	}
+	return rules
+}
+
+// loadRoyaleSetup applies the effects of effectDate to every entrant
+func (e *Engine) loadRoyaleSetup(royaleID int, effectDate time.Time) (*royaleSetup, error) {
+	royale, err := e.repo.GetBattleRoyale(royaleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get battle royale %d: %w", royaleID, err)
+	}
+	entrants, err := e.repo.GetBattleRoyaleEntrants(royaleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entrants for battle royale %d: %w", royaleID, err)
+	}
+
+	setup := &royaleSetup{royale: *royale}
+	setup.opening = RoyaleState{CurrentRound: 1, Remaining: len(entrants)}
+	for _, entrant := range entrants {
+		fighter, err := e.repo.GetFighter(entrant.FighterID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get entrant %d: %w", entrant.FighterID, err)
+		}
+		modified, _ := e.applyStatEffectsToFighter(*fighter, effectDate)
+		setup.fighters = append(setup.fighters, modified)
+		setup.opening.Lanes = append(setup.opening.Lanes, RoyaleLane{
+			FighterID: fighter.ID,
+			Name:      fighter.Name,
+			Health:    e.calculateFighterHealthForDate(fighter.ID, effectDate),
+		})
+	}
+	return setup, nil
+}
+
+// playRoyaleTick pairs up the fighters still standing and runs one exchange
+// for each pair under rules. Each fighter picks a target among those not yet
+// engaged this tick, usually at random but sometimes the weakest in reach.
+func playRoyaleTick(royaleID int, rules Ruleset, fighters []database.Fighter, state *RoyaleState, seed int64, emit func(LiveAction), eliminated func(RoyaleElimination)) {
+	tick := state.TickNumber + 1
+	rng := utils.NewSeededRNG(seed)
+
+	engaged := make([]bool, len(state.Lanes))
+	pair := 0
+	for _, i := range rng.Perm(len(state.Lanes)) {
+		if engaged[i] || state.Lanes[i].Placement != 0 {
+			continue
+		}
+		var targets []int
+		for j, lane := range state.Lanes {
+			if j != i && !engaged[j] && lane.Placement == 0 {
+				targets = append(targets, j)
+			}
+		}
+		if len(targets) == 0 {
+			break
+		}
+		j := targets[rng.Intn(len(targets))]
+		if rng.Intn(royaleVultureChance) == 0 {
+			for _, t := range targets {
+				if state.Lanes[t].Health < state.Lanes[j].Health {
+					j = t
+				}
+			}
+		}
+		engaged[i], engaged[j] = true, true
+		pair++
+
+		a, b := &state.Lanes[i], &state.Lanes[j]
+		duel := FightState{
+			Fighter1Health: a.Health,
+			Fighter2Health: b.Health,
+			TickNumber:     state.TickNumber,
+			CurrentRound:   state.CurrentRound,
+			SimFighter1ID:  a.FighterID,
+			SimFighter2ID:  b.FighterID,
+		}
+		// A knockout inside a duel is announced as an elimination instead
+		var sink func(LiveAction)
+		if emit != nil {
+			sink = func(action LiveAction) {
+				if action.Type != "death" {
+					emit(action)
+				}
+			}
+		}
+		rules.ResolveTick(TickInput{
+			FightID:  royaleID,
+			Tick:     tick,
+			Seed:     seed + int64(pair)*royalePairStride,
+			Fighter1: fighters[i],
+			Fighter2: fighters[j],
+		}, &duel, sink)
+		a.Health, b.Health = duel.Fighter1Health, duel.Fighter2Health
+
+		if duel.IsComplete {
+			loser, winner := a, b
+			if duel.WinnerID == a.FighterID {
+				loser, winner = b, a
+			}
+			state.eliminate(loser, winner.FighterID, tick, duel.DeathOccurred, emit, eliminated)
+			// Both can go down in the same exchange
+			if winner.Health <= 0 {
+				state.eliminate(winner, loser.FighterID, tick, false, emit, eliminated)
+			}
+		}
+	}
+
+	state.TickNumber = tick
+	if state.Remaining <= 1 {
+		state.finish(emit)
+		return
+	}
+	if tick%TICKS_PER_ROUND == 0 {
+		state.CurrentRound++
+		if emit != nil {
+			action := GenerateRoundAction(state.CurrentRound, 0, 0)
+			action.TickNumber = tick
+			emit(action)
+		}
+	}
+}
+
+// eliminate places a fighter and announces it
+func (s *RoyaleState) eliminate(lane *RoyaleLane, killerID, tick int, died bool, emit func(LiveAction), eliminated func(RoyaleElimination)) {
+	lane.Placement = s.Remaining
+	s.Remaining--
+
+	killerName := ""
+	for _, other := range s.Lanes {
+		if other.FighterID == killerID {
+			killerName = other.Name
+		}
+	}
+	if emit != nil {
+		emit(GenerateEliminationAction(tick, lane.Name, killerName, lane.Placement, s.Remaining, died, s.CurrentRound))
+	}
+	if eliminated != nil {
+		eliminated(RoyaleElimination{
+			FighterID: lane.FighterID,
+			KillerID:  killerID,
+			Placement: lane.Placement,
+			Round:     s.CurrentRound,
+			Tick:      tick,
+			Health:    lane.Health,
+			Died:      died,
+		})
+	}
+}
+
+// finish places whoever is still standing by health, most first, and ends the royale
+func (s *RoyaleState) finish(emit func(LiveAction)) {
+	s.Finishers = s.ranked()
+	for _, lane := range s.Finishers {
+		for i := range s.Lanes {
+			if s.Lanes[i].FighterID == lane.FighterID {
+				s.Lanes[i].Placement = lane.Placement
+			}
+		}
+	}
+	s.Remaining = 0
+	s.IsComplete = true
+	if emit != nil {
+		if winner := s.Winner(); winner != nil {
+			emit(GenerateRoyaleWinnerAction(s.TickNumber, winner.Name, s.CurrentRound))
+		}
+	}
+}
+
+// ranked returns the fighters still in the royale ranked by health, placed
+// from first down
+func (s *RoyaleState) ranked() []RoyaleLane {
+	var standing []RoyaleLane
+	for _, lane := range s.Lanes {
+		if lane.Placement == 0 {
+			standing = append(standing, lane)
+		}
+	}
+	sort.SliceStable(standing, func(i, j int) bool { return standing[i].Health > standing[j].Health })
+	for i := range standing {
+		standing[i].Placement = i + 1
+	}
+	return standing
+}
+
+// Winner returns the first-placed fighter of a finished royale
+func (s *RoyaleState) Winner() *RoyaleLane {
+	for i := range s.Lanes {
+		if s.Lanes[i].Placement == 1 {
+			return &s.Lanes[i]
+		}
+	}
+	return nil
+}
+
+// copyState gives an independent copy of a royale state
+func (s RoyaleState) copyState() RoyaleState {
+	s.Lanes = append([]RoyaleLane(nil), s.Lanes...)
+	return s
+}
+
+// SimulateRoyale plays a royale from its opening bell to the end without
+// writing anything, under the tick salt it was run with
+func (e *Engine) SimulateRoyale(royaleID int) (*RoyaleState, error) {
+	royale, err := e.repo.GetBattleRoyale(royaleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get battle royale %d: %w", royaleID, err)
+	}
+	setup, err := e.loadRoyaleSetup(royaleID, royale.ScheduledTime)
+	if err != nil {
+		return nil, err
+	}
+	rules := e.RulesetForRoyale(setup.royale)
+	state := setup.opening.copyState()
+	for !state.IsComplete {
+		playRoyaleTick(royaleID, rules, setup.fighters, &state, setup.tickSeed(state.TickNumber+1), nil, nil)
+		if !state.IsComplete && state.TickNumber >= MAX_FIGHT_TICKS {
+			state.finish(nil)
+		}
+	}
+	return &state, nil
+}
+
+// ProcessBattleRoyales starts any royale that is due, picks up active ones
+// that aren't running, such as after a restart, and prices the rest
+func (e *Engine) ProcessBattleRoyales(now time.Time) error {
+	if err := e.repo.ActivateDueBattleRoyales(now); err != nil {
+		return fmt.Errorf("failed to activate battle royales: %w", err)
+	}
+	royales, err := e.repo.GetUpcomingBattleRoyales()
+	if err != nil {
+		return fmt.Errorf("failed to get battle royales: %w", err)
+	}
+	for _, royale := range royales {
+		if royale.Status == "scheduled" {
+			// Pricing a royale takes seconds, so keep its quote warm for the betting page
+			go func(id int) {
+				if _, err := e.QuoteRoyale(id); err != nil {
+					log.Printf("Failed to price battle royale %d: %v", id, err)
+				}
+			}(royale.ID)
+			continue
+		}
+		e.simulationsMutex.Lock()
+		running := e.liveRoyales[royale.ID]
+		e.liveRoyales[royale.ID] = true
+		e.simulationsMutex.Unlock()
+		if !running {
+			go e.runRoyale(royale)
+		}
+	}
+	return nil
+}
+
+// runRoyale catches an active royale up to the present, then plays it out
+// live, recording every elimination as it happens
+func (e *Engine) runRoyale(royale database.BattleRoyale) {
+	defer func() {
+		e.simulationsMutex.Lock()
+		delete(e.liveRoyales, royale.ID)
+		e.simulationsMutex.Unlock()
+	}()
+
+	setup, err := e.loadRoyaleSetup(royale.ID, royale.ScheduledTime)
+	if err != nil {
+		log.Printf("Failed to start battle royale %d: %v", royale.ID, err)
+		return
+	}
+	rules := e.RulesetForRoyale(setup.royale)
+	state := setup.opening.copyState()
+	record := func(out RoyaleElimination) {
+		if err := e.repo.RecordRoyaleElimination(royale.ID, out.FighterID, out.KillerID, out.Placement, out.Round, out.Tick, out.Health, out.Died); err != nil {
+			log.Printf("Battle royale %d: failed to record elimination of fighter %d: %v", royale.ID, out.FighterID, err)
+		}
+	}
+	step := func(emit func(LiveAction)) {
+		playRoyaleTick(royale.ID, rules, setup.fighters, &state, setup.tickSeed(state.TickNumber+1), emit, record)
+		if !state.IsComplete && state.TickNumber >= MAX_FIGHT_TICKS {
+			state.finish(emit)
+		}
+	}
+
+	// Catch up quietly on ticks that passed while nobody was running it
+	elapsedTicks := int(e.clock().Since(royale.ScheduledTime).Seconds()) / TICK_DURATION_SECONDS
+	for !state.IsComplete && state.TickNumber < elapsedTicks {
+		step(nil)
+	}
+
+	log.Printf("Battle royale %d live from tick %d with %d fighters standing", royale.ID, state.TickNumber, state.Remaining)
+	rb, _ := e.broadcaster.(RoyaleBroadcaster)
+	emit := func(action LiveAction) {
+		if rb != nil {
+			rb.BroadcastRoyaleAction(royale.ID, RoyaleAction{LiveAction: action, Standings: append([]RoyaleLane(nil), state.Lanes...)})
+		}
+	}
+
+	ticker := e.clock().NewTicker(TICK_DURATION_SECONDS * time.Second)
+	defer ticker.Stop()
+	for !state.IsComplete {
+		<-ticker.C
+		step(emit)
+	}
+
+	// Eliminated fighters are already placed; the finishers are placed with the result
+	standing := make([]database.RoyaleEntrant, 0, len(state.Finishers))
+	for _, lane := range state.Finishers {
+		standing = append(standing, database.RoyaleEntrant{FighterID: lane.FighterID, FinalHealth: lane.Health})
+	}
+	if err := e.repo.CompleteBattleRoyale(royale.ID, standing); err != nil {
+		log.Printf("Failed to complete battle royale %d: %v", royale.ID, err)
+		return
+	}
+	if winner := state.Winner(); winner != nil {
+		log.Printf("👑 Battle royale %d won by %s", royale.ID, winner.Name)
+	}
+}
+
+// RoyaleOdds is the price of one royale entrant in each market
+type RoyaleOdds struct {
+	FighterID  int     `json:"fighter_id"`
+	Name       string  `json:"name"`
+	WinChance  float64 `json:"win_chance"`
+	Top3Chance float64 `json:"top3_chance"`
+	WinnerOdds float64 `json:"winner_odds"` // decimal odds, 0 when the fighter can't be backed
+	Top3Odds   float64 `json:"top3_odds"`
+}
+
+// RoyaleQuote prices every entrant of a scheduled royale
+type RoyaleQuote struct {
+	RoyaleID    int          `json:"royale_id"`
+	Entrants    []RoyaleOdds `json:"entrants"`
+	Simulations int          `json:"simulations"`
+	PricedAt    time.Time    `json:"priced_at"`
+}
+
+// OddsFor returns the decimal odds offered on fighterID in a bet type's market
+func (q RoyaleQuote) OddsFor(fighterID int, betType string) (float64, bool) {
+	for _, entrant := range q.Entrants {
+		if entrant.FighterID != fighterID {
+			continue
+		}
+		if betType == database.RoyaleBetTop3 {
+			return entrant.Top3Odds, true
+		}
+		return entrant.WinnerOdds, true
+	}
+	return 0, false
+}
+
+// EstimateRoyaleOdds plays sims seeded copies of a royale from start and
+// counts how often each entrant wins and places in the top 3
+func EstimateRoyaleOdds(royaleID int, rules Ruleset, fighters []database.Fighter, start RoyaleState, sims int) []RoyaleOdds {
+	odds := make([]RoyaleOdds, len(start.Lanes))
+	for i, lane := range start.Lanes {
+		odds[i] = RoyaleOdds{FighterID: lane.FighterID, Name: lane.Name}
+	}
+	if sims <= 0 {
+		return odds
+	}
+
+	type tally struct{ win, top3 []int }
+	workers := min(runtime.NumCPU(), sims)
+	tallies := make([]tally, workers)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			t := &tallies[w]
+			t.win, t.top3 = make([]int, len(odds)), make([]int, len(odds))
+			for run := w; run < sims; run += workers {
+				state := start.copyState()
+				salt := int64(run+1) * oddsSeedStride
+				for !state.IsComplete {
+					playRoyaleTick(royaleID, rules, fighters, &state, utils.RoyaleTickSeed(royaleID, state.TickNumber+1)+salt, nil, nil)
+					if !state.IsComplete && state.TickNumber >= MAX_FIGHT_TICKS {
+						state.finish(nil)
+					}
+				}
+				for i, lane := range state.Lanes {
+					if lane.Placement == 1 {
+						t.win[i]++
+					}
+					if lane.Placement >= 1 && lane.Placement <= 3 {
+						t.top3[i]++
+					}
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	n := float64(sims)
+	for i := range odds {
+		wins, top3 := 0, 0
+		for _, t := range tallies {
+			wins += t.win[i]
+			top3 += t.top3[i]
+		}
+		odds[i].WinChance = float64(wins) / n
+		odds[i].Top3Chance = float64(top3) / n
+		odds[i].WinnerOdds = offeredOdds(odds[i].WinChance)
+		odds[i].Top3Odds = offeredOdds(odds[i].Top3Chance)
+	}
+	return odds
+}
+
+// QuoteRoyale prices a scheduled royale from today's blessings and curses.
+// Prices are reused for RoyaleQuoteTTL since a full royale is costly to simulate.
+func (e *Engine) QuoteRoyale(royaleID int) (*RoyaleQuote, error) {
+	e.quotesMutex.Lock()
+	defer e.quotesMutex.Unlock()
+	if quote, ok := e.royaleQuotes[royaleID]; ok && e.clock().Since(quote.PricedAt) < RoyaleQuoteTTL {
+		return quote, nil
+	}
+
+	setup, err := e.loadRoyaleSetup(royaleID, e.clock().Now())
+	if err != nil {
+		return nil, err
+	}
+	if setup.royale.Status != "scheduled" {
+		return nil, fmt.Errorf("battle royale %d is %s, only scheduled royales are priced", royaleID, setup.royale.Status)
+	}
+
+	quote := &RoyaleQuote{
+		RoyaleID:    royaleID,
+		Entrants:    EstimateRoyaleOdds(royaleID, e.RulesetForRoyale(setup.royale), setup.fighters, setup.opening, RoyaleOddsSimulations),
+		Simulations: RoyaleOddsSimulations,
+		PricedAt:    e.clock().Now(),
+	}
+	e.royaleQuotes[royaleID] = quote
+	return quote, nil
+}
+
+// PlaceRoyaleBet backs a fighter to win a scheduled royale or to place in its
+// top 3, at the current price
+func (e *Engine) PlaceRoyaleBet(userID, royaleID, fighterID int, betType string, amount int) (float64, int, error) {
+	if betType != database.RoyaleBetWinner && betType != database.RoyaleBetTop3 {
+		return 0, 0, fmt.Errorf("unknown bet type %q", betType)
+	}
+	quote, err := e.QuoteRoyale(royaleID)
+	if err != nil {
+		return 0, 0, err
+	}
+	odds, ok := quote.OddsFor(fighterID, betType)
+	if !ok {
+		return 0, 0, fmt.Errorf("fighter %d is not in battle royale %d", fighterID, royaleID)
+	}
+	if odds == 0 {
+		return 0, 0, fmt.Errorf("no price available on that fighter")
+	}
+	betID, err := e.repo.CreateRoyaleBet(userID, royaleID, fighterID, betType, amount, odds)
+	if err != nil {
+		return 0, 0, err
+	}
+	return odds, betID, nil
+}
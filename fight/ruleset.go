@@ -0,0 +1,507 @@
+package fight
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"spoodblort/database"
+	"spoodblort/utils"
+)
+
+// DefaultRulesetName is used when neither the fight nor its tournament picks a ruleset
+const DefaultRulesetName = "standard"
+
+// TICKS_PER_ROUND is how many ticks make up one round
+const TICKS_PER_ROUND = 6
+
+// TickInput carries everything a ruleset needs to resolve a single tick
+type TickInput struct {
+	FightID  int
+	Tick     int
+	Seed     int64
+	Fighter1 database.Fighter
+	Fighter2 database.Fighter
+	// Health handed to each lane by the crowd this tick (clap healing)
+	ClapHeal1 int
+	ClapHeal2 int
+	// Tag-team partners waiting on each lane's apron (zero values in singles)
+	Partner1 database.Fighter
+	Partner2 database.Fighter
+	// What the commentary booth knows about the fighters; nil for quiet simulations
+	Booth *BoothContext
+}
+
+// Lineup is every fighter taking part in a fight, by lane
+type Lineup struct {
+	Fighter1 database.Fighter
+	Fighter2 database.Fighter
+	Partner1 database.Fighter // tag-team partners; zero values in singles
+	Partner2 database.Fighter
+	Booth    *BoothContext // commentary context, only needed when actions are emitted
+}
+
+// tickInput builds the input for one tick of a fight between the lineup
+func (l Lineup) tickInput(fightID, tick int, seed int64) TickInput {
+	return TickInput{
+		FightID:  fightID,
+		Tick:     tick,
+		Seed:     seed,
+		Fighter1: l.Fighter1,
+		Fighter2: l.Fighter2,
+		Partner1: l.Partner1,
+		Partner2: l.Partner2,
+		Booth:    l.Booth,
+	}
+}
+
+// Ruleset decides how a two-lane fight plays out. The engine owns timing,
+// persistence and broadcasting; the ruleset owns the combat math.
+//
+// A registered (name, version) pair must never change behaviour once fights
+// have run under it, otherwise those fights stop replaying bit-for-bit.
+// Tune the game by registering a new version instead.
+type Ruleset interface {
+	// Name is the identifier stored on fights and tournaments
+	Name() string
+	// Version is the algorithm version stamped on every fight run under this ruleset
+	Version() string
+	// ResolveTick applies one tick of combat to state. emit receives every
+	// LiveAction produced during the tick and is nil for quiet simulations.
+	ResolveTick(in TickInput, state *FightState, emit func(LiveAction))
+	// AdvanceRound runs after every tick and reports whether a new round began
+	AdvanceRound(state *FightState, emit func(LiveAction)) bool
+	// Decide settles a fight that went the full distance
+	Decide(state *FightState)
+}
+
+var (
+	rulesetsMu      sync.RWMutex
+	rulesets        = map[string]map[string]Ruleset{} // name -> version -> ruleset
+	currentVersions = map[string]string{}             // name -> version new fights run under
+)
+
+func init() {
+	RegisterRuleset(NewLegacyRuleset(), false)
+	RegisterRuleset(NewStandardRuleset("v1", StandardTuningV1), false)
+	RegisterRuleset(NewStandardRuleset("v2", StandardTuningV2), false)
+	RegisterRuleset(NewStandardRuleset("v3", StandardTuningV3), true)
+	RegisterRuleset(NewTagTeamRuleset("v1", NewStandardRuleset("v2", StandardTuningV2)), false)
+	RegisterRuleset(NewTagTeamRuleset("v2", NewStandardRuleset("v3", StandardTuningV3)), true)
+}
+
+// RegisterRuleset makes a ruleset version available for replay. When current
+// is true, new fights using this ruleset's name will run under this version.
+func RegisterRuleset(rules Ruleset, current bool) {
+	rulesetsMu.Lock()
+	defer rulesetsMu.Unlock()
+	versions, ok := rulesets[rules.Name()]
+	if !ok {
+		versions = map[string]Ruleset{}
+		rulesets[rules.Name()] = versions
+	}
+	versions[rules.Version()] = rules
+	if current || currentVersions[rules.Name()] == "" {
+		currentVersions[rules.Name()] = rules.Version()
+	}
+}
+
+// LookupRuleset returns the registered ruleset with the given name and
+// algorithm version. An empty version selects the current one.
+func LookupRuleset(name, version string) (Ruleset, error) {
+	rulesetsMu.RLock()
+	defer rulesetsMu.RUnlock()
+	versions, ok := rulesets[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown ruleset %q", name)
+	}
+	if version == "" {
+		version = currentVersions[name]
+	}
+	rules, ok := versions[version]
+	if !ok {
+		return nil, fmt.Errorf("ruleset %q has no algorithm version %q", name, version)
+	}
+	return rules, nil
+}
+
+// RulesetNames lists every registered ruleset, sorted
+func RulesetNames() []string {
+	rulesetsMu.RLock()
+	defer rulesetsMu.RUnlock()
+	names := make([]string, 0, len(rulesets))
+	for name := range rulesets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RulesetVersions lists every registered algorithm version of a ruleset, sorted
+func RulesetVersions(name string) []string {
+	rulesetsMu.RLock()
+	defer rulesetsMu.RUnlock()
+	versions := make([]string, 0, len(rulesets[name]))
+	for version := range rulesets[name] {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// StandardTuning holds the balance constants for one version of the standard ruleset
+type StandardTuning struct {
+	DeathChance int    // 1 in N chance of death per damage roll
+	CritChance  int    // 1 in N chance per tick for the trailing fighter to attempt a crit
+	MinDamage   int    // floor for a single base damage roll
+	MaxDamage   int    // ceiling for a single base damage roll (before strength)
+	CritTable   [6]int // comeback crit bonus damage indexed by natural 20s rolled on 5d20
+	// Undead frenzy: 1 in FrenzyChance per tick, multiplying damage taken by the
+	// opponent by FrenzyMinMult..FrenzyMaxMult
+	FrenzyChance  int
+	FrenzyMinMult int
+	FrenzyMaxMult int
+	// SpecialMoves lets each fighter class fire its signature move
+	SpecialMoves bool
+	// BinomialAdvantage settles stat contests with one draw instead of a coin per stat point
+	BinomialAdvantage bool
+}
+
+// StandardTuningV1 is the live tick path as it stood before versioning existed.
+// Fights from that era replay under LegacyVersion, which also covers the old
+// catch-up path. Frozen.
+var StandardTuningV1 = StandardTuning{
+	DeathChance:   100000,
+	CritChance:    2,
+	MinDamage:     10,
+	MaxDamage:     1000,
+	CritTable:     [6]int{0, 5000, 10000, 15000, 20000, 100000},
+	FrenzyChance:  4,
+	FrenzyMinMult: 2,
+	FrenzyMaxMult: 4,
+}
+
+// StandardTuningV2 adds class special moves driven by the chaos stats. Frozen.
+var StandardTuningV2 = StandardTuning{
+	DeathChance:   100000,
+	CritChance:    2,
+	MinDamage:     10,
+	MaxDamage:     1000,
+	CritTable:     [6]int{0, 5000, 10000, 15000, 20000, 100000},
+	FrenzyChance:  4,
+	FrenzyMinMult: 2,
+	FrenzyMaxMult: 4,
+	SpecialMoves:  true,
+}
+
+// StandardTuningV3 plays to the same odds as v2 but settles stat contests in constant
+// time, so inflated legacy stats no longer slow the engine down. Frozen.
+var StandardTuningV3 = StandardTuning{
+	DeathChance:       100000,
+	CritChance:        2,
+	MinDamage:         10,
+	MaxDamage:         1000,
+	CritTable:         [6]int{0, 5000, 10000, 15000, 20000, 100000},
+	FrenzyChance:      4,
+	FrenzyMinMult:     2,
+	FrenzyMaxMult:     4,
+	SpecialMoves:      true,
+	BinomialAdvantage: true,
+}
+
+// StandardRuleset is the classic Spoodblort ruleset: simultaneous exchanges
+// decided by stat coin flips, undead frenzies, comeback crits and the ever
+// present chance of death.
+type StandardRuleset struct {
+	version string
+	tuning  StandardTuning
+}
+
+// NewStandardRuleset builds a version of the standard ruleset with the given tuning
+func NewStandardRuleset(version string, tuning StandardTuning) StandardRuleset {
+	return StandardRuleset{version: version, tuning: tuning}
+}
+
+func (StandardRuleset) Name() string { return DefaultRulesetName }
+
+func (r StandardRuleset) Version() string { return r.version }
+
+// Tuning returns the balance constants this version runs with
+func (r StandardRuleset) Tuning() StandardTuning { return r.tuning }
+
+// ResolveTick runs one combat tick
+func (r StandardRuleset) ResolveTick(in TickInput, state *FightState, emit func(LiveAction)) {
+	rng := utils.NewSeededRNG(in.Seed)
+	fighter1, fighter2 := in.Fighter1, in.Fighter2
+
+	// Determine advantage using a random stat and coinflip aggregation
+	fighter1Advantage := r.determineStatBasedAdvantage(fighter1, fighter2, rng)
+
+	// Undead frenzy (25% chance). If frenzied, zero one stat and apply 2x/3x/4x multiplier to outgoing damage.
+	frenzy1Mult, frenzy1Zero := r.rollUndeadFrenzy(&fighter1, rng)
+	if frenzy1Zero != "" {
+		fighter1Advantage = r.determineStatBasedAdvantage(fighter1, fighter2, rng)
+	}
+	frenzy2Mult, frenzy2Zero := r.rollUndeadFrenzy(&fighter2, rng)
+	if frenzy2Zero != "" {
+		fighter1Advantage = r.determineStatBasedAdvantage(fighter1, fighter2, rng)
+	}
+
+	// Calculate base damage for both fighters (simultaneous combat)
+	baseDamage1 := r.calculateDamage(fighter1, rng) * frenzy2Mult
+	baseDamage2 := r.calculateDamage(fighter2, rng) * frenzy1Mult
+
+	var damage1, damage2 int
+	if fighter1Advantage {
+		damage2 = baseDamage1 + (baseDamage1 / 2) // Winner gets 150% damage
+		damage1 = baseDamage2 / 3                 // Loser deals 33% damage back
+	} else {
+		damage1 = baseDamage2 + (baseDamage2 / 2)
+		damage2 = baseDamage1 / 3
+	}
+
+	// Class special moves rewrite the exchange before it lands
+	var specials []*firedSpecial
+	heal1, heal2 := 0, 0
+	if r.tuning.SpecialMoves {
+		ex1 := exchange{Dealt: damage2, Taken: damage1}
+		if fired := rollSpecial(fighter1, fighter2, &ex1, rng); fired != nil {
+			specials = append(specials, fired)
+		}
+		ex2 := exchange{Dealt: ex1.Taken, Taken: ex1.Dealt}
+		if fired := rollSpecial(fighter2, fighter1, &ex2, rng); fired != nil {
+			specials = append(specials, fired)
+		}
+		damage1, damage2 = ex2.Dealt, ex2.Taken
+		heal1, heal2 = ex1.Heal, ex2.Heal
+	}
+
+	// Apply damage
+	state.Fighter1Health -= damage1
+	state.Fighter2Health -= damage2
+	state.LastDamage1 = damage1
+	state.LastDamage2 = damage2
+	state.TotalDamage += damage1 + damage2
+	if frenzy1Zero != "" {
+		state.Frenzies++
+	}
+	if frenzy2Zero != "" {
+		state.Frenzies++
+	}
+	state.TickNumber = in.Tick
+	if heal1 > 0 {
+		state.Fighter1Health = capHealth(state.Fighter1Health + heal1)
+	}
+	if heal2 > 0 {
+		state.Fighter2Health = capHealth(state.Fighter2Health + heal2)
+	}
+
+	// Apply aggregated clap healing after damage is applied for this tick
+	if in.ClapHeal1 > 0 {
+		state.Fighter1Health = capHealth(state.Fighter1Health + in.ClapHeal1)
+	}
+	if in.ClapHeal2 > 0 {
+		state.Fighter2Health = capHealth(state.Fighter2Health + in.ClapHeal2)
+	}
+
+	if emit != nil {
+		for _, fired := range specials {
+			emit(GenerateSpecialMoveAction(in.FightID, in.Tick, fired.Move, fired.User, fired.Foe, fired.Amount, state.Fighter1Health, state.Fighter2Health, state.CurrentRound, in.Booth))
+		}
+		action := GenerateLiveAction(in.FightID, in.Tick, fighter1, fighter2, damage1, damage2, state.Fighter1Health, state.Fighter2Health, state.CurrentRound, in.Booth)
+		if frenzy1Zero != "" {
+			action.Frenzy1, action.Frenzy1Mult, action.Frenzy1Zero = true, frenzy1Mult, frenzy1Zero
+		}
+		if frenzy2Zero != "" {
+			action.Frenzy2, action.Frenzy2Mult, action.Frenzy2Zero = true, frenzy2Mult, frenzy2Zero
+		}
+		emit(action)
+	}
+
+	// Comeback Critical: only if someone is losing by >= 3000 health
+	healthDiff := state.Fighter2Health - state.Fighter1Health // positive means fighter1 is behind
+	if (healthDiff >= 3000 || healthDiff <= -3000) && rng.Intn(r.tuning.CritChance) == 0 {
+		if critDmg := r.calculateCritDamage(rng); critDmg > 0 {
+			if healthDiff > 0 {
+				if r.landCrit(in, state, fighter1, fighter2, &state.Fighter1Health, &state.Fighter2Health, &state.LastDamage2, critDmg, rng, emit) {
+					return
+				}
+			} else if r.landCrit(in, state, fighter2, fighter1, &state.Fighter2Health, &state.Fighter1Health, &state.LastDamage1, critDmg, rng, emit) {
+				return
+			}
+		}
+	}
+
+	// Check for death (only if damage was dealt)
+	if damage1 > 0 && r.checkDeath(rng) {
+		r.finish(in, state, fighter2, fighter1, true, emit)
+		return
+	}
+	if damage2 > 0 && r.checkDeath(rng) {
+		r.finish(in, state, fighter1, fighter2, true, emit)
+		return
+	}
+
+	// Check for KO
+	if state.Fighter1Health <= 0 {
+		r.finish(in, state, fighter2, fighter1, false, emit)
+		return
+	}
+	if state.Fighter2Health <= 0 {
+		r.finish(in, state, fighter1, fighter2, false, emit)
+	}
+}
+
+// AdvanceRound moves to the next round every TICKS_PER_ROUND ticks
+func (StandardRuleset) AdvanceRound(state *FightState, emit func(LiveAction)) bool {
+	if state.TickNumber%TICKS_PER_ROUND != 0 {
+		return false
+	}
+	state.CurrentRound++
+	if emit != nil {
+		emit(GenerateRoundAction(state.CurrentRound, state.Fighter1Health, state.Fighter2Health))
+	}
+	return true
+}
+
+// Decide hands a fight that went the distance to the judges: most health wins,
+// exactly equal health is a draw
+func (StandardRuleset) Decide(state *FightState) {
+	if state.Fighter1Health > state.Fighter2Health {
+		state.WinnerID = state.SimFighter1ID
+	} else if state.Fighter2Health > state.Fighter1Health {
+		state.WinnerID = state.SimFighter2ID
+	}
+	state.IsComplete = true
+}
+
+// landCrit applies a comeback crit from attacker to victim and rolls the extra
+// death chance. Returns true if the victim died.
+func (r StandardRuleset) landCrit(in TickInput, state *FightState, attacker, victim database.Fighter, attackerHealth, victimHealth, victimLastDamage *int, critDmg int, rng *rand.Rand, emit func(LiveAction)) bool {
+	*victimHealth -= critDmg
+	*victimLastDamage += critDmg
+	state.TotalDamage += critDmg
+	state.Crits++
+	// Lifesteal: attacker recovers half the crit damage (capped), undead have nothing worth stealing
+	if !victim.IsUndead {
+		if heal := critDmg / 2; heal > 0 {
+			*attackerHealth = capHealth(*attackerHealth + heal)
+		}
+	}
+	if emit != nil {
+		emit(LiveAction{
+			Type:       "critical",
+			Action:     fmt.Sprintf("COMEBACK CRIT! %s detonates %s for %s bonus damage!", attacker.Name, victim.Name, formatNumber(critDmg)),
+			Damage:     critDmg,
+			Attacker:   attacker.Name,
+			Victim:     victim.Name,
+			Announcer:  "\"Screaming\" Sally Bloodworth",
+			Health1:    state.Fighter1Health,
+			Health2:    state.Fighter2Health,
+			Round:      state.CurrentRound,
+			TickNumber: in.Tick,
+		})
+	}
+	// Extra death chance due to crit damage
+	if r.checkDeath(rng) {
+		r.finish(in, state, attacker, victim, true, emit)
+		return true
+	}
+	return false
+}
+
+// finish ends the fight in winner's favour, by death or by KO
+func (StandardRuleset) finish(in TickInput, state *FightState, winner, loser database.Fighter, death bool, emit func(LiveAction)) {
+	state.DeathOccurred = death
+	state.IsComplete = true
+	state.WinnerID = winner.ID
+	if emit != nil {
+		emit(GenerateDeathAction(in.FightID, winner, loser, state.Fighter1Health, state.Fighter2Health, state.CurrentRound, in.Booth))
+	}
+}
+
+// rollUndeadFrenzy gives an undead fighter a 1 in FrenzyChance chance to frenzy.
+// A frenzy zeroes one stat and returns the damage multiplier and the stat that was zeroed.
+func (r StandardRuleset) rollUndeadFrenzy(fighter *database.Fighter, rng *rand.Rand) (int, string) {
+	if !fighter.IsUndead || rng.Intn(r.tuning.FrenzyChance) != 0 {
+		return 1, ""
+	}
+	var zeroed string
+	switch rng.Intn(4) {
+	case 0:
+		fighter.Strength, zeroed = 0, "strength"
+	case 1:
+		fighter.Speed, zeroed = 0, "speed"
+	case 2:
+		fighter.Endurance, zeroed = 0, "endurance"
+	default:
+		fighter.Technique, zeroed = 0, "technique"
+	}
+	return r.tuning.FrenzyMinMult + rng.Intn(r.tuning.FrenzyMaxMult-r.tuning.FrenzyMinMult+1), zeroed
+}
+
+// calculateDamage calculates damage dealt by winning fighter
+func (r StandardRuleset) calculateDamage(winner database.Fighter, rng *rand.Rand) int {
+	// Base damage with some randomness
+	baseDamage := r.tuning.MinDamage + rng.Intn(r.tuning.MaxDamage-r.tuning.MinDamage)
+
+	// Modify by strength (higher strength = more damage)
+	strengthMultiplier := 1.0 + (float64(winner.Strength)/100.0)*0.5
+
+	finalDamage := int(float64(baseDamage) * strengthMultiplier)
+
+	return int(math.Max(float64(r.tuning.MinDamage), float64(finalDamage)))
+}
+
+// calculateCritDamage rolls 5d20 and maps the number of natural 20s to bonus damage
+// via the tuning's crit table
+func (r StandardRuleset) calculateCritDamage(rng *rand.Rand) int {
+	successes := 0
+	for i := 0; i < 5; i++ {
+		roll := rng.Intn(20) + 1 // 1..20
+		if roll == 20 {          // natural 20 counts as a success
+			successes++
+		}
+	}
+	return r.tuning.CritTable[successes]
+}
+
+// checkDeath determines if death occurs this tick
+func (r StandardRuleset) checkDeath(rng *rand.Rand) bool {
+	return rng.Intn(r.tuning.DeathChance) == 0
+}
+
+// determineStatBasedAdvantage picks a random combat stat and gives advantage to the fighter
+// with more "heads" from coin flips equal to that stat value. Ties break randomly.
+// Versions with BinomialAdvantage settle the flips in a single draw.
+func (r StandardRuleset) determineStatBasedAdvantage(f1, f2 database.Fighter, rng *rand.Rand) bool {
+	// Choose a stat: 0=strength, 1=speed, 2=endurance, 3=technique
+	statIdx := rng.Intn(4)
+
+	var v1, v2 int
+	switch statIdx {
+	case 0:
+		v1, v2 = f1.Strength, f2.Strength
+	case 1:
+		v1, v2 = f1.Speed, f2.Speed
+	case 2:
+		v1, v2 = f1.Endurance, f2.Endurance
+	default:
+		v1, v2 = f1.Technique, f2.Technique
+	}
+
+	if r.tuning.BinomialAdvantage {
+		return binomialAdvantage(v1, v2, rng)
+	}
+	return coinFlipAdvantage(v1, v2, rng)
+}
+
+// capHealth keeps healing from pushing a lane above STARTING_HEALTH
+func capHealth(health int) int {
+	if health > STARTING_HEALTH {
+		return STARTING_HEALTH
+	}
+	return health
+}
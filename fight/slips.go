@@ -0,0 +1,81 @@
+package fight
+
+import (
+	"fmt"
+
+	"spoodblort/database"
+)
+
+// Parlay limits. Legs are priced like in-play bets, off the pre-fight
+// simulations, and the combined price is capped so a long slip can't print credits.
+const (
+	SlipMinLegs = 2
+	SlipMaxLegs = 8
+	SlipMaxOdds = 10000.0
+)
+
+// SlipPick is one leg a user wants on a slip
+type SlipPick struct {
+	FightID   int
+	FighterID int
+}
+
+// SlipLegOdds is the decimal price offered on fighterID winning a fight as a
+// parlay leg, or zero when the fight hasn't been priced or fighterID isn't in it
+func SlipLegOdds(f database.Fight, odds *database.FightOdds, fighterID int) float64 {
+	if odds == nil || odds.Simulations == 0 {
+		return 0
+	}
+	switch fighterID {
+	case f.Fighter1ID:
+		return offeredOdds(odds.Fighter1Win)
+	case f.Fighter2ID:
+		return offeredOdds(odds.Fighter2Win)
+	}
+	return 0
+}
+
+// PlaceBetSlip prices each pick off its fight's current odds and places the
+// parlay. Returns the slip ID and the combined odds it was accepted at.
+func (e *Engine) PlaceBetSlip(userID, stake int, picks []SlipPick) (int, float64, error) {
+	if len(picks) < SlipMinLegs || len(picks) > SlipMaxLegs {
+		return 0, 0, fmt.Errorf("a parlay needs between %d and %d legs", SlipMinLegs, SlipMaxLegs)
+	}
+
+	legs := make([]database.BetSlipLeg, 0, len(picks))
+	seen := map[int]bool{}
+	combined := 1.0
+	for _, pick := range picks {
+		if seen[pick.FightID] {
+			return 0, 0, fmt.Errorf("fight %d is on the slip twice", pick.FightID)
+		}
+		seen[pick.FightID] = true
+
+		f, err := e.repo.GetFight(pick.FightID)
+		if err != nil {
+			return 0, 0, fmt.Errorf("fight %d: %w", pick.FightID, err)
+		}
+		if f.Status != "scheduled" {
+			return 0, 0, fmt.Errorf("betting is closed for fight %d", f.ID)
+		}
+		odds, err := e.repo.GetFightOdds(f.ID)
+		if err != nil {
+			return 0, 0, fmt.Errorf("fight %d hasn't been priced yet", f.ID)
+		}
+		price := SlipLegOdds(*f, odds, pick.FighterID)
+		if price == 0 {
+			return 0, 0, fmt.Errorf("fighter %d isn't on offer in fight %d", pick.FighterID, f.ID)
+		}
+		combined *= price
+		legs = append(legs, database.BetSlipLeg{FightID: f.ID, FighterID: pick.FighterID, Odds: price})
+	}
+	if combined > SlipMaxOdds {
+		return 0, 0, fmt.Errorf("combined odds of %.2f are over the %.0f limit", combined, SlipMaxOdds)
+	}
+
+	slipID, err := e.repo.CreateBetSlip(userID, stake, legs)
+	if err != nil {
+		return 0, 0, err
+	}
+	return slipID, combined, nil
+}
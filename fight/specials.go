@@ -0,0 +1,181 @@
+package fight
+
+import (
+	"math/rand"
+	"strings"
+
+	"spoodblort/database"
+)
+
+// exchange is one lane's side of a tick: damage it deals, damage it takes and
+// health it gets back. Special moves rewrite it before the tick is applied.
+type exchange struct {
+	Dealt int
+	Taken int
+	Heal  int
+}
+
+// specialMove is a fighter class's signature move. How often it fires and how
+// hard it lands come from the fighter's chaos stats rather than combat stats.
+type specialMove struct {
+	Name  string
+	Lines []string // announcer lines; %s is the user, the second %s the foe
+	// chance is the per-tick trigger chance in tenths of a percent
+	chance func(user database.Fighter) int
+	// resolve rewrites the user's exchange and returns the amount to announce
+	resolve func(user, foe database.Fighter, ex *exchange, rng *rand.Rand) int
+}
+
+// specialMoves holds the signature move for each of the generated fighter
+// classes. Custom classes fall back to signatureFlurry.
+var specialMoves = map[string]specialMove{
+	"Emotional": {
+		Name: "EMOTIONAL DAMAGE",
+		Lines: []string{
+			"%s unloads years of unprocessed feelings onto %s!",
+			"%s makes it personal. %s did not consent to this vulnerability!",
+		},
+		// Dread fuels it: the more a fighter dreads, the more often and the harder it lands
+		chance: func(u database.Fighter) int { return 15 + clampStat(u.ExistentialDread)/4 },
+		resolve: func(u, _ database.Fighter, ex *exchange, _ *rand.Rand) int {
+			bonus := ex.Dealt * clampStat(u.ExistentialDread) / 100
+			ex.Dealt += bonus
+			return bonus
+		},
+	},
+	"Existential": {
+		Name: "STARE INTO THE VOID",
+		Lines: []string{
+			"%s stares into the void and the void stares at %s instead!",
+			"%s asks %s what any of this means. It drains them.",
+		},
+		chance: func(u database.Fighter) int { return 10 + clampStat(u.ExistentialDread)/5 },
+		// Drains more the more dread the user has over the foe, and keeps half of it
+		resolve: func(u, f database.Fighter, ex *exchange, _ *rand.Rand) int {
+			drain := 500 + max(0, clampStat(u.ExistentialDread)-clampStat(f.ExistentialDread))*50
+			ex.Dealt += drain
+			ex.Heal += drain / 2
+			return drain
+		},
+	},
+	"Conceptual": {
+		Name: "REDEFINE VIOLENCE",
+		Lines: []string{
+			"%s declares that %s's attack was merely a suggestion!",
+			"%s rewrites the dictionary entry for 'punch'. %s is baffled.",
+		},
+		// The less physical a fighter is, the easier it is to argue damage away
+		chance: func(u database.Fighter) int { return 10 + (100-clampStat(int(u.MolecularDensity)))/4 },
+		resolve: func(_, _ database.Fighter, ex *exchange, _ *rand.Rand) int {
+			negated := ex.Taken
+			ex.Taken = 0
+			return negated
+		},
+	},
+	"Temporal": {
+		Name: "DÉJÀ VU STRIKE",
+		Lines: []string{
+			"%s hits %s. Then hits them again, a moment ago!",
+			"%s loops the last three seconds. %s remembers them twice.",
+		},
+		// Later signs of the zodiac are further along the timeline
+		chance: func(u database.Fighter) int { return 10 + 2*zodiacPosition(u.Horoscope) },
+		resolve: func(_, _ database.Fighter, ex *exchange, _ *rand.Rand) int {
+			echo := ex.Dealt
+			ex.Dealt += echo
+			return echo
+		},
+	},
+	"Metaphysical": {
+		Name: "ASTRAL PROJECTION",
+		Lines: []string{
+			"%s leaves their body and walks straight through %s!",
+			"%s's astral form phases into %s's personal plane of existence!",
+		},
+		// Exotic blood makes for an easier exit from the body; density is what passes through
+		chance: func(u database.Fighter) int {
+			if ordinaryBloodType(u.BloodType) {
+				return 15
+			}
+			return 35
+		},
+		resolve: func(u, _ database.Fighter, ex *exchange, rng *rand.Rand) int {
+			bonus := 200 + int(u.MolecularDensity*20) + rng.Intn(500)
+			ex.Dealt += bonus
+			return bonus
+		},
+	},
+}
+
+// signatureFlurry is the move for every class without one of its own: a
+// flurry of hits, one for every ten fingers, set off more often by more toes
+var signatureFlurry = specialMove{
+	Name: "SIGNATURE FLURRY",
+	Lines: []string{
+		"%s unleashes a flurry of fingers upon %s!",
+		"%s counts their digits and applies every one of them to %s!",
+	},
+	chance: func(u database.Fighter) int { return 10 + min(u.Toes, 100)/5 },
+	resolve: func(u, _ database.Fighter, ex *exchange, rng *rand.Rand) int {
+		hits := 1 + min(u.Fingers, 100)/10
+		bonus := 0
+		for i := 0; i < hits; i++ {
+			bonus += 100 + rng.Intn(200)
+		}
+		ex.Dealt += bonus
+		return bonus
+	},
+}
+
+// specialMoveFor returns a fighter class's signature move
+func specialMoveFor(class string) specialMove {
+	if move, ok := specialMoves[class]; ok {
+		return move
+	}
+	return signatureFlurry
+}
+
+// firedSpecial is a special move that went off during a tick
+type firedSpecial struct {
+	Move   specialMove
+	User   database.Fighter
+	Foe    database.Fighter
+	Amount int
+}
+
+// rollSpecial gives user the chance to fire their class move this tick
+func rollSpecial(user, foe database.Fighter, ex *exchange, rng *rand.Rand) *firedSpecial {
+	move := specialMoveFor(user.FighterClass)
+	if rng.Intn(1000) >= move.chance(user) {
+		return nil
+	}
+	return &firedSpecial{Move: move, User: user, Foe: foe, Amount: move.resolve(user, foe, ex, rng)}
+}
+
+var zodiac = []string{"aries", "taurus", "gemini", "cancer", "leo", "virgo", "libra", "scorpio", "sagittarius", "capricorn", "aquarius", "pisces"}
+
+// zodiacPosition is a sign's place in the zodiac, 0-11. Anything that isn't a
+// real sign is considered to be outside of time entirely and scores highest.
+func zodiacPosition(horoscope string) int {
+	h := strings.ToLower(strings.TrimSpace(horoscope))
+	for i, sign := range zodiac {
+		if h == sign {
+			return i
+		}
+	}
+	return 12
+}
+
+// ordinaryBloodType reports whether a blood type is one of the eight human ones
+func ordinaryBloodType(bloodType string) bool {
+	switch strings.ToUpper(strings.TrimSpace(bloodType)) {
+	case "A+", "A-", "B+", "B-", "AB+", "AB-", "O+", "O-":
+		return true
+	}
+	return false
+}
+
+// clampStat keeps a chaos stat in 0-100 so outliers can't run away with a move
+func clampStat(v int) int {
+	return max(0, min(100, v))
+}
@@ -0,0 +1,260 @@
+package fight
+
+import "spoodblort/database"
+
+// TagTeamRulesetName is the ruleset every tag-team fight runs under
+const TagTeamRulesetName = "tag_team"
+
+// TagState tracks who is in the ring for each side of a tag-team fight. Sides
+// are indexed 0 and 1; within a side the captain is 0 and the partner 1. The
+// lane health in FightState always belongs to whoever is active, while the
+// fighters on the apron keep theirs here.
+type TagState struct {
+	Shared   bool         `json:"shared,omitempty"` // each side fights on one pooled health bar
+	Fighters [2][2]int    `json:"fighters"`
+	Names    [2][2]string `json:"names"`
+	Start    [2][2]int    `json:"start"`  // opening health, for splitting a shared pool
+	Health   [2][2]int    `json:"health"` // individual health; the active slot is synced from the lane
+	Out      [2][2]bool   `json:"out"`    // knocked out and unable to tag back in
+	Active   [2]int       `json:"active"`
+	// Who died and who killed them, since WinnerID names the winning side's captain
+	DeadFighterID int `json:"dead_fighter_id,omitempty"`
+	KillerID      int `json:"killer_id,omitempty"`
+}
+
+// TagBar is one fighter's health bar in a tag-team action
+type TagBar struct {
+	FighterID int    `json:"fighter_id"`
+	Name      string `json:"name"`
+	Side      int    `json:"side"` // 1 or 2
+	Health    int    `json:"health"`
+	Active    bool   `json:"active"`
+	Out       bool   `json:"out"`
+}
+
+// Enabled reports whether the state belongs to a tag-team fight
+func (t TagState) Enabled() bool {
+	return t.Fighters[0][1] != 0 && t.Fighters[1][1] != 0
+}
+
+// openingTag puts both captains in the ring with their partners on the apron
+func openingTag(s *fightSetup) TagState {
+	return TagState{
+		Shared:   s.tagShared,
+		Fighters: [2][2]int{{s.fighter1.ID, s.partner1.ID}, {s.fighter2.ID, s.partner2.ID}},
+		Names:    [2][2]string{{s.fighter1.Name, s.partner1.Name}, {s.fighter2.Name, s.partner2.Name}},
+		Start:    [2][2]int{{s.health1, s.partnerHealth1}, {s.health2, s.partnerHealth2}},
+		Health:   [2][2]int{{s.health1, s.partnerHealth1}, {s.health2, s.partnerHealth2}},
+	}
+}
+
+// laneHealth points at the FightState health of a side's active fighter
+func laneHealth(state *FightState, side int) *int {
+	if side == 0 {
+		return &state.Fighter1Health
+	}
+	return &state.Fighter2Health
+}
+
+// healthOf returns the health of one fighter. A shared pool is split between
+// the pair in proportion to the health each brought to it.
+func (t TagState) healthOf(state *FightState, side, slot int) int {
+	if t.Shared {
+		pool := t.Start[side][0] + t.Start[side][1]
+		if pool <= 0 {
+			return 0
+		}
+		return *laneHealth(state, side) * t.Start[side][slot] / pool
+	}
+	if slot == t.Active[side] {
+		return *laneHealth(state, side)
+	}
+	return t.Health[side][slot]
+}
+
+// Bars reports every fighter's health bar, side by side
+func (t TagState) Bars(state *FightState) []TagBar {
+	bars := make([]TagBar, 0, 4)
+	for side := 0; side < 2; side++ {
+		for slot := 0; slot < 2; slot++ {
+			health := *laneHealth(state, side)
+			if !t.Shared {
+				health = t.healthOf(state, side, slot)
+			}
+			bars = append(bars, TagBar{
+				FighterID: t.Fighters[side][slot],
+				Name:      t.Names[side][slot],
+				Side:      side + 1,
+				Health:    health,
+				Active:    t.Active[side] == slot,
+				Out:       t.Out[side][slot],
+			})
+		}
+	}
+	return bars
+}
+
+// sideTotal is the health a side has left across both fighters
+func (t TagState) sideTotal(state *FightState, side int) int {
+	if t.Shared {
+		return *laneHealth(state, side)
+	}
+	total := 0
+	for slot := 0; slot < 2; slot++ {
+		if !t.Out[side][slot] {
+			total += max(t.healthOf(state, side, slot), 0)
+		}
+	}
+	return total
+}
+
+// swap sends a side's partner in, parking the outgoing fighter's health on the apron
+func (t *TagState) swap(state *FightState, side int) {
+	lane := laneHealth(state, side)
+	if !t.Shared {
+		t.Health[side][t.Active[side]] = *lane
+	}
+	t.Active[side] = 1 - t.Active[side]
+	if !t.Shared {
+		*lane = t.Health[side][t.Active[side]]
+	}
+}
+
+// canTag reports whether a side has a partner able to come in
+func (t TagState) canTag(side int) bool {
+	return !t.Out[side][1-t.Active[side]]
+}
+
+// TagTeamRuleset runs two-a-side fights on top of another ruleset. Only one
+// fighter per side is in the ring at a time and the wrapped ruleset resolves
+// their exchanges. Partners tag in between rounds, and in individual health
+// mode a knocked out fighter is replaced rather than losing the fight. Death
+// ends the fight for the whole side.
+type TagTeamRuleset struct {
+	version string
+	inner   Ruleset
+}
+
+// NewTagTeamRuleset builds a version of the tag-team ruleset over inner's combat
+func NewTagTeamRuleset(version string, inner Ruleset) TagTeamRuleset {
+	return TagTeamRuleset{version: version, inner: inner}
+}
+
+func (TagTeamRuleset) Name() string { return TagTeamRulesetName }
+
+func (r TagTeamRuleset) Version() string { return r.version }
+
+// ResolveTick runs one exchange between the active fighters
+func (r TagTeamRuleset) ResolveTick(in TickInput, state *FightState, emit func(LiveAction)) {
+	tag := &state.Tag
+	if !tag.Enabled() {
+		r.inner.ResolveTick(in, state, emit)
+		return
+	}
+
+	corners := [2][2]database.Fighter{{in.Fighter1, in.Partner1}, {in.Fighter2, in.Partner2}}
+	active := in
+	active.Fighter1 = corners[0][tag.Active[0]]
+	active.Fighter2 = corners[1][tag.Active[1]]
+
+	// Hold the tick's actions back so a knockout can be turned into a tag
+	var actions []LiveAction
+	var sink func(LiveAction)
+	if emit != nil {
+		sink = func(action LiveAction) { actions = append(actions, action) }
+	}
+	r.inner.ResolveTick(active, state, sink)
+
+	if state.IsComplete {
+		loser := 0
+		if state.WinnerID == active.Fighter1.ID {
+			loser = 1
+		}
+		downed := corners[loser][tag.Active[loser]]
+
+		if state.DeathOccurred {
+			tag.DeadFighterID, tag.KillerID = downed.ID, state.WinnerID
+		} else if !tag.Shared {
+			tag.Out[loser][tag.Active[loser]] = true
+			if tag.canTag(loser) {
+				tag.swap(state, loser)
+				state.IsComplete, state.WinnerID = false, 0
+				if emit != nil {
+					actions = dropActions(actions, "death")
+					fresh := corners[loser][tag.Active[loser]]
+					actions = append(actions, GenerateTagAction(in.Tick, downed.Name, fresh.Name, true,
+						state.Fighter1Health, state.Fighter2Health, state.CurrentRound))
+				}
+			}
+		}
+
+		if state.IsComplete {
+			state.WinnerID = tag.Fighters[1-loser][0]
+		}
+	}
+
+	for _, action := range actions {
+		emit(action)
+	}
+}
+
+// AdvanceRound moves to the next round and lets each side tag between rounds.
+// With individual health a side tags when the partner is fresher than whoever
+// is in the ring; with shared health a side tags after losing the last exchange.
+func (r TagTeamRuleset) AdvanceRound(state *FightState, emit func(LiveAction)) bool {
+	newRound := r.inner.AdvanceRound(state, emit)
+	tag := &state.Tag
+	if !newRound || !tag.Enabled() || state.IsComplete {
+		return newRound
+	}
+
+	taken := [2]int{state.LastDamage1, state.LastDamage2}
+	for side := 0; side < 2; side++ {
+		if !tag.canTag(side) {
+			continue
+		}
+		partner := 1 - tag.Active[side]
+		if tag.Shared {
+			if taken[side] <= taken[1-side] {
+				continue
+			}
+		} else if tag.Health[side][partner] <= *laneHealth(state, side) {
+			continue
+		}
+
+		outgoing := tag.Names[side][tag.Active[side]]
+		tag.swap(state, side)
+		if emit != nil {
+			emit(GenerateTagAction(state.TickNumber, outgoing, tag.Names[side][partner], false,
+				state.Fighter1Health, state.Fighter2Health, state.CurrentRound))
+		}
+	}
+	return true
+}
+
+// Decide compares what each side has left across both fighters
+func (r TagTeamRuleset) Decide(state *FightState) {
+	tag := &state.Tag
+	if !tag.Enabled() {
+		r.inner.Decide(state)
+		return
+	}
+	total1, total2 := tag.sideTotal(state, 0), tag.sideTotal(state, 1)
+	if total1 > total2 {
+		state.WinnerID = state.SimFighter1ID
+	} else if total2 > total1 {
+		state.WinnerID = state.SimFighter2ID
+	}
+	state.IsComplete = true
+}
+
+// dropActions removes every action of the given type
+func dropActions(actions []LiveAction, actionType string) []LiveAction {
+	kept := actions[:0]
+	for _, action := range actions {
+		if action.Type != actionType {
+			kept = append(kept, action)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,128 @@
+package fight
+
+import (
+	"fmt"
+
+	"spoodblort/database"
+)
+
+// FightVerification compares a fresh replay of a completed fight against the
+// result stored when it originally ran
+type FightVerification struct {
+	FightID        int      `json:"fight_id"`
+	Ruleset        string   `json:"ruleset"`
+	AlgoVersion    string   `json:"algo_version"`
+	RecordedInputs bool     `json:"recorded_inputs"`          // false for fights that ran before inputs were recorded
+	CatchUpTicks   int      `json:"catch_up_ticks,omitempty"` // legacy fights: opening ticks that ran through the old catch-up path
+	TickSalt       int64    `json:"tick_salt"`                // secret tick seed salt, published once the fight is over
+	StoredWinnerID int      `json:"stored_winner_id"`
+	StoredScore1   int      `json:"stored_score1"`
+	StoredScore2   int      `json:"stored_score2"`
+	ReplayWinnerID int      `json:"replay_winner_id"`
+	ReplayScore1   int      `json:"replay_score1"`
+	ReplayScore2   int      `json:"replay_score2"`
+	ReplayTicks    int      `json:"replay_ticks"`
+	ReplayDeath    bool     `json:"replay_death"`
+	Match          bool     `json:"match"`
+	Divergences    []string `json:"divergences,omitempty"`
+}
+
+// VerifyFight re-simulates a completed fight from its seed and recorded inputs
+// under the algorithm version it ran with, and reports any divergence from the
+// stored winner and FinalScore1/2
+func (e *Engine) VerifyFight(fightID int) (*FightVerification, error) {
+	fight, err := e.repo.GetFight(fightID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fight %d: %w", fightID, err)
+	}
+	if fight.Status != "completed" {
+		return nil, fmt.Errorf("fight %d is %s, only completed fights can be verified", fightID, fight.Status)
+	}
+
+	inputs, err := e.repo.GetFightInputs(fightID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load inputs for fight %d: %w", fightID, err)
+	}
+
+	setup, err := e.replaySetup(*fight)
+	if err != nil {
+		return nil, err
+	}
+	rules := e.RulesetForFight(*fight)
+	var state *FightState
+	if legacy, ok := rules.(LegacyRuleset); ok {
+		rules, state = e.replayLegacy(*fight, legacy, setup)
+	} else {
+		state = e.runFight(fight.ID, rules, setup, nil)
+	}
+
+	v := &FightVerification{
+		FightID:        fight.ID,
+		Ruleset:        rules.Name(),
+		AlgoVersion:    rules.Version(),
+		RecordedInputs: hasSnapshot(inputs),
+		StoredWinnerID: int(fight.WinnerID.Int64),
+		StoredScore1:   int(fight.FinalScore1.Int64),
+		StoredScore2:   int(fight.FinalScore2.Int64),
+		ReplayWinnerID: state.WinnerID,
+		ReplayScore1:   state.Fighter1Health,
+		ReplayScore2:   state.Fighter2Health,
+		ReplayTicks:    state.TickNumber,
+		ReplayDeath:    state.DeathOccurred,
+		TickSalt:       setup.tickSalt,
+	}
+	if legacy, ok := rules.(LegacyRuleset); ok {
+		v.CatchUpTicks = legacy.CatchUpThrough()
+	}
+
+	if fight.AlgoVersion != "" && fight.AlgoVersion != rules.Version() {
+		v.Divergences = append(v.Divergences, fmt.Sprintf("algorithm version %s is not registered, replayed under %s", fight.AlgoVersion, rules.Version()))
+	}
+	if v.StoredWinnerID != v.ReplayWinnerID {
+		v.Divergences = append(v.Divergences, fmt.Sprintf("winner: stored %d, replay %d", v.StoredWinnerID, v.ReplayWinnerID))
+	}
+	if !fight.FinalScore1.Valid || v.StoredScore1 != v.ReplayScore1 {
+		v.Divergences = append(v.Divergences, fmt.Sprintf("final_score1: stored %d, replay %d", v.StoredScore1, v.ReplayScore1))
+	}
+	if !fight.FinalScore2.Valid || v.StoredScore2 != v.ReplayScore2 {
+		v.Divergences = append(v.Divergences, fmt.Sprintf("final_score2: stored %d, replay %d", v.StoredScore2, v.ReplayScore2))
+	}
+	if len(v.Divergences) > 0 && rules.Version() == LegacyVersion && !v.RecordedInputs {
+		v.Divergences = append(v.Divergences, "fight predates recorded inputs; fighter stats may have changed since it ran")
+	}
+	v.Match = len(v.Divergences) == 0
+	return v, nil
+}
+
+// replayLegacy replays a pre-versioning fight. The old engine simulated the
+// opening ticks through its catch-up path whenever it (re)started mid-fight and
+// never recorded how far that went, so every boundary is tried until one
+// reproduces the stored result. Boundaries past the tick the fight ended on all
+// play out the same, so the search stops there.
+func (e *Engine) replayLegacy(fight database.Fight, rules LegacyRuleset, setup *fightSetup) (LegacyRuleset, *FightState) {
+	var state *FightState
+	for ticks := 0; ticks <= MAX_FIGHT_TICKS; ticks++ {
+		rules = rules.WithCatchUp(ticks)
+		state = e.runFight(fight.ID, rules, setup, nil)
+		if matchesStored(fight, state) || state.TickNumber <= ticks {
+			break
+		}
+	}
+	return rules, state
+}
+
+// matchesStored reports whether a replay reproduced the stored winner and scores
+func matchesStored(fight database.Fight, state *FightState) bool {
+	return int(fight.WinnerID.Int64) == state.WinnerID &&
+		fight.FinalScore1.Valid && int(fight.FinalScore1.Int64) == state.Fighter1Health &&
+		fight.FinalScore2.Valid && int(fight.FinalScore2.Int64) == state.Fighter2Health
+}
+
+func hasSnapshot(inputs []database.FightInput) bool {
+	for _, in := range inputs {
+		if in.InputType == database.FightInputFighterSnapshot {
+			return true
+		}
+	}
+	return false
+}
@@ -13,6 +13,7 @@ import (
 
 	"spoodblort/database"
 	"spoodblort/scheduler"
+	"spoodblort/utils"
 	"spoodblort/web"
 	"spoodblort/wiki"
 )
@@ -42,6 +43,29 @@ func (w *IPMaskingWriter) Write(p []byte) (n int, err error) {
 	return w.writer.Write(masked)
 }
 
+// connectDatabase opens the league database named by DATABASE_URL
+func connectDatabase() *sqlx.DB {
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		dbURL = "./spoodblort.db"
+	}
+
+	db, err := sqlx.Connect("sqlite3", dbURL)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	return db
+}
+
+// leagueClock builds the league clock from LEAGUE_TIMEZONE and the TIME_WARP settings
+func leagueClock() utils.Clock {
+	clock, err := utils.ClockFromEnv()
+	if err != nil {
+		log.Fatal("Failed to set up league clock:", err)
+	}
+	return clock
+}
+
 func main() {
 	// Set up IP masking for log output
 	maskedWriter := NewIPMaskingWriter(os.Stdout)
@@ -53,29 +77,26 @@ func main() {
 		log.Printf("Warning: Could not load .env file: %v", err)
 	}
 
-	// Set up timezone
-	centralTime, err := time.LoadLocation("America/Chicago")
-	if err != nil {
-		log.Fatal("Failed to load Central Time zone:", err)
+	// One-off maintenance commands (spoodblort <command> ...) run instead of the server
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
 	}
 
-	now := time.Now().In(centralTime)
+	// Set up the league clock and timezone
+	clock := leagueClock()
+	now := clock.Now()
 	log.Printf("🕒 Starting Spoodblort at: %s", now.Format("Monday, January 2, 2006 at 3:04:05 PM MST"))
-
-	// Connect to database
-	dbURL := os.Getenv("DATABASE_URL")
-	if dbURL == "" {
-		dbURL = "./spoodblort.db"
+	if clock.Warp() != 1 {
+		log.Printf("⏩ Time warp active: %gx real time", clock.Warp())
 	}
 
-	db, err := sqlx.Connect("sqlite3", dbURL)
-	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
-	}
+	// Connect to database
+	db := connectDatabase()
 	defer db.Close()
 
 	// Initialize components
 	repo := database.NewRepository(db)
+	repo.SetClock(clock)
 	sched := scheduler.NewScheduler(repo)
 
 	if err := repo.BackfillChampionLegacyStats(); err != nil {
@@ -108,12 +129,11 @@ func main() {
 
 	// Start background scheduler to handle fight activation
 	go func() {
-		ticker := time.NewTicker(30 * time.Second) // Check every 30 seconds
+		ticker := clock.NewTicker(30 * time.Second) // Check every 30 seconds of league time
 		defer ticker.Stop()
 
 		for range ticker.C {
-			centralTime, _ := time.LoadLocation("America/Chicago")
-			now := time.Now().In(centralTime)
+			now := clock.Now()
 
 			// Skip all processing on Sundays - Department is closed
 			if now.Weekday() == time.Sunday {
@@ -122,6 +142,12 @@ func main() {
 
 			// Weekly high-roller tithe on Mondays (idempotent)
 			_ = repo.TaxHighRollersIfNeeded(now)
+			// Weekly loan interest and installments on Mondays (idempotent)
+			if changed, err := repo.ProcessLoansIfNeeded(now); err != nil {
+				log.Printf("Background scheduler: Error processing loans: %v", err)
+			} else if len(changed) > 0 {
+				go sched.GetEngine().UpdateUserRolesAfterCreditsChange(changed)
+			}
 			// Weekly sacrifice decay (idempotent)
 			_ = repo.DecaySacrificesIfNeeded(now)
 
@@ -153,6 +179,13 @@ func main() {
 				log.Printf("Background scheduler: Error processing active fights: %v", err)
 			}
 
+			if err := engine.ProcessBattleRoyales(now); err != nil {
+				log.Printf("Background scheduler: Error processing battle royales: %v", err)
+			}
+
+			// Price today's upcoming fights that have no odds yet
+			go engine.PriceUpcomingFights(now)
+
 			// Saturday playoff creation (idempotent)
 			_ = sched.MaybeCreateSaturdayPlayoffs(now)
 		}
@@ -163,21 +196,20 @@ func main() {
 	// Safe to leave empty. Progress will be logged for the debugger.
 	wiki.NewBackfillWorker(repo, "", "").Start()
 
-	// Daily Discord event sync at 4:00 AM Central
+	// Daily Discord event sync at 4:00 AM league time
 	go func() {
 		for {
-			centralTime, _ := time.LoadLocation("America/Chicago")
-			now := time.Now().In(centralTime)
+			now := clock.Now()
 			// compute next 4:00 AM
-			next := time.Date(now.Year(), now.Month(), now.Day(), 4, 0, 0, 0, centralTime)
+			next := time.Date(now.Year(), now.Month(), now.Day(), 4, 0, 0, 0, clock.Location())
 			if !now.Before(next) {
 				next = next.Add(24 * time.Hour)
 			}
-			dur := time.Until(next)
-			timer := time.NewTimer(dur)
+			dur := clock.Until(next)
+			timer := clock.NewTimer(dur)
 			<-timer.C
 			// Skip Sundays
-			now = time.Now().In(centralTime)
+			now = clock.Now()
 			if now.Weekday() == time.Sunday {
 				continue
 			}
@@ -4,16 +4,18 @@ import (
 	"fmt"
 	"log"
 	"spoodblort/database"
+	"spoodblort/discord"
 	"spoodblort/utils"
 	"time"
 )
 
 type Recovery struct {
-	repo *database.Repository
+	repo     *database.Repository
+	notifier *discord.Notifier
 }
 
 func NewRecovery(repo *database.Repository) *Recovery {
-	return &Recovery{repo: repo}
+	return &Recovery{repo: repo, notifier: discord.NewNotifier(repo)}
 }
 
 func (r *Recovery) VoidPastFights(tournamentID int, now time.Time) error {
@@ -50,11 +52,44 @@ func (r *Recovery) VoidPastFights(tournamentID int, now time.Time) error {
 		if err != nil {
 			return fmt.Errorf("failed to update fighter records for voided fight %d: %w", fight.ID, err)
 		}
+
+		fight.VoidedReason.String, fight.VoidedReason.Valid = reason, true
+		if r.SettleVoidedFight(fight, database.VoidBetPolicy()) == nil {
+			// Parlay legs still drop out so the rest of their slips can settle
+			// while the fight's own bets wait for settle-voids
+			if err := r.repo.ProcessSlipLegsForFight(fight.ID, nil); err != nil {
+				log.Printf("Failed to drop parlay legs on voided fight %d: %v", fight.ID, err)
+			}
+		}
 	}
 
 	return nil
 }
 
+// SettleVoidedFight closes out the stakes on a voided fight under policy and
+// lets the affected users know. Failures are logged rather than returned so
+// one stuck fight doesn't hold up the rest; the settle-voids command can
+// retry it later.
+func (r *Recovery) SettleVoidedFight(fight database.Fight, policy string) *database.VoidSettlement {
+	settlement, err := r.repo.SettleVoidedFight(fight.ID, policy)
+	if err != nil {
+		log.Printf("Failed to settle bets on voided fight %d: %v", fight.ID, err)
+		return nil
+	}
+	if len(settlement.Stakes) == 0 && settlement.SlipLegs == 0 {
+		return settlement
+	}
+
+	log.Printf("Settled voided fight %d under %s policy: %d stakes, %d parlay legs",
+		fight.ID, policy, len(settlement.Stakes), settlement.SlipLegs)
+	if r.notifier != nil {
+		if err := r.notifier.NotifyVoidSettlement(fight, settlement); err != nil {
+			log.Printf("Failed to announce void settlement for fight %d: %v", fight.ID, err)
+		}
+	}
+	return settlement
+}
+
 func (r *Recovery) ActivateCurrentFights(tournamentID int, now time.Time) error {
 	return r.repo.ActivateCurrentFights(tournamentID, now)
 }
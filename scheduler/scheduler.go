@@ -22,6 +22,22 @@ type Scheduler struct {
 // Saturday feature flag and timing (code-level kill switch)
 var SaturdayRoundRobinEnabled = true
 
+// Tag-team day: that weekday's card is fought two-a-side
+var TagTeamEnabled = true
+var TagTeamDay = time.Wednesday
+
+// TagTeamHealth is whether tag-team partners share a health pool or each have their own
+var TagTeamHealth = database.TagHealthIndividual
+
+// Grudge matches: one weekday singles card a day is rebooked so the hottest
+// rivalry among the day's fighters meets, if it's hot enough and still fresh
+var GrudgeMatchesEnabled = true
+var GrudgeMatchMinScore = 30
+var GrudgeMatchWindow = 21 * 24 * time.Hour
+
+// SaturdayFinalSettlement is how bets on the Saturday final settle
+var SaturdayFinalSettlement = database.SettlementParimutuel
+
 const SaturdayStartHour = 10
 const SaturdayStartMinute = 30
 
@@ -37,13 +53,6 @@ func NewScheduler(repo *database.Repository) *Scheduler {
 // SetBroadcaster allows setting a live broadcaster for the fight engine
 func (s *Scheduler) SetBroadcaster(broadcaster fight.Broadcaster) {
 	s.engine.SetBroadcaster(broadcaster)
-
-	// If the broadcaster has a SetEngine method, connect the engine for logging
-	if engineSetter, ok := broadcaster.(interface {
-		SetEngine(interface{ LogAction(int, string) })
-	}); ok {
-		engineSetter.SetEngine(s.engine)
-	}
 }
 
 // GetEngine returns the fight engine for external use
@@ -144,7 +153,15 @@ func (s *Scheduler) EnsureTodaysSchedule(now time.Time) error {
 	todaysFighters := s.generator.SelectDailyFighters(allFighters, today)
 	log.Printf("Selected %d fighters for today", len(todaysFighters))
 
-	fights, err := s.generator.GenerateFightSchedule(tournament, todaysFighters, today)
+	var fights []database.Fight
+	if now.Weekday() == TagTeamDay && TagTeamEnabled && len(todaysFighters) >= 4 {
+		fights, err = s.generator.GenerateTagTeamSchedule(tournament, todaysFighters, today, TagTeamHealth)
+	} else {
+		fights, err = s.generator.GenerateFightSchedule(tournament, todaysFighters, today)
+		if err == nil && GrudgeMatchesEnabled {
+			fights = s.generator.ArrangeGrudgeMatch(fights, GrudgeMatchMinScore, today.Add(-GrudgeMatchWindow))
+		}
+	}
 	if err != nil {
 		return fmt.Errorf("failed to generate fight schedule: %w", err)
 	}
@@ -180,8 +197,7 @@ func (s *Scheduler) EnsureTodaysSchedule(now time.Time) error {
 // ensureSaturdayRoundRobin creates the 24 group fights starting at 10:30 (no playoffs here)
 func (s *Scheduler) ensureSaturdayRoundRobin(t *database.Tournament, now time.Time) error {
 	// Determine Mon–Fri winners
-	centralTime, _ := time.LoadLocation("America/Chicago")
-	nowC := now.In(centralTime)
+	nowC := now.In(s.repo.Clock().Location())
 	mon, sat := utils.GetMonToFriBounds(nowC)
 
 	wins, err := s.repo.GetCompletedFightsInRange(t.ID, mon, sat)
@@ -388,19 +404,55 @@ func (s *Scheduler) MaybeCreateSaturdayPlayoffs(now time.Time) error {
 			f1, _ := s.repo.GetFighter(w1)
 			f2, _ := s.repo.GetFighter(w2)
 			_ = s.generator.CreateFights([]database.Fight{{
-				TournamentID:  t.ID,
-				Fighter1ID:    f1.ID,
-				Fighter2ID:    f2.ID,
-				Fighter1Name:  f1.Name,
-				Fighter2Name:  f2.Name,
-				ScheduledTime: fTime,
-				Status:        "scheduled",
+				TournamentID:   t.ID,
+				Fighter1ID:     f1.ID,
+				Fighter2ID:     f2.ID,
+				Fighter1Name:   f1.Name,
+				Fighter2Name:   f2.Name,
+				ScheduledTime:  fTime,
+				Status:         "scheduled",
+				SettlementMode: SaturdayFinalSettlement,
 			}})
 		}
 	}
 	return nil
 }
 
+// ScheduleBattleRoyale books a battle royale at the given time. With no
+// fighter IDs it fills the field from the day's usual draw of eligible fighters.
+// The royale is pinned to the current standard ruleset so later engine
+// changes can't alter how it plays.
+func (s *Scheduler) ScheduleBattleRoyale(name string, at time.Time, fighterIDs []int) (int, error) {
+	var fighters []database.Fighter
+	if len(fighterIDs) == 0 {
+		eligible, err := s.repo.GetEligibleFighters()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get eligible fighters: %w", err)
+		}
+		fighters = s.generator.SelectDailyFighters(eligible, at)
+		if len(fighters) > database.RoyaleMaxEntrants {
+			fighters = fighters[:database.RoyaleMaxEntrants]
+		}
+	} else {
+		for _, id := range fighterIDs {
+			fighter, err := s.repo.GetFighter(id)
+			if err != nil {
+				return 0, fmt.Errorf("failed to get fighter %d: %w", id, err)
+			}
+			if fighter.IsDead && !fighter.IsUndead {
+				return 0, fmt.Errorf("%s is dead", fighter.Name)
+			}
+			fighters = append(fighters, *fighter)
+		}
+	}
+
+	rules, err := fight.LookupRuleset(fight.DefaultRulesetName, "")
+	if err != nil {
+		return 0, err
+	}
+	return s.repo.CreateBattleRoyale(name, at, rules.Name(), rules.Version(), fighters)
+}
+
 // Discord events removed
 
 func (s *Scheduler) GetTodaysSchedule(now time.Time) ([]database.Fight, error) {
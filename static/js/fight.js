@@ -15,7 +15,59 @@ document.addEventListener('DOMContentLoaded', function() {
         const amount = el.getAttribute('data-amount');
         el.textContent = formatShort(amount);
     });
+
+    const inplay = document.getElementById('inplay-section');
+    if (inplay) {
+        refreshInPlayQuote(inplay.getAttribute('data-fight-id'));
+    }
+
+    const cashout = document.getElementById('cashout-section');
+    if (cashout) {
+        refreshCashOutOffer(cashout.getAttribute('data-bet-id'));
+    }
 });
+
+// Poll the cash-out offer on the user's bet while the fight is live
+function refreshCashOutOffer(betId) {
+    fetch(`/user/bets/${betId}/cashout`)
+        .then(res => res.json())
+        .then(data => {
+            const status = document.getElementById('cashout-status');
+            const form = document.getElementById('cashout-form');
+            if (data.error) {
+                status.textContent = 'Cash-out is closed.';
+                form.querySelector('button').style.display = 'none';
+                return;
+            }
+            status.textContent = data.amount > 0
+                ? `Cash out now for ${formatShort(data.amount)} (pays ${formatShort(data.return)} if it lands)`
+                : 'No cash-out offer right now.';
+            form.querySelector('button').style.display = data.amount > 0 ? '' : 'none';
+            setTimeout(() => refreshCashOutOffer(betId), 3000);
+        })
+        .catch(() => setTimeout(() => refreshCashOutOffer(betId), 6000));
+}
+
+// Poll the live price once per tick while in-play betting is open
+function refreshInPlayQuote(fightId) {
+    fetch(`/api/fights/${fightId}/inplay`)
+        .then(res => res.json())
+        .then(data => {
+            const status = document.getElementById('inplay-status');
+            if (!data.open) {
+                status.textContent = 'In-play betting is closed.';
+                document.querySelectorAll('.inplay-form').forEach(f => f.style.display = 'none');
+                return;
+            }
+            const q = data.quote;
+            const fmt = o => o > 0 ? o.toFixed(2) + 'x' : 'OFF';
+            document.getElementById('inplay-odds-1').textContent = fmt(q.fighter1_odds);
+            document.getElementById('inplay-odds-2').textContent = fmt(q.fighter2_odds);
+            status.textContent = `Round ${q.round}, tick ${q.tick} · closes at tick ${q.closes_at_tick}`;
+            setTimeout(() => refreshInPlayQuote(fightId), 3000);
+        })
+        .catch(() => setTimeout(() => refreshInPlayQuote(fightId), 6000));
+}
 // Apply effect (bless/curse) to a fighter
 function applyEffect(itemId, fightId, fighterId, fighterName, effectName) {
     // Send effect application request directly without confirmation
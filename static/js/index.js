@@ -60,15 +60,15 @@ function initCountdown() {
     setInterval(updateCountdown, 1000);
 }
 
-// Check for Sunday closure and redirect at midnight CST6CDT
+// Check for Sunday closure and redirect at midnight league time
 function checkSundayClosure() {
     const now = new Date();
     
-    // Convert to CST/CDT - simpler approach
-    // Create a date in Central Time
-    const centralTime = new Date(now.toLocaleString("en-US", {timeZone: "America/Chicago"}));
+    // Create a date in the league timezone (America/Chicago unless the server says otherwise)
+    const leagueTimezone = window.LEAGUE_TIMEZONE || "America/Chicago";
+    const centralTime = new Date(now.toLocaleString("en-US", {timeZone: leagueTimezone}));
     
-    console.log('Current Central Time:', centralTime.toString());
+    console.log('Current league time:', centralTime.toString());
     console.log('Day of week (0=Sunday):', centralTime.getDay());
     console.log('Hours:', centralTime.getHours(), 'Minutes:', centralTime.getMinutes());
     
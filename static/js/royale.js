@@ -0,0 +1,148 @@
+// BATTLE ROYALE - live standings for every fighter in the pile
+let royaleSocket;
+let royaleID;
+let royaleAttempts = 0;
+const royaleMaxAttempts = 3;
+const royaleMaxHealth = 100000;
+
+function initializeRoyalePage(id, status) {
+    royaleID = id;
+    if (status === 'active' || status === 'scheduled') {
+        connectRoyaleSocket();
+    }
+}
+
+function connectRoyaleSocket() {
+    royaleAttempts++;
+    const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
+    royaleSocket = new WebSocket(`${protocol}//${window.location.host}/ws/royale/${royaleID}`);
+
+    royaleSocket.onopen = function() {
+        setRoyaleStatus('Connected to violence feed');
+        royaleAttempts = 0;
+    };
+
+    royaleSocket.onmessage = function(event) {
+        const data = JSON.parse(event.data);
+        if (data.type === 'initial') {
+            handleRoyaleInitial(data);
+        } else if (data.type === 'action') {
+            handleRoyaleAction(data.action);
+        }
+    };
+
+    royaleSocket.onclose = function() {
+        if (royaleAttempts < royaleMaxAttempts) {
+            setRoyaleStatus(`Reconnecting... (${royaleAttempts}/${royaleMaxAttempts})`);
+            setTimeout(connectRoyaleSocket, 3000);
+        } else {
+            setRoyaleStatus('⚠️ Connection failed - refresh for results');
+        }
+    };
+}
+
+function setRoyaleStatus(text) {
+    const el = document.getElementById('commentary-status');
+    if (el) {
+        el.textContent = text;
+    }
+}
+
+function handleRoyaleInitial(data) {
+    if (data.royale) {
+        document.getElementById('royale-status').textContent = data.royale.status;
+    }
+    (data.entrants || []).forEach(entrant => {
+        if (entrant.placement > 0) {
+            markPlacement(entrant.fighter_id, entrant.placement);
+        }
+    });
+}
+
+function handleRoyaleAction(action) {
+    if (action.standings) {
+        let remaining = 0;
+        action.standings.forEach(lane => {
+            updateRoyaleBar(lane.fighter_id, lane.health);
+            if (lane.placement > 0) {
+                markPlacement(lane.fighter_id, lane.placement);
+            } else {
+                remaining++;
+            }
+        });
+        document.getElementById('royale-remaining').textContent = remaining;
+    }
+    addRoyaleMessage(action);
+
+    if (action.type === 'royale_winner') {
+        document.getElementById('royale-status').textContent = 'completed';
+        setRoyaleStatus('👑 It is over.');
+    }
+}
+
+function updateRoyaleBar(fighterID, health) {
+    const fill = document.getElementById('royale-health-' + fighterID);
+    const text = document.getElementById('royale-health-' + fighterID + '-text');
+    if (!fill || !text) {
+        return;
+    }
+    const percentage = Math.max(0, Math.min(100, (health / royaleMaxHealth) * 100));
+    fill.style.width = percentage + '%';
+    text.textContent = Math.max(0, health).toLocaleString();
+    if (percentage > 60) {
+        fill.style.backgroundColor = '#00ff00';
+    } else if (percentage > 30) {
+        fill.style.backgroundColor = '#ffaa00';
+    } else {
+        fill.style.backgroundColor = '#ff4444';
+    }
+}
+
+function markPlacement(fighterID, placement) {
+    const card = document.getElementById('royale-entrant-' + fighterID);
+    const badge = document.getElementById('royale-placement-' + fighterID);
+    if (badge) {
+        badge.textContent = '#' + placement;
+    }
+    if (card) {
+        card.classList.add('royale-out');
+        card.classList.toggle('royale-winner', placement === 1);
+    }
+}
+
+function addRoyaleMessage(action) {
+    const feed = document.getElementById('commentary-feed');
+    if (!feed) {
+        return;
+    }
+
+    const messageDiv = document.createElement('div');
+    messageDiv.className = 'commentary-message';
+    if (action.type === 'elimination') {
+        messageDiv.classList.add('royale-elimination-message');
+    } else if (action.type === 'royale_winner') {
+        messageDiv.classList.add('royale-winner-message');
+    }
+
+    const actionDiv = document.createElement('div');
+    actionDiv.className = 'action-text';
+    actionDiv.textContent = action.action;
+    messageDiv.appendChild(actionDiv);
+
+    if (action.commentary && action.commentary.trim() !== '') {
+        const commentDiv = document.createElement('div');
+        commentDiv.className = 'announcer-comment';
+        const name = document.createElement('span');
+        name.className = 'announcer-name';
+        name.textContent = action.announcer + ':';
+        commentDiv.appendChild(name);
+        commentDiv.appendChild(document.createTextNode(' "' + action.commentary + '"'));
+        messageDiv.appendChild(commentDiv);
+    }
+
+    feed.appendChild(messageDiv);
+    feed.scrollTop = feed.scrollHeight;
+    while (feed.children.length > 80) {
+        feed.removeChild(feed.firstChild);
+    }
+}
@@ -362,6 +362,23 @@ function showCompletedFightResults(fightData) {
     // Replace the initial message
     feed.innerHTML = '';
     feed.appendChild(messageDiv);
+
+    loadFightHighlights();
+}
+
+// Replay the big moments of a finished fight from its recorded event history
+function loadFightHighlights() {
+    fetch(`/api/fights/${fightID}/events?type=critical,special,clap_summary,death&limit=40`)
+        .then(res => res.ok ? res.json() : null)
+        .then(data => {
+            if (!data || !data.events) return;
+            data.events.forEach(ev => {
+                if (ev.payload && ev.payload.action) {
+                    addCommentaryMessage(ev.payload);
+                }
+            });
+        })
+        .catch(err => console.log('No fight history available:', err));
 }
 
 function showVoidedFightResults(fightData) {
@@ -453,10 +470,30 @@ function handleLiveUpdate(data) {
         case 'clap':
             updateClappingState(data.round);
             break;
+        case 'pool':
+            updatePoolSummary(data.pool);
+            break;
     }
 }
 
+// Show live parimutuel pool totals and what a credit on each side pays right now
+function updatePoolSummary(pool) {
+    const container = document.getElementById('pool-summary');
+    if (!container || !pool) return;
+    container.style.display = '';
+    const payout = p => p > 0 ? p.toFixed(2) + 'x' : '—';
+    document.getElementById('pool-total').textContent = pool.total.toLocaleString();
+    document.getElementById('pool-rake').textContent = pool.rake_percent;
+    document.getElementById('pool-stake-1').textContent = pool.fighter1_stake.toLocaleString();
+    document.getElementById('pool-stake-2').textContent = pool.fighter2_stake.toLocaleString();
+    document.getElementById('pool-payout-1').textContent = payout(pool.fighter1_payout);
+    document.getElementById('pool-payout-2').textContent = payout(pool.fighter2_payout);
+}
+
 function handleInitialState(data) {
+    if (data.tag_health) {
+        updateTagBars(data.tag_health);
+    }
     if (data.status === 'scheduled') {
         document.getElementById('commentary-status').textContent = 'Violence begins soon!';
     } else if (data.status === 'active') {
@@ -551,6 +588,9 @@ function handleLiveAction(action) {
     // Update health bars
     updateHealthBar('health1', action.health1);
     updateHealthBar('health2', action.health2);
+    if (action.tag_health) {
+        updateTagBars(action.tag_health);
+    }
     
     // Update round number
     document.getElementById('round-number').textContent = action.round;
@@ -564,6 +604,10 @@ function handleLiveAction(action) {
     // Special effects for different action types
     if (action.type === 'critical') {
         flashScreen('#ff4444');
+    } else if (action.type === 'special') {
+        flashScreen('#aa44ff');
+    } else if (action.type === 'tag') {
+        flashScreen('#44aaff');
     } else if (action.type === 'death') {
         flashScreen('#000000');
         document.getElementById('fight-status').textContent = '💀 FATALITY';
@@ -595,6 +639,18 @@ function updateHealthBar(healthId, health) {
     }
 }
 
+// Tag-team fights carry a bar for every fighter, marking who is in the ring
+function updateTagBars(bars) {
+    bars.forEach(bar => {
+        updateHealthBar('tag-health-' + bar.fighter_id, bar.health);
+        const row = document.getElementById('tag-row-' + bar.fighter_id);
+        if (row) {
+            row.classList.toggle('tag-active', !!bar.active);
+            row.classList.toggle('tag-out', !!bar.out);
+        }
+    });
+}
+
 function addCommentaryMessage(action) {
     const feed = document.getElementById('commentary-feed');
     
@@ -621,6 +677,12 @@ function addCommentaryMessage(action) {
         messageDiv.appendChild(actionDiv);
     }
     
+    if (action.type === 'special') {
+        messageDiv.classList.add('special-move-message');
+    } else if (action.type === 'tag') {
+        messageDiv.classList.add('tag-message');
+    }
+
     feed.appendChild(messageDiv);
     
     // Scroll to bottom
@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// DefaultLeagueTimezone is where the league has always been run from
+const DefaultLeagueTimezone = "America/Chicago"
+
+// Clock is the league's sense of time. Anything that schedules, paces or dates
+// league activity asks the clock rather than calling time.Now, so a league can
+// be hosted in any timezone and staging can run faster than the wall clock.
+type Clock interface {
+	// Now is the current league time, in the league's location
+	Now() time.Time
+	// Location is the league timezone: days, weeks and fight slots follow it
+	Location() *time.Location
+	// Since and Until measure league time
+	Since(t time.Time) time.Duration
+	Until(t time.Time) time.Duration
+	// NewTicker and NewTimer fire after d of league time has passed
+	NewTicker(d time.Duration) *time.Ticker
+	NewTimer(d time.Duration) *time.Timer
+	// Warp is how many league seconds pass per real second
+	Warp() float64
+}
+
+// leagueClock follows the wall clock, optionally sped up from an anchor point
+type leagueClock struct {
+	loc          *time.Location
+	warp         float64
+	anchorReal   time.Time
+	anchorLeague time.Time
+}
+
+// NewClock returns a clock that follows real time in loc
+func NewClock(loc *time.Location) Clock {
+	return &leagueClock{loc: loc, warp: 1}
+}
+
+// NewWarpClock returns a clock that starts at start and runs warp times faster
+// than real time. A zero start begins the warp from the current time.
+func NewWarpClock(loc *time.Location, start time.Time, warp float64) Clock {
+	if warp <= 0 {
+		warp = 1
+	}
+	now := time.Now()
+	if start.IsZero() {
+		start = now
+	}
+	return &leagueClock{loc: loc, warp: warp, anchorReal: now, anchorLeague: start}
+}
+
+func (c *leagueClock) Now() time.Time {
+	if c.warp == 1 && c.anchorReal.IsZero() {
+		return time.Now().In(c.loc)
+	}
+	elapsed := time.Since(c.anchorReal)
+	return c.anchorLeague.Add(time.Duration(float64(elapsed) * c.warp)).In(c.loc)
+}
+
+func (c *leagueClock) Location() *time.Location { return c.loc }
+
+func (c *leagueClock) Since(t time.Time) time.Duration { return c.Now().Sub(t) }
+
+func (c *leagueClock) Until(t time.Time) time.Duration { return t.Sub(c.Now()) }
+
+func (c *leagueClock) NewTicker(d time.Duration) *time.Ticker { return time.NewTicker(c.real(d)) }
+
+func (c *leagueClock) NewTimer(d time.Duration) *time.Timer { return time.NewTimer(c.real(d)) }
+
+func (c *leagueClock) Warp() float64 { return c.warp }
+
+// real converts a span of league time to wall-clock time. Tickers need a
+// positive interval, so nothing is shortened below a millisecond.
+func (c *leagueClock) real(d time.Duration) time.Duration {
+	if d <= 0 {
+		return d
+	}
+	return max(time.Duration(float64(d)/c.warp), time.Millisecond)
+}
+
+// ClockFromEnv builds the league clock from the environment:
+//
+//	LEAGUE_TIMEZONE   IANA zone the league runs in (default America/Chicago)
+//	TIME_WARP         league seconds per real second, e.g. 60 plays a fight day in under 12 minutes
+//	TIME_WARP_START   league time the warp starts from, as 2006-01-02T15:04 in the league zone
+func ClockFromEnv() (Clock, error) {
+	zone := os.Getenv("LEAGUE_TIMEZONE")
+	if zone == "" {
+		zone = DefaultLeagueTimezone
+	}
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LEAGUE_TIMEZONE %q: %w", zone, err)
+	}
+
+	raw := os.Getenv("TIME_WARP")
+	if raw == "" {
+		return NewClock(loc), nil
+	}
+	warp, err := strconv.ParseFloat(raw, 64)
+	if err != nil || warp <= 0 {
+		return nil, fmt.Errorf("invalid TIME_WARP %q: must be a positive number", raw)
+	}
+	var start time.Time
+	if s := os.Getenv("TIME_WARP_START"); s != "" {
+		if start, err = time.ParseInLocation("2006-01-02T15:04", s, loc); err != nil {
+			return nil, fmt.Errorf("invalid TIME_WARP_START %q: %w", s, err)
+		}
+	}
+	return NewWarpClock(loc, start, warp), nil
+}
+
+// leagueClockDefault is used by anything that hasn't been handed a clock
+var leagueClockDefault Clock = NewClock(loadLocationOrUTC(DefaultLeagueTimezone))
+
+// DefaultClock is the real-time clock in the default league timezone
+func DefaultClock() Clock { return leagueClockDefault }
+
+// loadLocationOrUTC loads a zone, falling back to UTC when the zone database is missing
+func loadLocationOrUTC(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	crand "crypto/rand"
+	"encoding/binary"
 	"math/rand"
 	"time"
 )
@@ -17,6 +19,32 @@ func VoidReasonSeed(now time.Time) int64 {
 	return now.Unix()
 }
 
+// FightTickSeed is the public part of a tick's seed. Fights add a secret
+// per-fight salt on top (see NewTickSalt), otherwise anyone with the fighters'
+// stats could play a live fight forward before it happens.
 func FightTickSeed(fightID int, tickNumber int) int64 {
 	return int64(fightID*1000000 + tickNumber)
 }
+
+// NewTickSalt draws a fight's secret tick salt. It is kept until the fight is
+// over and then published so the fight can be replayed and verified.
+func NewTickSalt() (int64, error) {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		return 0, err
+	}
+	// Keep the salt positive and small enough that salt + FightTickSeed can't overflow
+	return int64(binary.BigEndian.Uint64(b[:]) >> 2), nil
+}
+
+func MutationSeed(fightID int, fighterID int) int64 {
+	return int64(fightID)*1000003 + int64(fighterID)
+}
+
+// royaleSeedOffset keeps battle royale seeds clear of the range FightTickSeed hands out.
+// Like fights, royales add a secret salt drawn from NewTickSalt when they go active.
+const royaleSeedOffset = int64(1) << 44
+
+func RoyaleTickSeed(royaleID int, tickNumber int) int64 {
+	return royaleSeedOffset + int64(royaleID)*1000000 + int64(tickNumber)
+}
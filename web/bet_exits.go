@@ -0,0 +1,96 @@
+package web
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"spoodblort/database"
+	"spoodblort/fight"
+)
+
+// handleCancelBet withdraws the user's bet before its fight's cancellation
+// cutoff, refunding the stake less the fee
+func (s *Server) handleCancelBet(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	betID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid bet ID", http.StatusBadRequest)
+		return
+	}
+
+	bet, err := s.repo.CancelBet(user.ID, betID, s.clock().Now())
+	if errors.Is(err, database.ErrBetNotOpen) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to cancel bet %d for user %d: %v", betID, user.ID, err)
+		http.Error(w, "Failed to cancel bet", http.StatusInternalServerError)
+		return
+	}
+
+	// Pool fights show live totals, so let watchers see the stake leave
+	if mode, err := s.repo.SettlementModeForFight(bet.FightID); err == nil && mode == database.SettlementParimutuel {
+		s.broadcaster.BroadcastPool(bet.FightID)
+	}
+
+	log.Printf("Bet %d cancelled by user %d: %d refunded of %d", bet.ID, user.ID, bet.Payout.Int64, bet.Amount)
+	http.Redirect(w, r, "/fight/"+strconv.Itoa(bet.FightID), http.StatusSeeOther)
+}
+
+// handleCashOutQuote returns the live cash-out offer on the user's bet
+func (s *Server) handleCashOutQuote(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"error": "unauthorized"})
+		return
+	}
+	betID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "invalid bet id"})
+		return
+	}
+
+	offer, err := s.scheduler.GetEngine().QuoteCashOut(user.ID, betID)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, fight.ErrInPlayClosed) || errors.Is(err, database.ErrBetNotOpen) {
+			status = http.StatusConflict
+		}
+		writeJSON(w, status, map[string]interface{}{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, offer)
+}
+
+// handleCashOutBet settles the user's bet on a live fight at the current offer
+func (s *Server) handleCashOutBet(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	betID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid bet ID", http.StatusBadRequest)
+		return
+	}
+
+	offer, err := s.scheduler.GetEngine().CashOutBet(user.ID, betID)
+	if err != nil {
+		log.Printf("Cash-out rejected for user %d on bet %d: %v", user.ID, betID, err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("Bet %d cashed out by user %d for %d at tick %d", betID, user.ID, offer.Amount, offer.Tick)
+	http.Redirect(w, r, "/fight/"+strconv.Itoa(offer.FightID), http.StatusSeeOther)
+}
@@ -0,0 +1,167 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"spoodblort/database"
+)
+
+// handleCreateChallenge posts a challenge on a scheduled fight, putting the
+// user's stake into escrow until someone takes the other side
+func (s *Server) handleCreateChallenge(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	fightID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid fight ID", http.StatusBadRequest)
+		return
+	}
+	fighterID, err := strconv.Atoi(r.FormValue("fighter_id"))
+	if err != nil {
+		http.Error(w, "Invalid fighter ID", http.StatusBadRequest)
+		return
+	}
+	amount, err := strconv.Atoi(r.FormValue("amount"))
+	if err != nil || amount <= 0 {
+		http.Error(w, "Invalid stake", http.StatusBadRequest)
+		return
+	}
+	if maxAllowed := s.getUserMaxFightBet(user); amount > maxAllowed {
+		http.Error(w, fmt.Sprintf("Stake exceeds allowed maximum (%d)", maxAllowed), http.StatusBadRequest)
+		return
+	}
+
+	challenge, err := s.repo.CreateChallenge(user.ID, fightID, fighterID, amount)
+	if errors.Is(err, database.ErrInsufficientCredits) {
+		http.Error(w, "Insufficient credits", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Challenge rejected for user %d on fight %d: %v", user.ID, fightID, err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("Challenge %d: user %d put %d on fighter %d in fight %d", challenge.ID, user.ID, amount, fighterID, fightID)
+	s.announceChallenge(challenge, false)
+	http.Redirect(w, r, "/fight/"+strconv.Itoa(fightID), http.StatusSeeOther)
+}
+
+// handleAcceptChallenge takes the other side of someone's open challenge
+func (s *Server) handleAcceptChallenge(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	challengeID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid challenge ID", http.StatusBadRequest)
+		return
+	}
+
+	// The acceptor is held to the same bet cap as the challenger
+	open, err := s.repo.GetChallenge(challengeID)
+	if err != nil {
+		http.Error(w, "Challenge not found", http.StatusNotFound)
+		return
+	}
+	if maxAllowed := s.getUserMaxFightBet(user); open.Stake > maxAllowed {
+		http.Error(w, fmt.Sprintf("Stake exceeds allowed maximum (%d)", maxAllowed), http.StatusBadRequest)
+		return
+	}
+
+	challenge, err := s.repo.AcceptChallenge(user.ID, challengeID)
+	switch {
+	case errors.Is(err, database.ErrChallengeNotOpen):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, database.ErrInsufficientCredits):
+		http.Error(w, "Insufficient credits", http.StatusBadRequest)
+		return
+	case err != nil:
+		log.Printf("Failed to accept challenge %d for user %d: %v", challengeID, user.ID, err)
+		http.Error(w, "Failed to accept challenge", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Challenge %d accepted by user %d: %d in escrow", challenge.ID, user.ID, challenge.Pot())
+	s.announceChallenge(challenge, true)
+	http.Redirect(w, r, "/fight/"+strconv.Itoa(challenge.FightID), http.StatusSeeOther)
+}
+
+// handleWithdrawChallenge takes back an open challenge nobody has accepted
+func (s *Server) handleWithdrawChallenge(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	challengeID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid challenge ID", http.StatusBadRequest)
+		return
+	}
+
+	challenge, err := s.repo.WithdrawChallenge(user.ID, challengeID)
+	if errors.Is(err, database.ErrChallengeNotOpen) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to withdraw challenge %d for user %d: %v", challengeID, user.ID, err)
+		http.Error(w, "Failed to withdraw challenge", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Challenge %d withdrawn by user %d", challenge.ID, user.ID)
+	http.Redirect(w, r, "/fight/"+strconv.Itoa(challenge.FightID), http.StatusSeeOther)
+}
+
+// handleChallengesAPI returns the challenges the signed-in user posted or accepted
+func (s *Server) handleChallengesAPI(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"error": "unauthorized"})
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	challenges, err := s.repo.GetUserChallenges(user.ID, limit)
+	if err != nil {
+		log.Printf("failed loading challenges for user %d: %v", user.ID, err)
+		writeJSON(w, http.StatusInternalServerError, map[string]interface{}{"error": "failed to load challenges"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"challenges": challenges})
+}
+
+// announceChallenge posts a new or newly matched challenge to Discord in the background
+func (s *Server) announceChallenge(challenge *database.Challenge, accepted bool) {
+	if s.notifier == nil {
+		return
+	}
+	go func() {
+		fight, err := s.repo.GetFight(challenge.FightID)
+		if err != nil {
+			log.Printf("Failed to load fight %d for challenge announcement: %v", challenge.FightID, err)
+			return
+		}
+		if accepted {
+			err = s.notifier.AnnounceChallengeAccepted(*fight, challenge)
+		} else {
+			err = s.notifier.AnnounceChallenge(*fight, challenge)
+		}
+		if err != nil {
+			log.Printf("Failed to announce challenge %d: %v", challenge.ID, err)
+		}
+	}()
+}
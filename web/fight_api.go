@@ -0,0 +1,179 @@
+package web
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"spoodblort/database"
+
+	"github.com/gorilla/mux"
+)
+
+// writeJSON encodes v as the JSON response body with the given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// handleFightVerifyAPI re-runs a completed fight from its seed and recorded
+// inputs and reports whether the replay matches the stored result
+func (s *Server) handleFightVerifyAPI(w http.ResponseWriter, r *http.Request) {
+	fightID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "invalid fight id"})
+		return
+	}
+
+	verification, err := s.scheduler.GetEngine().VerifyFight(fightID)
+	if err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, verification)
+}
+
+const (
+	defaultFightEventsPage = 200
+	maxFightEventsPage     = 1000
+)
+
+// fightEventJSON is a stored fight event with its payload inlined as JSON
+type fightEventJSON struct {
+	database.FightEvent
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// handleFightEventsAPI pages through a fight's structured event history.
+// Query params: after (event id cursor), limit, and type to filter by a
+// comma-separated list of event types.
+func (s *Server) handleFightEventsAPI(w http.ResponseWriter, r *http.Request) {
+	fightID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "invalid fight id"})
+		return
+	}
+	if _, err := s.repo.GetFight(fightID); err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "fight not found"})
+		return
+	}
+
+	q := r.URL.Query()
+	after, _ := strconv.Atoi(q.Get("after"))
+	limit, err := strconv.Atoi(q.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = defaultFightEventsPage
+	}
+	if limit > maxFightEventsPage {
+		limit = maxFightEventsPage
+	}
+
+	var types []string
+	for _, t := range strings.Split(q.Get("type"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types = append(types, t)
+		}
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	events, err := s.repo.GetFightEvents(fightID, after, limit+1, types)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]interface{}{"error": "failed to load events"})
+		return
+	}
+	hasMore := len(events) > limit
+	if hasMore {
+		events = events[:limit]
+	}
+
+	out := make([]fightEventJSON, 0, len(events))
+	for _, ev := range events {
+		item := fightEventJSON{FightEvent: ev}
+		if ev.PayloadJSON != "" {
+			item.Payload = json.RawMessage(ev.PayloadJSON)
+		}
+		out = append(out, item)
+	}
+
+	nextAfter := after
+	if len(events) > 0 {
+		nextAfter = events[len(events)-1].ID
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"fight_id":   fightID,
+		"events":     out,
+		"next_after": nextAfter,
+		"has_more":   hasMore,
+	})
+}
+
+// handleFightOddsAPI returns the Monte Carlo price for a fight. Scheduled
+// fights that haven't been priced yet are queued and reported as pending.
+func (s *Server) handleFightOddsAPI(w http.ResponseWriter, r *http.Request) {
+	fightID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "invalid fight id"})
+		return
+	}
+	fight, err := s.repo.GetFight(fightID)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "fight not found"})
+		return
+	}
+
+	odds, err := s.repo.GetFightOdds(fightID)
+	if err != nil {
+		if fight.Status == "scheduled" {
+			s.scheduler.GetEngine().RepriceFight(fightID)
+			writeJSON(w, http.StatusAccepted, map[string]interface{}{"fight_id": fightID, "pending": true})
+			return
+		}
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "no odds for this fight"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, odds)
+}
+
+// handleInPlayQuoteAPI returns the current in-play price for a live fight
+func (s *Server) handleInPlayQuoteAPI(w http.ResponseWriter, r *http.Request) {
+	fightID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "invalid fight id"})
+		return
+	}
+
+	quote, err := s.scheduler.GetEngine().QuoteInPlay(fightID)
+	if err != nil {
+		writeJSON(w, http.StatusConflict, map[string]interface{}{"error": err.Error(), "open": false})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"open": true, "quote": quote})
+}
+
+// placeInPlayBet takes a bet on an active fight at the engine's current price.
+// Pool fights close their books at the opening bell, so only fixed-odds fights
+// take in-play bets.
+func (s *Server) placeInPlayBet(w http.ResponseWriter, r *http.Request, user *database.User, fight *database.Fight, fighterID, amount int) {
+	if mode, err := s.repo.SettlementModeForFight(fight.ID); err != nil || mode == database.SettlementParimutuel {
+		http.Error(w, "Betting is closed for this fight", http.StatusBadRequest)
+		return
+	}
+
+	quote, betID, err := s.scheduler.GetEngine().PlaceInPlayBet(user.ID, fight.ID, fighterID, amount)
+	if err != nil {
+		log.Printf("In-play bet rejected for user %d on fight %d: %v", user.ID, fight.ID, err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("In-play bet %d: user %d backed fighter %d on fight %d for %d at %.2f (tick %d)",
+		betID, user.ID, fighterID, fight.ID, amount, quote.OddsFor(fighterID), quote.Tick)
+	http.Redirect(w, r, "/fight/"+strconv.Itoa(fight.ID), http.StatusSeeOther)
+}
@@ -0,0 +1,75 @@
+package web
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// handleCreditStatement returns the signed-in user's credit ledger, newest
+// first. Page back with ?before=<id of the oldest row seen>. Admins can pass
+// ?user_id= to read anyone's statement.
+func (s *Server) handleCreditStatement(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"error": "unauthorized"})
+		return
+	}
+
+	userID := user.ID
+	if raw := r.URL.Query().Get("user_id"); raw != "" {
+		if !isAdmin(user) {
+			writeJSON(w, http.StatusForbidden, map[string]interface{}{"error": "forbidden"})
+			return
+		}
+		id, err := strconv.Atoi(raw)
+		if err != nil || id <= 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "invalid user id"})
+			return
+		}
+		userID = id
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	before, _ := strconv.Atoi(r.URL.Query().Get("before"))
+
+	owner, err := s.repo.GetUser(userID)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "user not found"})
+		return
+	}
+	entries, err := s.repo.GetCreditStatement(userID, limit, before)
+	if err != nil {
+		log.Printf("failed loading credit statement for user %d: %v", userID, err)
+		writeJSON(w, http.StatusInternalServerError, map[string]interface{}{"error": "failed to load statement"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"user_id": userID,
+		"balance": owner.Credits,
+		"entries": entries,
+	})
+}
+
+// handleLedgerReconcile lets admins replay the credit ledger against every
+// user's balance. Responds 409 when any balance has drifted off the books.
+func (s *Server) handleLedgerReconcile(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if !isAdmin(user) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	report, err := s.repo.ReconcileLedger()
+	if err != nil {
+		log.Printf("ledger reconciliation failed: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]interface{}{"error": "reconciliation failed"})
+		return
+	}
+	status := http.StatusOK
+	if !report.Balanced() {
+		status = http.StatusConflict
+		log.Printf("Ledger reconciliation: %d of %d users off the books", len(report.Mismatches), report.Users)
+	}
+	writeJSON(w, status, report)
+}
@@ -0,0 +1,116 @@
+package web
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"spoodblort/database"
+)
+
+// loanOffice is what the Department loan office shows a user: their credit
+// line, and the loan they're carrying with its remaining schedule
+type loanOffice struct {
+	Limit    int                        `json:"limit"`
+	Loan     *database.Loan             `json:"loan"`
+	Schedule []database.LoanInstallment `json:"schedule"`
+	Entries  []database.LoanEntry       `json:"entries,omitempty"`
+}
+
+// loanOffice loads a user's standing with the loan office
+func (s *Server) loanOffice(userID int, now time.Time) (*loanOffice, error) {
+	limit, err := s.repo.GetLoanLimit(userID)
+	if err != nil {
+		return nil, err
+	}
+	loan, err := s.repo.GetOpenLoan(userID)
+	if err != nil {
+		return nil, err
+	}
+	office := &loanOffice{Limit: limit, Loan: loan}
+	if loan != nil {
+		office.Schedule = loan.Schedule(now)
+	}
+	return office, nil
+}
+
+// handleLoansAPI returns the signed-in user's credit line, open loan,
+// repayment schedule and the loan's balance history
+func (s *Server) handleLoansAPI(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"error": "unauthorized"})
+		return
+	}
+	office, err := s.loanOffice(user.ID, s.clock().Now())
+	if err == nil && office.Loan != nil {
+		office.Entries, err = s.repo.GetLoanEntries(office.Loan.ID)
+	}
+	if err != nil {
+		log.Printf("failed loading loans for user %d: %v", user.ID, err)
+		writeJSON(w, http.StatusInternalServerError, map[string]interface{}{"error": "failed to load loans"})
+		return
+	}
+	writeJSON(w, http.StatusOK, office)
+}
+
+// handleTakeLoan draws a loan against the user's credit line
+func (s *Server) handleTakeLoan(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	amount, err := strconv.Atoi(r.FormValue("amount"))
+	if err != nil || amount <= 0 {
+		http.Error(w, "Invalid amount", http.StatusBadRequest)
+		return
+	}
+
+	loan, err := s.repo.TakeLoan(user.ID, amount, s.clock().Now())
+	if errors.Is(err, database.ErrLoanDenied) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to lend %d to user %d: %v", amount, user.ID, err)
+		http.Error(w, "Failed to take loan", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Loan %d: user %d borrowed %d at %d weekly", loan.ID, user.ID, loan.Principal, loan.Installment)
+	go s.scheduler.GetEngine().UpdateUserRolesAfterCreditsChange([]int{user.ID})
+	http.Redirect(w, r, "/user/dashboard", http.StatusSeeOther)
+}
+
+// handleRepayLoan pays down the user's open loan from their credits
+func (s *Server) handleRepayLoan(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	amount, err := strconv.Atoi(r.FormValue("amount"))
+	if err != nil || amount <= 0 {
+		http.Error(w, "Invalid amount", http.StatusBadRequest)
+		return
+	}
+
+	paid, loan, err := s.repo.RepayLoan(user.ID, amount)
+	if errors.Is(err, database.ErrInsufficientCredits) {
+		http.Error(w, "Insufficient credits", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Loan repayment rejected for user %d: %v", user.ID, err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("Loan %d: user %d repaid %d, %d left (%s)", loan.ID, user.ID, paid, loan.Balance, loan.Status)
+	// Paying off a defaulted loan lifts the Debtor role
+	go s.scheduler.GetEngine().UpdateUserRolesAfterCreditsChange([]int{user.ID})
+	http.Redirect(w, r, "/user/dashboard", http.StatusSeeOther)
+}
@@ -0,0 +1,80 @@
+package web
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// handleFightPropsAPI returns the prop markets offered on a scheduled fight
+func (s *Server) handleFightPropsAPI(w http.ResponseWriter, r *http.Request) {
+	fightID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "invalid fight id"})
+		return
+	}
+	fight, err := s.repo.GetFight(fightID)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "fight not found"})
+		return
+	}
+	props, err := s.repo.GetFightPropOdds(fightID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]interface{}{"error": "failed to load props"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"fight_id": fightID,
+		"open":     fight.Status == "scheduled" && len(props) > 0,
+		"props":    props,
+	})
+}
+
+// handlePropBet backs a prop selection on a scheduled fight
+func (s *Server) handlePropBet(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	fightID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid fight ID", http.StatusBadRequest)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	amount, err := strconv.Atoi(r.FormValue("amount"))
+	if err != nil || amount <= 0 {
+		http.Error(w, "Invalid bet amount", http.StatusBadRequest)
+		return
+	}
+	if maxAllowed := s.getUserMaxFightBet(user); amount > maxAllowed {
+		http.Error(w, fmt.Sprintf("Bet exceeds allowed maximum (%d)", maxAllowed), http.StatusBadRequest)
+		return
+	}
+
+	// The fight page sends market:selection from a single picker
+	market, selection := r.FormValue("market"), r.FormValue("selection")
+	if prop := r.FormValue("prop"); prop != "" {
+		market, selection, _ = strings.Cut(prop, ":")
+	}
+	price, betID, err := s.scheduler.GetEngine().PlacePropBet(user.ID, fightID, market, selection, amount)
+	if err != nil {
+		log.Printf("Prop bet rejected for user %d on fight %d: %v", user.ID, fightID, err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("Prop bet %d: user %d backed %s %s on fight %d for %d at %.2f",
+		betID, user.ID, market, selection, fightID, amount, price.Odds)
+	http.Redirect(w, r, "/fight/"+strconv.Itoa(fightID), http.StatusSeeOther)
+}
@@ -0,0 +1,244 @@
+package web
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"spoodblort/database"
+	"spoodblort/fight"
+	"spoodblort/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// handleRoyale shows a battle royale's field, its prices while betting is
+// open, and the live standings once it starts
+func (s *Server) handleRoyale(w http.ResponseWriter, r *http.Request) {
+	royaleID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid battle royale ID", http.StatusBadRequest)
+		return
+	}
+
+	royale, err := s.repo.GetBattleRoyale(royaleID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Battle royale not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	entrants, err := s.repo.GetBattleRoyaleEntrants(royaleID)
+	if err != nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	user := GetUserFromContext(r.Context())
+	data := PageData{
+		User:           user,
+		Title:          royale.Name,
+		RequiredCSS:    []string{"watch.css", "royale.css"},
+		Now:            s.clock().Now(),
+		Royale:         royale,
+		RoyaleEntrants: entrants,
+		IsAdmin:        isAdmin(user),
+	}
+
+	if royale.Status == "scheduled" {
+		if quote, err := s.scheduler.GetEngine().QuoteRoyale(royaleID); err == nil {
+			data.RoyaleQuote = quote
+		} else {
+			log.Printf("Failed to price battle royale %d: %v", royaleID, err)
+		}
+	}
+
+	if user != nil {
+		data.PrimaryColor, data.SecondaryColor = utils.GenerateUserColors(user.DiscordID)
+		data.FightBetMax = s.getUserMaxFightBet(user)
+		if bets, err := s.repo.GetUserRoyaleBets(user.ID, royaleID); err == nil {
+			data.RoyaleBets = bets
+		}
+	}
+
+	s.renderTemplate(w, "royale.html", data)
+}
+
+// handleRoyaleAPI returns a battle royale with its entrants and, while
+// betting is open, its prices
+func (s *Server) handleRoyaleAPI(w http.ResponseWriter, r *http.Request) {
+	royaleID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "invalid battle royale id"})
+		return
+	}
+	royale, err := s.repo.GetBattleRoyale(royaleID)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "battle royale not found"})
+		return
+	}
+	entrants, err := s.repo.GetBattleRoyaleEntrants(royaleID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]interface{}{"error": "failed to load entrants"})
+		return
+	}
+
+	resp := map[string]interface{}{"royale": royale, "entrants": entrants}
+	if royale.Status == "completed" {
+		// The salt only comes out once the result is in, so the royale can be replayed
+		resp["tick_salt"] = royale.TickSalt
+	}
+	if royale.Status == "scheduled" {
+		if quote, err := s.scheduler.GetEngine().QuoteRoyale(royaleID); err == nil {
+			resp["quote"] = quote
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleRoyaleBet backs a fighter to win a battle royale or to finish in its top 3
+func (s *Server) handleRoyaleBet(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	royaleID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid battle royale ID", http.StatusBadRequest)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	fighterID, err := strconv.Atoi(r.FormValue("fighter_id"))
+	if err != nil {
+		http.Error(w, "Invalid fighter ID", http.StatusBadRequest)
+		return
+	}
+	amount, err := strconv.Atoi(r.FormValue("amount"))
+	if err != nil || amount <= 0 {
+		http.Error(w, "Invalid bet amount", http.StatusBadRequest)
+		return
+	}
+	if maxAllowed := s.getUserMaxFightBet(user); amount > maxAllowed {
+		http.Error(w, fmt.Sprintf("Bet exceeds allowed maximum (%d)", maxAllowed), http.StatusBadRequest)
+		return
+	}
+
+	betType := r.FormValue("bet_type")
+	odds, betID, err := s.scheduler.GetEngine().PlaceRoyaleBet(user.ID, royaleID, fighterID, betType, amount)
+	if err != nil {
+		log.Printf("Battle royale bet rejected for user %d on royale %d: %v", user.ID, royaleID, err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("Battle royale bet %d: user %d backed fighter %d (%s) in royale %d for %d at %.2f",
+		betID, user.ID, fighterID, betType, royaleID, amount, odds)
+	http.Redirect(w, r, "/royale/"+strconv.Itoa(royaleID), http.StatusSeeOther)
+}
+
+// handleRoyaleCreate lets admins book a battle royale. fighter_ids is a comma
+// separated list; leave it empty to draw the field from eligible fighters.
+func (s *Server) handleRoyaleCreate(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if !isAdmin(user) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	if name == "" {
+		name = "Battle Royale"
+	}
+
+	at, err := time.ParseInLocation("2006-01-02T15:04", strings.TrimSpace(r.FormValue("scheduled_time")), s.clock().Location())
+	if err != nil {
+		http.Error(w, "Invalid scheduled time", http.StatusBadRequest)
+		return
+	}
+
+	var fighterIDs []int
+	for _, field := range strings.Split(r.FormValue("fighter_ids"), ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		id, err := strconv.Atoi(field)
+		if err != nil {
+			http.Error(w, "Invalid fighter id "+field, http.StatusBadRequest)
+			return
+		}
+		fighterIDs = append(fighterIDs, id)
+	}
+
+	royaleID, err := s.scheduler.ScheduleBattleRoyale(name, at, fighterIDs)
+	if err != nil {
+		log.Printf("failed creating battle royale: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Admin %s scheduled battle royale %d for %s", user.Username, royaleID, at.Format(time.RFC3339))
+	http.Redirect(w, r, fmt.Sprintf("/royale/%d", royaleID), http.StatusSeeOther)
+}
+
+// handleRoyaleVoid lets admins call off a battle royale and refund its bets
+func (s *Server) handleRoyaleVoid(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if !isAdmin(user) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+
+	royaleID, err := strconv.Atoi(strings.TrimSpace(r.FormValue("royale_id")))
+	if err != nil || royaleID <= 0 {
+		http.Error(w, "Invalid battle royale id", http.StatusBadRequest)
+		return
+	}
+	if err := s.repo.VoidBattleRoyale(royaleID); err != nil {
+		log.Printf("failed voiding battle royale %d: %v", royaleID, err)
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	log.Printf("Admin %s voided battle royale %d", user.Username, royaleID)
+	http.Redirect(w, r, fmt.Sprintf("/royale/%d", royaleID), http.StatusSeeOther)
+}
+
+// royaleOddsFor is a template helper looking up an entrant's prices
+func royaleOddsFor(quote *fight.RoyaleQuote, fighterID int) *fight.RoyaleOdds {
+	if quote == nil {
+		return nil
+	}
+	for i := range quote.Entrants {
+		if quote.Entrants[i].FighterID == fighterID {
+			return &quote.Entrants[i]
+		}
+	}
+	return nil
+}
+
+// royaleBetLabel names a royale bet type for display
+func royaleBetLabel(betType string) string {
+	if betType == database.RoyaleBetTop3 {
+		return "Top 3"
+	}
+	return "Winner"
+}
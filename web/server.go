@@ -6,6 +6,7 @@ import (
 	"database/sql"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
@@ -22,6 +23,7 @@ import (
 
 	"spoodblort/database"
 	"spoodblort/discord"
+	"spoodblort/fight"
 	"spoodblort/scheduler"
 	"spoodblort/utils"
 )
@@ -100,6 +102,12 @@ type PageData struct {
 	Fighter         *database.Fighter
 	Fight           *database.Fight
 	FightKill       *database.FighterKill
+	FightOdds       *database.FightOdds
+	PropOdds        []database.FightPropOdds
+	PropBets        []database.PropBet
+	Challenges      []database.ChallengeWithNames
+	SettlementMode  string
+	BetPool         *database.BetPool
 	Users           []database.User
 	Fighters        []database.Fighter
 	FighterMap      map[int]*database.Fighter // For looking up fighters by ID in templates
@@ -110,6 +118,12 @@ type PageData struct {
 	AllBets         []database.BetWithUser
 	UserBets        []database.BetWithFight
 	UserBetFightIDs map[int]bool
+	CanCancelBet    bool // the user's bet is still inside the cancellation window
+	BetCancelFee    int
+	CanCashOut      bool // the user's bet is on a live fight and may have a cash-out offer
+	BetSlips        []database.BetSlipWithLegs
+	SlipCard        []slipCardFight // today's fights open to parlays
+	LoanOffice      *loanOffice
 	// Meta tags for social media
 	MetaDescription             string
 	MetaImage                   string
@@ -139,6 +153,13 @@ type PageData struct {
 	FighterKillCount            int
 	FighterPastFights           []database.Fight
 	FighterKillVictims          map[int]int
+	FighterMutations            []database.FighterMutation
+	FighterRivals               []database.Rival
+	// Battle royale page
+	Royale         *database.BattleRoyale
+	RoyaleEntrants []database.RoyaleEntrant
+	RoyaleQuote    *fight.RoyaleQuote
+	RoyaleBets     []database.RoyaleBet
 	// MVP-related fields
 	CurrentMVP   *database.UserSetting
 	CanChangeMVP bool
@@ -192,6 +213,11 @@ func NewServer(repo *database.Repository, scheduler *scheduler.Scheduler, sessio
 	return s
 }
 
+// clock is the league clock pages and handlers read the time from
+func (s *Server) clock() utils.Clock {
+	return s.repo.Clock()
+}
+
 // GetBroadcaster returns the fight broadcaster for use by background processes
 func (s *Server) GetBroadcaster() *FightBroadcaster {
 	return s.broadcaster
@@ -242,11 +268,21 @@ func (s *Server) setupRoutes() {
 
 	// WebSocket route (public, no auth required for watching)
 	public.HandleFunc("/ws/fight/{id:[0-9]+}", s.broadcaster.HandleWebSocket)
+	public.HandleFunc("/ws/royale/{id:[0-9]+}", s.broadcaster.HandleRoyaleWebSocket)
+
+	// Battle royales
+	public.HandleFunc("/royale/{id:[0-9]+}", s.handleRoyale).Methods("GET")
+	public.HandleFunc("/api/royale/{id:[0-9]+}", s.handleRoyaleAPI).Methods("GET")
 
 	// Internal JSON endpoints
 	public.HandleFunc("/api/schedule/today", s.handleScheduleTodayAPI).Methods("GET")
 	public.HandleFunc("/api/fighters", s.handleFightersAPI).Methods("GET")
 	public.HandleFunc("/api/fights", s.handleFightsAPI).Methods("GET")
+	public.HandleFunc("/api/fights/{id:[0-9]+}/verify", s.handleFightVerifyAPI).Methods("GET")
+	public.HandleFunc("/api/fights/{id:[0-9]+}/events", s.handleFightEventsAPI).Methods("GET")
+	public.HandleFunc("/api/fights/{id:[0-9]+}/odds", s.handleFightOddsAPI).Methods("GET")
+	public.HandleFunc("/api/fights/{id:[0-9]+}/inplay", s.handleInPlayQuoteAPI).Methods("GET")
+	public.HandleFunc("/api/fights/{id:[0-9]+}/props", s.handleFightPropsAPI).Methods("GET")
 
 	// Protected routes (require authentication)
 	protected := s.router.PathPrefix("/user").Subrouter()
@@ -265,6 +301,27 @@ func (s *Server) setupRoutes() {
 
 	// Add betting routes
 	protected.HandleFunc("/fight/{id}/bet", s.handlePlaceBet).Methods("POST")
+	protected.HandleFunc("/royale/{id:[0-9]+}/bet", s.handleRoyaleBet).Methods("POST")
+	protected.HandleFunc("/fight/{id:[0-9]+}/prop", s.handlePropBet).Methods("POST")
+	protected.HandleFunc("/slips", s.handleBetSlip).Methods("POST")
+	protected.HandleFunc("/bets/{id:[0-9]+}/cancel", s.handleCancelBet).Methods("POST")
+	protected.HandleFunc("/bets/{id:[0-9]+}/cashout", s.handleCashOutQuote).Methods("GET")
+	protected.HandleFunc("/bets/{id:[0-9]+}/cashout", s.handleCashOutBet).Methods("POST")
+	protected.HandleFunc("/slips", s.handleBetSlipsAPI).Methods("GET")
+
+	// Department loan office
+	protected.HandleFunc("/loans", s.handleLoansAPI).Methods("GET")
+	protected.HandleFunc("/loans", s.handleTakeLoan).Methods("POST")
+	protected.HandleFunc("/loans/repay", s.handleRepayLoan).Methods("POST")
+
+	// Challenges between users
+	protected.HandleFunc("/fight/{id:[0-9]+}/challenge", s.handleCreateChallenge).Methods("POST")
+	protected.HandleFunc("/challenges/{id:[0-9]+}/accept", s.handleAcceptChallenge).Methods("POST")
+	protected.HandleFunc("/challenges/{id:[0-9]+}/withdraw", s.handleWithdrawChallenge).Methods("POST")
+	protected.HandleFunc("/challenges", s.handleChallengesAPI).Methods("GET")
+
+	// Credit ledger statement
+	protected.HandleFunc("/credits/statement", s.handleCreditStatement).Methods("GET")
 
 	// Shop purchase route (requires auth)
 	protected.HandleFunc("/shop/purchase", s.handleShopPurchase).Methods("POST")
@@ -303,6 +360,11 @@ func (s *Server) setupRoutes() {
 	protectedGeneral.HandleFunc("/fighter/edit", s.handleFighterEdit).Methods("POST")
 	protectedGeneral.HandleFunc("/fighter/avatar/upload", s.handleFighterAvatarUpload).Methods("POST")
 	protectedGeneral.HandleFunc("/fighter/avatar/clear", s.handleFighterAvatarClear).Methods("POST")
+	protectedGeneral.HandleFunc("/fight/ruleset", s.handleFightRuleset).Methods("POST")
+	protectedGeneral.HandleFunc("/fight/settlement", s.handleFightSettlement).Methods("POST")
+	protectedGeneral.HandleFunc("/royale/create", s.handleRoyaleCreate).Methods("POST")
+	protectedGeneral.HandleFunc("/royale/void", s.handleRoyaleVoid).Methods("POST")
+	protectedGeneral.HandleFunc("/admin/ledger/reconcile", s.handleLedgerReconcile).Methods("GET")
 }
 
 // handleBlog renders the proclamations blog page
@@ -357,11 +419,10 @@ func (s *Server) handleWeather(w http.ResponseWriter, r *http.Request) {
 	user := GetUserFromContext(r.Context())
 
 	// Determine reference date (supports ?date=YYYY-MM-DD) but do not allow future dates
-	now := time.Now()
+	now := s.clock().Now()
 	if ds := r.URL.Query().Get("date"); ds != "" {
-		if d, err := time.ParseInLocation("2006-01-02", ds, time.Local); err == nil {
-			todayLocal := time.Now().In(time.Local)
-			todayDate := time.Date(todayLocal.Year(), todayLocal.Month(), todayLocal.Day(), 0, 0, 0, 0, time.Local)
+		if d, err := time.ParseInLocation("2006-01-02", ds, now.Location()); err == nil {
+			todayDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 			if d.After(todayDate) {
 				now = todayDate
 			} else {
@@ -395,7 +456,7 @@ func (s *Server) handleWeather(w http.ResponseWriter, r *http.Request) {
 	if weekly != nil {
 		start = weekly.WeekStart
 	} else {
-		// Fallback: compute Monday of current week in local tz
+		// Fallback: compute Monday of current week in league time
 		start = time.Date(now.Year(), now.Month(), now.Day()-int(now.Weekday())+1, 0, 0, 0, 0, now.Location())
 	}
 	end = start.AddDate(0, 0, 6)
@@ -429,7 +490,7 @@ func (s *Server) handleWeather(w http.ResponseWriter, r *http.Request) {
 		Now:                  now,
 		ShowHistoricalBanner: start.Before(networkStart),
 		NetworkStart:         networkStart,
-		ShowNextNav:          now.Before(time.Now().In(time.Local).Truncate(24 * time.Hour)),
+		ShowNextNav:          now.Before(s.clock().Now().Truncate(24 * time.Hour)),
 		MetaDescription:      "📡 Recreational meteorology: weekly card and daily drift.",
 		MetaType:             "website",
 	}
@@ -632,10 +693,101 @@ func (s *Server) handleFighterAvatarClear(w http.ResponseWriter, r *http.Request
 	http.Redirect(w, r, fmt.Sprintf("/fighter/%d", fighterID), http.StatusSeeOther)
 }
 
+// handleFightRuleset lets admins pick the combat ruleset for a scheduled fight
+// or for a whole tournament. An empty ruleset means "inherit".
+func (s *Server) handleFightRuleset(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if !isAdmin(user) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+
+	ruleset := strings.TrimSpace(r.FormValue("ruleset"))
+	if ruleset != "" {
+		if _, err := fight.LookupRuleset(ruleset, ""); err != nil {
+			http.Error(w, "Unknown ruleset", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if tournamentID, err := strconv.Atoi(strings.TrimSpace(r.FormValue("tournament_id"))); err == nil && tournamentID > 0 {
+		if err := s.repo.SetTournamentRuleset(tournamentID, ruleset); err != nil {
+			log.Printf("failed setting ruleset for tournament %d: %v", tournamentID, err)
+			http.Error(w, "Update failed", http.StatusInternalServerError)
+			return
+		}
+		log.Printf("Admin %s set tournament %d ruleset to %q", user.Username, tournamentID, ruleset)
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	fightID, err := strconv.Atoi(strings.TrimSpace(r.FormValue("fight_id")))
+	if err != nil || fightID <= 0 {
+		http.Error(w, "Invalid fight id", http.StatusBadRequest)
+		return
+	}
+	if err := s.repo.SetFightRuleset(fightID, ruleset); err != nil {
+		log.Printf("failed setting ruleset for fight %d: %v", fightID, err)
+		http.Error(w, "Only scheduled fights can change rules", http.StatusConflict)
+		return
+	}
+	log.Printf("Admin %s set fight %d ruleset to %q", user.Username, fightID, ruleset)
+	http.Redirect(w, r, fmt.Sprintf("/fight/%d", fightID), http.StatusSeeOther)
+}
+
+// handleFightSettlement lets admins choose fixed-odds or parimutuel settlement
+// for a tournament (tournament_id) or a single scheduled fight (fight_id)
+func (s *Server) handleFightSettlement(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if !isAdmin(user) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+
+	mode := strings.TrimSpace(r.FormValue("mode"))
+	if !database.ValidSettlementMode(mode) {
+		http.Error(w, "Unknown settlement mode", http.StatusBadRequest)
+		return
+	}
+
+	if tournamentID, err := strconv.Atoi(strings.TrimSpace(r.FormValue("tournament_id"))); err == nil && tournamentID > 0 {
+		if err := s.repo.SetTournamentSettlementMode(tournamentID, mode); err != nil {
+			log.Printf("failed setting settlement mode for tournament %d: %v", tournamentID, err)
+			http.Error(w, "Update failed", http.StatusInternalServerError)
+			return
+		}
+		log.Printf("Admin %s set tournament %d settlement mode to %q", user.Username, tournamentID, mode)
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	fightID, err := strconv.Atoi(strings.TrimSpace(r.FormValue("fight_id")))
+	if err != nil || fightID <= 0 {
+		http.Error(w, "Invalid fight id", http.StatusBadRequest)
+		return
+	}
+	if err := s.repo.SetFightSettlementMode(fightID, mode); err != nil {
+		log.Printf("failed setting settlement mode for fight %d: %v", fightID, err)
+		http.Error(w, "Only scheduled fights can change settlement", http.StatusConflict)
+		return
+	}
+	log.Printf("Admin %s set fight %d settlement mode to %q", user.Username, fightID, mode)
+	http.Redirect(w, r, fmt.Sprintf("/fight/%d", fightID), http.StatusSeeOther)
+}
+
 // handleSaturday renders the Saturday special schedule view
 func (s *Server) handleSaturday(w http.ResponseWriter, r *http.Request) {
-	centralTime, _ := time.LoadLocation("America/Chicago")
-	now := time.Now().In(centralTime)
+	now := s.clock().Now()
 
 	user := GetUserFromContext(r.Context())
 	data := PageData{
@@ -672,7 +824,7 @@ func (s *Server) handleSaturday(w http.ResponseWriter, r *http.Request) {
 		if ferr == nil {
 			data.Tournament = tournament
 			data.Fights = fights
-			data.SaturdayFights = toScheduleDTOs(fights)
+			data.SaturdayFights = toScheduleDTOs(fights, s.clock().Location())
 		}
 	}
 
@@ -801,8 +953,7 @@ func (s *Server) getUserMaxFightBet(user *database.User) int {
 }
 
 func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
-	centralTime, _ := time.LoadLocation("America/Chicago")
-	now := time.Now().In(centralTime)
+	now := s.clock().Now()
 
 	// Check if it's Sunday - serve closed page
 	if now.Weekday() == time.Sunday {
@@ -1102,8 +1253,20 @@ func (s *Server) handleFighter(w http.ResponseWriter, r *http.Request) {
 	killCount := 0
 	killVictims := map[int]int{}
 	var pastFights []database.Fight
+	var mutations []database.FighterMutation
+	var rivals []database.Rival
 	if fighter != nil {
 		legacyCount, _ = s.repo.CountChampionTitlesForFighter(fighter.ID)
+		if rv, err := s.repo.GetRivalsForFighter(fighter.ID, 8); err == nil {
+			rivals = rv
+		} else {
+			log.Printf("failed to load rivals for fighter %d: %v", fighter.ID, err)
+		}
+		if m, err := s.repo.GetFighterMutations(fighter.ID); err == nil {
+			mutations = m
+		} else {
+			log.Printf("failed to load mutations for fighter %d: %v", fighter.ID, err)
+		}
 		legacyRecords, _ = s.repo.GetChampionLegacyRecordsForFighter(fighter.ID)
 		if kc, err := s.repo.CountFighterKills(fighter.ID); err == nil {
 			killCount = kc
@@ -1136,6 +1299,8 @@ func (s *Server) handleFighter(w http.ResponseWriter, r *http.Request) {
 		FighterKillCount:   killCount,
 		FighterPastFights:  pastFights,
 		FighterKillVictims: killVictims,
+		FighterMutations:   mutations,
+		FighterRivals:      rivals,
 	}
 
 	// If this is a custom fighter with a creator, get the creator's info
@@ -1350,21 +1515,44 @@ func (s *Server) handleFight(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var fightKill *database.FighterKill
+	var fightOdds *database.FightOdds
 	if fight != nil {
 		fightKill = s.repo.GetKillForFight(fight.ID)
+		if odds, err := s.repo.GetFightOdds(fight.ID); err == nil {
+			fightOdds = odds
+		}
+	}
+
+	var propOdds []database.FightPropOdds
+	if fight != nil && fight.Status == "scheduled" {
+		propOdds, _ = s.repo.GetFightPropOdds(fight.ID)
 	}
 
 	user := GetUserFromContext(r.Context())
-	now := time.Now()
+	now := s.clock().Now()
 	data := PageData{
 		User:        user,
 		Title:       "Fight Details",
 		Fight:       fight,
 		FightKill:   fightKill,
+		FightOdds:   fightOdds,
+		PropOdds:    propOdds,
 		RequiredCSS: []string{"fight.css"},
 		Now:         now,
 	}
 
+	if fight != nil {
+		if challenges, err := s.repo.GetChallengesForFight(fight.ID); err == nil {
+			data.Challenges = challenges
+		}
+		if mode, err := s.repo.SettlementModeForFight(fight.ID); err == nil {
+			data.SettlementMode = mode
+			if mode == database.SettlementParimutuel {
+				data.BetPool, _ = s.repo.GetBetPool(fight.ID)
+			}
+		}
+	}
+
 	if fight != nil {
 		data.Title = fmt.Sprintf("%s vs %s", fight.Fighter1Name, fight.Fighter2Name)
 		statusText := "SCHEDULED FOR MAXIMUM VIOLENCE"
@@ -1389,6 +1577,11 @@ func (s *Server) handleFight(w http.ResponseWriter, r *http.Request) {
 		userBet, err := s.repo.GetUserBetOnFight(user.ID, fightID)
 		if err == nil {
 			data.UserBet = userBet
+			if database.CanCancelBet(userBet, fight, now) {
+				data.BetCancelFee = database.BetCancelFee(userBet.Amount)
+				data.CanCancelBet = true
+			}
+			data.CanCashOut = userBet.Status == "pending" && fight.Status == "active"
 		}
 
 		// Get all bets on this fight
@@ -1397,6 +1590,10 @@ func (s *Server) handleFight(w http.ResponseWriter, r *http.Request) {
 			data.AllBets = allBets
 		}
 
+		if propBets, err := s.repo.GetUserPropBets(user.ID, fightID); err == nil {
+			data.PropBets = propBets
+		}
+
 		// Get user's inventory for bless/curse options
 		userInventory, err := s.repo.GetUserInventory(user.ID)
 		if err == nil {
@@ -1427,9 +1624,8 @@ func (s *Server) handleFight(w http.ResponseWriter, r *http.Request) {
 		// Determine which date to use for effects based on fight status
 		var effectDate time.Time
 		if fight.Status == "active" || fight.Status == "scheduled" {
-			// For active and scheduled fights, use today's effects (live viewing) in Central Time
-			centralTime, _ := time.LoadLocation("America/Chicago")
-			effectDate = time.Now().In(centralTime)
+			// For active and scheduled fights, use today's effects (live viewing) in league time
+			effectDate = s.clock().Now()
 		} else {
 			// For completed/voided fights, use the fight's scheduled date (historical viewing)
 			effectDate = fight.ScheduledTime
@@ -1550,12 +1746,12 @@ func (s *Server) handleFight(w http.ResponseWriter, r *http.Request) {
 		// Filter effects to only show ones from the same date range as fighter effects
 		var filteredUserEffects []database.AppliedEffectWithUser
 		for _, effect := range userEffectsOnFight {
-			// Convert effect's created_at to Central Time for proper comparison
-			centralTime, _ := time.LoadLocation("America/Chicago")
-			effectTimeInCentral := effect.CreatedAt.In(centralTime)
+			// Convert effect's created_at to league time for proper comparison
+			leagueTime := s.clock().Location()
+			effectTimeInLeague := effect.CreatedAt.In(leagueTime)
 
 			// Check if effect's created_at is within our date range
-			if (effectTimeInCentral.After(startDate) || effectTimeInCentral.Equal(startDate)) && effectTimeInCentral.Before(endDate) {
+			if (effectTimeInLeague.After(startDate) || effectTimeInLeague.Equal(startDate)) && effectTimeInLeague.Before(endDate) {
 				filteredUserEffects = append(filteredUserEffects, effect)
 			}
 		}
@@ -1628,6 +1824,11 @@ func (s *Server) handlePlaceBet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if fight.Status == "active" {
+		s.placeInPlayBet(w, r, user, fight, fighterID, amount)
+		return
+	}
+
 	if fight.Status != "scheduled" {
 		http.Error(w, "Betting is closed for this fight", http.StatusBadRequest)
 		return
@@ -1646,20 +1847,21 @@ func (s *Server) handlePlaceBet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Create the bet and deduct credits in a transaction-like manner
+	// Create the bet and deduct credits in one transaction
 	err = s.repo.CreateBet(user.ID, fightID, fighterID, amount)
+	if errors.Is(err, database.ErrInsufficientCredits) {
+		http.Error(w, "Insufficient credits", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		log.Printf("Failed to create bet: %v", err)
 		http.Error(w, "Failed to place bet", http.StatusInternalServerError)
 		return
 	}
 
-	// Deduct credits from user
-	err = s.repo.UpdateUserCredits(user.ID, user.Credits-amount)
-	if err != nil {
-		log.Printf("Failed to deduct credits: %v", err)
-		http.Error(w, "Failed to process bet", http.StatusInternalServerError)
-		return
+	// Pool fights show live totals, so let watchers see the new stake
+	if mode, err := s.repo.SettlementModeForFight(fightID); err == nil && mode == database.SettlementParimutuel {
+		s.broadcaster.BroadcastPool(fightID)
 	}
 
 	// Redirect back to fight page
@@ -1697,12 +1899,29 @@ func (s *Server) handleUserDashboard(w http.ResponseWriter, r *http.Request) {
 		bettingStats = nil
 	}
 
+	// Fetch user's parlays
+	betSlips, err := s.repo.GetUserBetSlips(user.ID, 10)
+	if err != nil {
+		log.Printf("Error fetching bet slips: %v", err)
+		betSlips = nil
+	}
+
+	// Fetch the user's standing with the loan office
+	office, err := s.loanOffice(user.ID, s.clock().Now())
+	if err != nil {
+		log.Printf("Error fetching loans: %v", err)
+		office = nil
+	}
+
 	data := PageData{
 		User:           user,
 		Title:          "Dashboard",
 		PrimaryColor:   primaryColor,
 		SecondaryColor: secondaryColor,
 		UserBets:       userBets,
+		BetSlips:       betSlips,
+		SlipCard:       s.slipCard(s.clock().Now()),
+		LoanOffice:     office,
 		UserInventory:  userInventory,
 		BettingStats:   bettingStats,
 		RequiredCSS:    []string{"dashboard.css"},
@@ -1903,8 +2122,7 @@ func (s *Server) handleUpdateMVP(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Get current tournament week to set next change date
-		centralTime, _ := time.LoadLocation("America/Chicago")
-		now := time.Now().In(centralTime)
+		now := s.clock().Now()
 
 		// Calculate next tournament start (add 7 days)
 		nextTournamentStart := now.AddDate(0, 0, 7)
@@ -2019,9 +2237,8 @@ func (s *Server) handleWatch(w http.ResponseWriter, r *http.Request) {
 		// Determine which date to use for effects based on fight status
 		var effectDate time.Time
 		if fight.Status == "active" || fight.Status == "scheduled" {
-			// For active and scheduled fights, use today's effects (live viewing) in Central Time
-			centralTime, _ := time.LoadLocation("America/Chicago")
-			effectDate = time.Now().In(centralTime)
+			// For active and scheduled fights, use today's effects (live viewing) in league time
+			effectDate = s.clock().Now()
 		} else {
 			// For completed/voided fights, use the fight's scheduled date (historical viewing)
 			effectDate = fight.ScheduledTime
@@ -2079,8 +2296,7 @@ func (s *Server) handleWatch(w http.ResponseWriter, r *http.Request) {
 		// Use the same date calculation logic as above
 		var effectDate time.Time
 		if fight.Status == "active" || fight.Status == "scheduled" {
-			centralTime, _ := time.LoadLocation("America/Chicago")
-			effectDate = time.Now().In(centralTime)
+			effectDate = s.clock().Now()
 		} else {
 			effectDate = fight.ScheduledTime
 		}
@@ -2456,7 +2672,7 @@ func (s *Server) handleApplyEffect(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
-	if fight.Status != "scheduled" || time.Now().After(fight.ScheduledTime) {
+	if fight.Status != "scheduled" || s.clock().Now().After(fight.ScheduledTime) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]interface{}{
@@ -2467,8 +2683,7 @@ func (s *Server) handleApplyEffect(w http.ResponseWriter, r *http.Request) {
 	}
 	var effectDate time.Time
 	if fight.Status == "active" || fight.Status == "scheduled" {
-		centralTime, _ := time.LoadLocation("America/Chicago")
-		effectDate = time.Now().In(centralTime)
+		effectDate = s.clock().Now()
 	} else {
 		effectDate = fight.ScheduledTime
 	}
@@ -2592,6 +2807,9 @@ func (s *Server) handleApplyEffect(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// The matchup just changed, so the odds need re-running
+	s.scheduler.GetEngine().RepriceFight(fight.ID)
+
 	// Determine which side (f1/f2) the target is for DOM updates
 	targetSide := "f2"
 	if req.FighterID == fight.Fighter1ID {
@@ -2810,7 +3028,7 @@ func (s *Server) handleCreateFighterPost(w http.ResponseWriter, r *http.Request)
 	fighterClass := generateFighterClass()
 
 	// Create the fighter
-	now := time.Now()
+	now := s.clock().Now()
 	fighterID, err := s.repo.CreateCustomFighter(database.Fighter{
 		Name:                      req.Name,
 		Team:                      "Custom Fighters",
@@ -2998,7 +3216,7 @@ func (s *Server) handleSponsorshipAssign(w http.ResponseWriter, r *http.Request)
 			"team":        fighter.Team,
 			"record":      fmt.Sprintf("%dW-%dL-%dD", fighter.Wins, fighter.Losses, fighter.Draws),
 			"avatar":      fighter.AvatarURL,
-			"licensed_at": time.Now().Format(time.RFC3339),
+			"licensed_at": s.clock().Now().Format(time.RFC3339),
 		},
 	}
 
@@ -3106,7 +3324,7 @@ func (s *Server) handleHybridCreate(w http.ResponseWriter, r *http.Request) {
 
 	team := "Free Agent"
 
-	now := time.Now()
+	now := s.clock().Now()
 	description := fmt.Sprintf("Lab-bred hybrid of %s and %s. Licensed by @%s.", parent1.Name, parent2.Name, getDisplayName(user.Username, user.CustomUsername))
 	lore := fmt.Sprintf("%s + %s were compelled to share genetic materials in an unlit warehouse. The result is %s.", parent1.Name, parent2.Name, req.Name)
 	fighter := database.Fighter{
@@ -3402,9 +3620,8 @@ func (s *Server) handleCasino(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Only accessible on Sundays (America/Chicago). Redirect home otherwise.
-	centralTime, _ := time.LoadLocation("America/Chicago")
-	now := time.Now().In(centralTime)
+	// Only accessible on Sundays in league time. Redirect home otherwise.
+	now := s.clock().Now()
 	if now.Weekday() != time.Sunday {
 		http.Redirect(w, r, "/", http.StatusSeeOther)
 		return
@@ -3434,7 +3651,7 @@ func (s *Server) handleCasino(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check if it's Sunday and assign VIP role if they don't have it
-	// Note: centralTime/now already defined above
+	// Note: now already defined above
 	if now.Weekday() == time.Sunday {
 		// Get the role manager from the scheduler's engine
 		engine := s.scheduler.GetEngine()
@@ -3481,6 +3698,21 @@ func (s *Server) handleCasino(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// casinoStake charges a casino bet to the user's ledger
+func casinoStake(userID int, game string, amount int) database.CreditMove {
+	return database.CreditMove{UserID: userID, Amount: -amount, Reason: database.ReasonCasinoStake, Ref: database.CasinoGameRef(game)}
+}
+
+// casinoPayout pays casino winnings; a zero payout posts nothing
+func casinoPayout(userID int, game string, payout int) database.CreditMove {
+	return database.CreditMove{UserID: userID, Amount: payout, Reason: database.ReasonCasinoPayout, Ref: database.CasinoGameRef(game)}
+}
+
+// casinoRefund hands back a stake the game neither won nor lost
+func casinoRefund(userID int, game string, stake int) database.CreditMove {
+	return database.CreditMove{UserID: userID, Amount: stake, Reason: database.ReasonCasinoRefund, Ref: database.CasinoGameRef(game)}
+}
+
 // handleMoonFlip processes moon phase coin flip bets
 func (s *Server) handleMoonFlip(w http.ResponseWriter, r *http.Request) {
 	user := GetUserFromContext(r.Context())
@@ -3562,18 +3794,17 @@ func (s *Server) handleMoonFlip(w http.ResponseWriter, r *http.Request) {
 
 	// Determine win/loss
 	won := result == req.Choice
-	var newBalance int
 	var payout int
 
 	if won {
 		payout = req.Amount * 2 // 2x payout
-		newBalance = user.Credits + payout - req.Amount
-	} else {
-		newBalance = user.Credits - req.Amount
 	}
 
 	// Update user credits
-	err = s.repo.UpdateUserCredits(user.ID, newBalance)
+	newBalance, err := s.repo.MoveCredits(
+		casinoStake(user.ID, "moonflip", req.Amount),
+		casinoPayout(user.ID, "moonflip", payout),
+	)
 	if err != nil {
 		log.Printf("Failed to update user credits: %v", err)
 		w.Header().Set("Content-Type", "application/json")
@@ -3663,8 +3894,7 @@ func (s *Server) handleHiLowStep1(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// SECURITY: Charge the user immediately and generate first card
-	newBalance := user.Credits - req.Amount
-	err = s.repo.UpdateUserCredits(user.ID, newBalance)
+	newBalance, err := s.repo.MoveCredits(casinoStake(user.ID, "hilow", req.Amount))
 	if err != nil {
 		log.Printf("Failed to deduct bet amount for Hi-Low step 1: %v", err)
 		w.Header().Set("Content-Type", "application/json")
@@ -3799,19 +4029,13 @@ func (s *Server) handleHiLowStep2(w http.ResponseWriter, r *http.Request) {
 		won = false
 	}
 
-	var newBalance int
+	newBalance := user.Credits // No additional charge, already paid in step 1
 	var payout int
 
-	if won {
-		payout = req.Amount * 2 // 2x payout
-		newBalance = user.Credits + payout
-	} else {
-		newBalance = user.Credits // No additional charge, already paid in step 1
-	}
-
 	// Update user credits (only if they won)
 	if won {
-		err = s.repo.UpdateUserCredits(user.ID, newBalance)
+		payout = req.Amount * 2 // 2x payout
+		newBalance, err = s.repo.MoveCredits(casinoPayout(user.ID, "hilow", payout))
 		if err != nil {
 			log.Printf("Failed to update user credits for Hi-Low step 2: %v", err)
 			w.Header().Set("Content-Type", "application/json")
@@ -3945,7 +4169,6 @@ func (s *Server) handleSlots(w http.ResponseWriter, r *http.Request) {
 			// JACKPOT! Pay out the progressive jackpot + base payout
 			basePayout := req.Amount * 6 // 6x base for 3 lines
 			payout = basePayout + jackpotAmount
-			newBalance = user.Credits + payout - req.Amount
 
 			// Reset the progressive jackpot to a base amount
 			err = s.setProgressiveJackpot(1000) // Reset to 1000 credits
@@ -3959,11 +4182,9 @@ func (s *Server) handleSlots(w http.ResponseWriter, r *http.Request) {
 			} else if len(winningLines) == 2 {
 				payout = req.Amount * 50 // 50x bet
 			}
-			newBalance = user.Credits + payout - req.Amount
 		}
 	} else {
 		// Player lost - add 90% of their bet to the progressive jackpot
-		newBalance = user.Credits - req.Amount
 		jackpotContribution := (req.Amount * 9) / 10 // 90% of bet goes to jackpot
 		if jackpotContribution < 1 {
 			jackpotContribution = 1 // Minimum 1 credit contribution
@@ -3982,7 +4203,10 @@ func (s *Server) handleSlots(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Update user credits
-	err = s.repo.UpdateUserCredits(user.ID, newBalance)
+	newBalance, err = s.repo.MoveCredits(
+		casinoStake(user.ID, "slots", req.Amount),
+		casinoPayout(user.ID, "slots", payout),
+	)
 	if err != nil {
 		log.Printf("Failed to update user credits for slots: %v", err)
 		w.Header().Set("Content-Type", "application/json")
@@ -4231,13 +4455,14 @@ func (s *Server) renderTemplate(w http.ResponseWriter, templateName string, data
 			}
 			return template.JS(bytes)
 		},
+		"leagueTimezone": func() string { return s.clock().Location().String() },
 		"formatDate": func(t time.Time) string {
-			loc, err := time.LoadLocation("America/Chicago")
-			if err != nil {
-				return t.UTC().Format("Jan 2, 2006 15:04 MST")
-			}
-			return t.In(loc).Format("Jan 2, 2006 15:04 MST")
+			return t.In(s.clock().Location()).Format("Jan 2, 2006 15:04 MST")
 		},
+		"royaleOdds":     royaleOddsFor,
+		"royaleBetLabel": royaleBetLabel,
+		"propLabel":      database.PropSelectionLabel,
+		"percent":        func(f float64) string { return fmt.Sprintf("%.1f%%", f*100) },
 		"toTitle": func(s string) string {
 			replacements := map[string]string{
 				"strength":  "Strength",
@@ -4342,12 +4567,17 @@ func (s *Server) respondWithExtortion(w http.ResponseWriter, user *database.User
 
 	// Deduct 70% as a hold
 	original := user.Credits
-	hold := (original * 70) / 100
-	newBalance := original - hold
-	if newBalance < 0 {
-		newBalance = 0
+	hold := max((original*70)/100, 0)
+	newBalance, err := s.repo.MoveCredits(database.CreditMove{
+		UserID: user.ID,
+		Amount: -hold,
+		Reason: database.ReasonExtortion,
+		Memo:   "70% hold",
+	})
+	if err != nil {
+		log.Printf("[Extortion] Failed to take hold from user %d: %v", user.ID, err)
+		newBalance = original
 	}
-	_ = s.repo.UpdateUserCredits(user.ID, newBalance)
 	// Persist authoritative extortion context for secure settlement
 	_ = s.repo.SetUserSetting(user.ID, "extortion_original", fmt.Sprintf("%d", original), nil)
 	_ = s.repo.SetUserSetting(user.ID, "extortion_hold", fmt.Sprintf("%d", hold), nil)
@@ -4427,8 +4657,13 @@ func (s *Server) handleExtortionResolve(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	finalBalance := user.Credits + refund
-	if err := s.repo.UpdateUserCredits(user.ID, finalBalance); err != nil {
+	finalBalance, err := s.repo.MoveCredits(database.CreditMove{
+		UserID: user.ID,
+		Amount: refund,
+		Reason: database.ReasonExtortionRefund,
+		Memo:   outcome,
+	})
+	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "error": "Failed to settle"})
@@ -4511,8 +4746,8 @@ func (s *Server) handleBlackjackStart(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Charge immediately (like Hi-Low step 1)
-	newBalance := user.Credits - req.Amount
-	if err := s.repo.UpdateUserCredits(user.ID, newBalance); err != nil {
+	newBalance, err := s.repo.MoveCredits(casinoStake(user.ID, "blackjack", req.Amount))
+	if err != nil {
 		log.Printf("Failed to deduct bet amount for Blackjack start: %v", err)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -4573,8 +4808,8 @@ func (s *Server) handleBlackjackStart(w http.ResponseWriter, r *http.Request) {
 	// Natural blackjack immediate payout (3:2) – return 2.5x (stake included)
 	if total, _ := calc(player); total == 21 {
 		payout := (req.Amount * 5) / 2 // 2.5x return credited after stake was already deducted
-		finalBalance := newBalance + payout
-		if err := s.repo.UpdateUserCredits(user.ID, finalBalance); err != nil {
+		finalBalance, err := s.repo.MoveCredits(casinoPayout(user.ID, "blackjack", payout))
+		if err != nil {
 			log.Printf("Failed to pay natural blackjack: %v", err)
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusInternalServerError)
@@ -4885,8 +5120,8 @@ func (s *Server) handleBlackjackStand(w http.ResponseWriter, r *http.Request) {
 	payout := 0
 	if won {
 		payout = st.Amount * 2
-		newBalance = user.Credits + payout
-		if err := s.repo.UpdateUserCredits(user.ID, newBalance); err != nil {
+		var err error
+		if newBalance, err = s.repo.MoveCredits(casinoPayout(user.ID, "blackjack", payout)); err != nil {
 			log.Printf("Failed to pay Blackjack winnings: %v", err)
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusInternalServerError)
@@ -4898,8 +5133,8 @@ func (s *Server) handleBlackjackStand(w http.ResponseWriter, r *http.Request) {
 		}
 	} else if push {
 		payout = st.Amount
-		newBalance = user.Credits + payout
-		if err := s.repo.UpdateUserCredits(user.ID, newBalance); err != nil {
+		var err error
+		if newBalance, err = s.repo.MoveCredits(casinoRefund(user.ID, "blackjack", payout)); err != nil {
 			log.Printf("Failed to refund Blackjack push: %v", err)
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusInternalServerError)
@@ -4926,14 +5161,13 @@ func (s *Server) handleBlackjackStand(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleScheduleTodayAPI(w http.ResponseWriter, r *http.Request) {
-	centralTime, _ := time.LoadLocation("America/Chicago")
-	now := time.Now().In(centralTime)
+	now := s.clock().Now()
 
 	resp := scheduleAPIResponse{
 		Meta: scheduleAPIMeta{
 			Now:      now.Format(time.RFC3339),
 			Day:      now.Format("2006-01-02"),
-			Timezone: "America/Chicago",
+			Timezone: now.Location().String(),
 		},
 		Fights: []scheduleFightDTO{},
 	}
@@ -4944,7 +5178,7 @@ func (s *Server) handleScheduleTodayAPI(w http.ResponseWriter, r *http.Request)
 		fights, ferr := s.repo.GetTodaysFights(tournament.ID, today, tomorrow)
 		if ferr == nil {
 			resp.Meta.Tournament = tournament.ID
-			resp.Fights = toScheduleDTOs(fights)
+			resp.Fights = toScheduleDTOs(fights, s.clock().Location())
 		} else {
 			resp.Error = ferr.Error()
 		}
@@ -4990,15 +5224,15 @@ func (s *Server) handleFightsAPI(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(fights)
 }
 
-func toScheduleDTOs(fights []database.Fight) []scheduleFightDTO {
+func toScheduleDTOs(fights []database.Fight, league *time.Location) []scheduleFightDTO {
 	out := make([]scheduleFightDTO, 0, len(fights))
 	for _, fight := range fights {
-		out = append(out, toScheduleDTO(fight))
+		out = append(out, toScheduleDTO(fight, league))
 	}
 	return out
 }
 
-func toScheduleDTO(fight database.Fight) scheduleFightDTO {
+func toScheduleDTO(fight database.Fight, league *time.Location) scheduleFightDTO {
 	var winner *int
 	if fight.WinnerID.Valid {
 		id := int(fight.WinnerID.Int64)
@@ -5019,12 +5253,10 @@ func toScheduleDTO(fight database.Fight) scheduleFightDTO {
 
 	var completed *string
 	if fight.CompletedAt.Valid {
-		central, _ := time.LoadLocation("America/Chicago")
-		stamp := fight.CompletedAt.Time.In(central).Format(time.RFC3339)
+		stamp := fight.CompletedAt.Time.In(league).Format(time.RFC3339)
 		completed = &stamp
 	}
 
-	central, _ := time.LoadLocation("America/Chicago")
 	return scheduleFightDTO{
 		ID:            fight.ID,
 		TournamentID:  fight.TournamentID,
@@ -5032,7 +5264,7 @@ func toScheduleDTO(fight database.Fight) scheduleFightDTO {
 		Fighter2ID:    fight.Fighter2ID,
 		Fighter1Name:  fight.Fighter1Name,
 		Fighter2Name:  fight.Fighter2Name,
-		ScheduledTime: fight.ScheduledTime.In(central).Format(time.RFC3339),
+		ScheduledTime: fight.ScheduledTime.In(league).Format(time.RFC3339),
 		Status:        fight.Status,
 		WinnerID:      winner,
 		FinalScore1:   score1,
@@ -0,0 +1,135 @@
+package web
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"spoodblort/fight"
+	"spoodblort/utils"
+)
+
+// slipCardFight is a fight on today's card that can go on a parlay, with the
+// leg price for each side
+type slipCardFight struct {
+	ID            int
+	Fighter1ID    int
+	Fighter2ID    int
+	Fighter1Name  string
+	Fighter2Name  string
+	Fighter1Odds  float64
+	Fighter2Odds  float64
+	ScheduledTime time.Time
+}
+
+// slipCard lists today's scheduled, priced fights for the parlay builder
+func (s *Server) slipCard(now time.Time) []slipCardFight {
+	tournament, err := s.scheduler.GetCurrentTournament(now)
+	if err != nil || tournament == nil {
+		return nil
+	}
+	today, tomorrow := utils.GetDayBounds(now)
+	fights, err := s.repo.GetTodaysFights(tournament.ID, today, tomorrow)
+	if err != nil {
+		log.Printf("Error fetching today's fights for parlays: %v", err)
+		return nil
+	}
+
+	var card []slipCardFight
+	for _, f := range fights {
+		if f.Status != "scheduled" {
+			continue
+		}
+		odds, err := s.repo.GetFightOdds(f.ID)
+		if err != nil {
+			continue // not priced yet
+		}
+		entry := slipCardFight{
+			ID:            f.ID,
+			Fighter1ID:    f.Fighter1ID,
+			Fighter2ID:    f.Fighter2ID,
+			Fighter1Name:  f.Fighter1Name,
+			Fighter2Name:  f.Fighter2Name,
+			Fighter1Odds:  fight.SlipLegOdds(f, odds, f.Fighter1ID),
+			Fighter2Odds:  fight.SlipLegOdds(f, odds, f.Fighter2ID),
+			ScheduledTime: f.ScheduledTime.In(s.clock().Location()),
+		}
+		if entry.Fighter1Odds > 0 && entry.Fighter2Odds > 0 {
+			card = append(card, entry)
+		}
+	}
+	return card
+}
+
+// handleBetSlip places a parlay from the dashboard builder. Each leg arrives
+// as leg_<fight id>=<fighter id>; fights left blank aren't on the slip.
+func (s *Server) handleBetSlip(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	stake, err := strconv.Atoi(r.FormValue("stake"))
+	if err != nil || stake <= 0 {
+		http.Error(w, "Invalid stake", http.StatusBadRequest)
+		return
+	}
+	if maxAllowed := s.getUserMaxFightBet(user); stake > maxAllowed {
+		http.Error(w, fmt.Sprintf("Stake exceeds allowed maximum (%d)", maxAllowed), http.StatusBadRequest)
+		return
+	}
+
+	var picks []fight.SlipPick
+	for key, values := range r.PostForm {
+		raw, ok := strings.CutPrefix(key, "leg_")
+		if !ok || len(values) == 0 || values[0] == "" {
+			continue
+		}
+		fightID, err := strconv.Atoi(raw)
+		if err != nil {
+			http.Error(w, "Invalid fight ID", http.StatusBadRequest)
+			return
+		}
+		fighterID, err := strconv.Atoi(values[0])
+		if err != nil {
+			http.Error(w, "Invalid fighter ID", http.StatusBadRequest)
+			return
+		}
+		picks = append(picks, fight.SlipPick{FightID: fightID, FighterID: fighterID})
+	}
+
+	slipID, odds, err := s.scheduler.GetEngine().PlaceBetSlip(user.ID, stake, picks)
+	if err != nil {
+		log.Printf("Parlay rejected for user %d: %v", user.ID, err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("Parlay %d: user %d staked %d on %d legs at %.2f", slipID, user.ID, stake, len(picks), odds)
+	http.Redirect(w, r, "/user/dashboard", http.StatusSeeOther)
+}
+
+// handleBetSlipsAPI returns the signed-in user's recent parlays and their legs
+func (s *Server) handleBetSlipsAPI(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"error": "unauthorized"})
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	slips, err := s.repo.GetUserBetSlips(user.ID, limit)
+	if err != nil {
+		log.Printf("failed loading parlays for user %d: %v", user.ID, err)
+		writeJSON(w, http.StatusInternalServerError, map[string]interface{}{"error": "failed to load parlays"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"slips": slips})
+}
@@ -24,11 +24,13 @@ var upgrader = websocket.Upgrader{
 // FightBroadcaster manages live fight broadcasting
 type FightBroadcaster struct {
 	repo       *database.Repository
-	engine     FightEngine                      // Interface to log actions
 	clients    map[int]map[*websocket.Conn]bool // fightID -> connections
 	clientsMux sync.RWMutex
 	broadcast  map[int]chan fight.LiveAction // fightID -> broadcast channel
 
+	// Battle royale viewers, guarded by clientsMux
+	royaleClients map[int]map[*websocket.Conn]bool // royaleID -> connections
+
 	// In-memory clap rate limiting
 	userClaps map[string][]time.Time // userID_fightID -> timestamps
 	clapsMux  sync.RWMutex
@@ -43,11 +45,6 @@ type FightBroadcaster struct {
 	clapHealMux sync.Mutex
 }
 
-// FightEngine interface for logging actions
-type FightEngine interface {
-	LogAction(fightID int, text string)
-}
-
 type ClapMessage struct {
 	Type        string `json:"type"`
 	FighterID   int    `json:"fighter_id"`
@@ -61,17 +58,13 @@ func NewFightBroadcaster(repo *database.Repository) *FightBroadcaster {
 		repo:            repo,
 		clients:         make(map[int]map[*websocket.Conn]bool),
 		broadcast:       make(map[int]chan fight.LiveAction),
+		royaleClients:   make(map[int]map[*websocket.Conn]bool),
 		userClaps:       make(map[string][]time.Time),
 		roundClapTotals: make(map[string]map[int]int),
 		clapHeal:        make(map[int]map[int]int),
 	}
 }
 
-// SetEngine sets the fight engine for logging purposes
-func (fb *FightBroadcaster) SetEngine(engine FightEngine) {
-	fb.engine = engine
-}
-
 // CanUserClap checks if user can clap (rate limiting)
 func (fb *FightBroadcaster) CanUserClap(userID, fightID int) bool {
 	fb.clapsMux.Lock()
@@ -166,16 +159,18 @@ func (fb *FightBroadcaster) ConsumeClapHealth(fightID int, fighter1ID int, fight
 	return delta1, delta2
 }
 
-// BroadcastRoundClapSummary sends a summary of claps when a clapping round ends
-func (fb *FightBroadcaster) BroadcastRoundClapSummary(fightID, round int) {
-	// Only broadcast summaries for rounds that just ended clapping (were divisible by 5)
+// RoundClapSummary builds a summary of claps when a clapping round ends. The
+// engine announces it like any other action so it is broadcast, logged and
+// kept in the fight's event history.
+func (fb *FightBroadcaster) RoundClapSummary(fightID, round int) (fight.LiveAction, bool) {
+	// Only summarize rounds that just ended clapping (were divisible by 5)
 	if round%5 != 1 {
-		return
+		return fight.LiveAction{}, false
 	}
 
 	previousRound := round - 1
 	if previousRound%5 != 0 {
-		return
+		return fight.LiveAction{}, false
 	}
 
 	fb.roundTotalsMux.RLock()
@@ -184,7 +179,7 @@ func (fb *FightBroadcaster) BroadcastRoundClapSummary(fightID, round int) {
 	fb.roundTotalsMux.RUnlock()
 
 	if len(clapTotals) == 0 {
-		return
+		return fight.LiveAction{}, false
 	}
 
 	// Sort users by clap count (highest first)
@@ -226,30 +221,22 @@ func (fb *FightBroadcaster) BroadcastRoundClapSummary(fightID, round int) {
 		summaryParts = append(summaryParts, fmt.Sprintf("%s cheered %s times", uc.displayName, addCommas(uc.count)))
 	}
 
-	if len(summaryParts) > 0 {
-		summaryAction := fight.LiveAction{
-			Type:       "clap_summary",
-			Action:     fmt.Sprintf("🎉 ROUND %d CLAP TOTALS: %s!", previousRound, joinWithCommasAnd(summaryParts)),
-			Announcer:  "THE COMMISSIONER",
-			Commentary: "The Department has recorded these displays of crowd enthusiasm for statistical analysis.",
-			Round:      round,
-		}
-
-		fb.BroadcastAction(fightID, summaryAction)
-
-		// Log the clap summary to fight log
-		if fb.engine != nil {
-			fb.engine.LogAction(fightID, summaryAction.Action)
-			if summaryAction.Commentary != "" {
-				fb.engine.LogAction(fightID, fmt.Sprintf("%s: \"%s\"", summaryAction.Announcer, summaryAction.Commentary))
-			}
-		}
-	}
-
 	// Clean up the round data to save memory
 	fb.roundTotalsMux.Lock()
 	delete(fb.roundClapTotals, roundKey)
 	fb.roundTotalsMux.Unlock()
+
+	if len(summaryParts) == 0 {
+		return fight.LiveAction{}, false
+	}
+
+	return fight.LiveAction{
+		Type:       "clap_summary",
+		Action:     fmt.Sprintf("🎉 ROUND %d CLAP TOTALS: %s!", previousRound, joinWithCommasAnd(summaryParts)),
+		Announcer:  "THE COMMISSIONER",
+		Commentary: "The Department has recorded these displays of crowd enthusiasm for statistical analysis.",
+		Round:      round,
+	}, true
 }
 
 // Helper function to add commas to numbers
@@ -450,6 +437,55 @@ func (fb *FightBroadcaster) BroadcastAction(fightID int, action fight.LiveAction
 	log.Printf("Broadcasted action to %d viewers of fight %d", len(clients), fightID)
 }
 
+// BroadcastPool pushes the current parimutuel pool for a fight to its viewers
+func (fb *FightBroadcaster) BroadcastPool(fightID int) {
+	fb.clientsMux.RLock()
+	clients := fb.clients[fightID]
+	fb.clientsMux.RUnlock()
+
+	if len(clients) == 0 {
+		return // No viewers
+	}
+
+	message, err := fb.poolMessage(fightID)
+	if err != nil {
+		log.Printf("Failed to build pool update for fight %d: %v", fightID, err)
+		return
+	}
+
+	for conn := range clients {
+		if err := conn.WriteMessage(websocket.TextMessage, message); err != nil {
+			log.Printf("Failed to send pool update: %v", err)
+			fb.clientsMux.Lock()
+			delete(fb.clients[fightID], conn)
+			fb.clientsMux.Unlock()
+			conn.Close()
+		}
+	}
+}
+
+// poolMessage encodes a fight's pool totals and implied payouts per credit
+func (fb *FightBroadcaster) poolMessage(fightID int) ([]byte, error) {
+	pool, err := fb.repo.GetBetPool(fightID)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(map[string]interface{}{
+		"type": "pool",
+		"pool": map[string]interface{}{
+			"fighter1_stake":  pool.Fighter1Stake,
+			"fighter2_stake":  pool.Fighter2Stake,
+			"fighter1_bets":   pool.Fighter1Bets,
+			"fighter2_bets":   pool.Fighter2Bets,
+			"total":           pool.Total(),
+			"net":             pool.Net(),
+			"rake_percent":    pool.RakePercent,
+			"fighter1_payout": pool.ImpliedPayout(pool.Fighter1ID),
+			"fighter2_payout": pool.ImpliedPayout(pool.Fighter2ID),
+		},
+	})
+}
+
 // BroadcastViewerCount sends updated viewer count to all clients
 func (fb *FightBroadcaster) BroadcastViewerCount(fightID int) {
 	fb.clientsMux.RLock()
@@ -474,6 +510,39 @@ func (fb *FightBroadcaster) BroadcastViewerCount(fightID int) {
 	}
 }
 
+// startingHealth is a fighter's health after the blessings and curses applied between start and end
+func (fb *FightBroadcaster) startingHealth(fighterID int, start, end time.Time) int {
+	effects, _ := fb.repo.GetAppliedEffectsForDate("fighter", fighterID, start, end)
+
+	health := 100000 // STARTING_HEALTH constant
+	for _, effect := range effects {
+		switch effect.EffectType {
+		case "fighter_blessing":
+			health += effect.EffectValue
+		case "fighter_curse":
+			health -= effect.EffectValue
+		}
+	}
+
+	// Ensure health never goes below 1
+	if health < 1 {
+		health = 1
+	}
+	return health
+}
+
+// tagBar is one fighter's bar in a tag-team initial state, shaped like fight.TagBar
+func tagBar(fighter *database.Fighter, side, health int, active bool) map[string]interface{} {
+	return map[string]interface{}{
+		"fighter_id": fighter.ID,
+		"name":       fighter.Name,
+		"side":       side,
+		"health":     health,
+		"active":     active,
+		"out":        false,
+	}
+}
+
 // sendInitialState sends the current fight state to a new viewer
 func (fb *FightBroadcaster) sendInitialState(conn *websocket.Conn, fightID int) error {
 	// Get fight details
@@ -499,9 +568,8 @@ func (fb *FightBroadcaster) sendInitialState(conn *websocket.Conn, fightID int)
 	// Determine which date to use for effects based on fight status
 	var effectDate time.Time
 	if fight.Status == "active" {
-		// For active fights, use today's effects (live viewing) in Central Time
-		centralTime, _ := time.LoadLocation("America/Chicago")
-		effectDate = time.Now().In(centralTime)
+		// For active fights, use today's effects (live viewing) in league time
+		effectDate = fb.repo.Clock().Now()
 	} else {
 		// For scheduled/completed/voided fights, use the fight's scheduled date (historical viewing)
 		effectDate = fight.ScheduledTime
@@ -512,39 +580,8 @@ func (fb *FightBroadcaster) sendInitialState(conn *websocket.Conn, fightID int)
 	endDate := startDate.Add(24 * time.Hour)
 
 	// Calculate health with applied effects from the specific date
-	fighter1Effects, _ := fb.repo.GetAppliedEffectsForDate("fighter", fight.Fighter1ID, startDate, endDate)
-	fighter2Effects, _ := fb.repo.GetAppliedEffectsForDate("fighter", fight.Fighter2ID, startDate, endDate)
-
-	// Base health
-	fighter1Health = 100000 // STARTING_HEALTH constant
-	fighter2Health = 100000
-
-	// Apply effects
-	for _, effect := range fighter1Effects {
-		switch effect.EffectType {
-		case "fighter_blessing":
-			fighter1Health += effect.EffectValue
-		case "fighter_curse":
-			fighter1Health -= effect.EffectValue
-		}
-	}
-
-	for _, effect := range fighter2Effects {
-		switch effect.EffectType {
-		case "fighter_blessing":
-			fighter2Health += effect.EffectValue
-		case "fighter_curse":
-			fighter2Health -= effect.EffectValue
-		}
-	}
-
-	// Ensure health never goes below 1
-	if fighter1Health < 1 {
-		fighter1Health = 1
-	}
-	if fighter2Health < 1 {
-		fighter2Health = 1
-	}
+	fighter1Health = fb.startingHealth(fight.Fighter1ID, startDate, endDate)
+	fighter2Health = fb.startingHealth(fight.Fighter2ID, startDate, endDate)
 
 	// Determine current state based on fight status
 	var state map[string]interface{}
@@ -635,12 +672,50 @@ func (fb *FightBroadcaster) sendInitialState(conn *websocket.Conn, fightID int)
 		}
 	}
 
+	// Tag-team fights also carry the partners and a health bar for all four fighters
+	if fight.IsTagTeam() && state["fight"] != nil {
+		partner1, err1 := fb.repo.GetFighter(fight.Partner1ID)
+		partner2, err2 := fb.repo.GetFighter(fight.Partner2ID)
+		if err1 == nil && err2 == nil {
+			state["partner1"] = partner1
+			state["partner2"] = partner2
+			if fight.Status != "completed" {
+				partnerHealth1 := fb.startingHealth(partner1.ID, startDate, endDate)
+				partnerHealth2 := fb.startingHealth(partner2.ID, startDate, endDate)
+				bars := []map[string]interface{}{
+					tagBar(fighter1, 1, fighter1Health, true),
+					tagBar(partner1, 1, partnerHealth1, false),
+					tagBar(fighter2, 2, fighter2Health, true),
+					tagBar(partner2, 2, partnerHealth2, false),
+				}
+				// A shared pool shows the same bar for both partners
+				if fight.TagHealth == database.TagHealthShared {
+					pool1, pool2 := fighter1Health+partnerHealth1, fighter2Health+partnerHealth2
+					state["health1"], state["health2"] = pool1, pool2
+					bars[0]["health"], bars[1]["health"] = pool1, pool1
+					bars[2]["health"], bars[3]["health"] = pool2, pool2
+				}
+				state["tag_health"] = bars
+			}
+		}
+	}
+
 	message, err := json.Marshal(state)
 	if err != nil {
 		return err
 	}
 
-	return conn.WriteMessage(websocket.TextMessage, message)
+	if err := conn.WriteMessage(websocket.TextMessage, message); err != nil {
+		return err
+	}
+
+	// Parimutuel fights also open with the current pool
+	if mode, err := fb.repo.SettlementModeForFight(fightID); err == nil && mode == database.SettlementParimutuel {
+		if pool, err := fb.poolMessage(fightID); err == nil {
+			return conn.WriteMessage(websocket.TextMessage, pool)
+		}
+	}
+	return nil
 }
 
 // GetViewerCount returns the number of viewers for a fight
@@ -649,3 +724,92 @@ func (fb *FightBroadcaster) GetViewerCount(fightID int) int {
 	defer fb.clientsMux.RUnlock()
 	return len(fb.clients[fightID])
 }
+
+// HandleRoyaleWebSocket streams a battle royale to one viewer
+func (fb *FightBroadcaster) HandleRoyaleWebSocket(w http.ResponseWriter, r *http.Request) {
+	royaleID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid battle royale ID", http.StatusBadRequest)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("WebSocket: Upgrade failed for battle royale %d: %v", royaleID, err)
+		return
+	}
+	defer conn.Close()
+
+	fb.clientsMux.Lock()
+	if fb.royaleClients[royaleID] == nil {
+		fb.royaleClients[royaleID] = make(map[*websocket.Conn]bool)
+	}
+	fb.royaleClients[royaleID][conn] = true
+	fb.clientsMux.Unlock()
+
+	defer func() {
+		fb.clientsMux.Lock()
+		delete(fb.royaleClients[royaleID], conn)
+		if len(fb.royaleClients[royaleID]) == 0 {
+			delete(fb.royaleClients, royaleID)
+		}
+		fb.clientsMux.Unlock()
+	}()
+
+	royale, err := fb.repo.GetBattleRoyale(royaleID)
+	if err != nil {
+		log.Printf("WebSocket: Battle royale %d not found: %v", royaleID, err)
+		return
+	}
+	entrants, err := fb.repo.GetBattleRoyaleEntrants(royaleID)
+	if err != nil {
+		log.Printf("WebSocket: Failed to load entrants for battle royale %d: %v", royaleID, err)
+		return
+	}
+	if err := conn.WriteJSON(map[string]interface{}{
+		"type":     "initial",
+		"royale":   royale,
+		"entrants": entrants,
+	}); err != nil {
+		return
+	}
+
+	// Viewers only listen; read until they go away
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// BroadcastRoyaleAction sends a live action to all viewers of a battle royale
+func (fb *FightBroadcaster) BroadcastRoyaleAction(royaleID int, action fight.RoyaleAction) {
+	fb.clientsMux.RLock()
+	clients := make([]*websocket.Conn, 0, len(fb.royaleClients[royaleID]))
+	for conn := range fb.royaleClients[royaleID] {
+		clients = append(clients, conn)
+	}
+	fb.clientsMux.RUnlock()
+
+	if len(clients) == 0 {
+		return
+	}
+
+	message, err := json.Marshal(map[string]interface{}{
+		"type":   "action",
+		"action": action,
+	})
+	if err != nil {
+		log.Printf("Failed to marshal royale action: %v", err)
+		return
+	}
+
+	for _, conn := range clients {
+		if err := conn.WriteMessage(websocket.TextMessage, message); err != nil {
+			fb.clientsMux.Lock()
+			delete(fb.royaleClients[royaleID], conn)
+			fb.clientsMux.Unlock()
+			conn.Close()
+		}
+	}
+}